@@ -4,4 +4,4 @@ import "backend/internal/app"
 
 func main() {
 	app.New().Run()
-}
\ No newline at end of file
+}
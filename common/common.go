@@ -6,3 +6,15 @@ var StatusInvalidParams = 0
 var ErrInvalidParams = errors.New("invalid params").Error()
 var StatusGetInfo = 1
 var ErrGetInfo = errors.New("can not get info").Error()
+var StatusTooManyRequests = 2
+var ErrTooManyRequests = errors.New("too many requests").Error()
+var StatusUnauthorized = 3
+var ErrUnauthorized = errors.New("unauthorized").Error()
+var StatusForbidden = 4
+var ErrForbidden = errors.New("forbidden").Error()
+var StatusUpdateInfo = 5
+var ErrUpdateInfo = errors.New("can not update info").Error()
+var StatusConflict = 6
+var ErrConflict = errors.New("conflict with existing data").Error()
+var StatusNotFound = 7
+var ErrNotFound = errors.New("not found").Error()
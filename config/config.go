@@ -1,27 +1,209 @@
 package config
 
-
 import (
 	"fmt"
-	"github.com/spf13/viper"
 	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
 )
 
+const (
+	// EnvPrefix - префикс переменных окружения: APP_POSTGRES_PASSWORD -> Postgres.password
+	EnvPrefix = "APP"
+	// EnvConfigFile - путь к yaml-файлу конфигурации, если не передан флаг -config
+	EnvConfigFile = EnvPrefix + "_CONFIG_FILE"
+	// fileSuffix - суффикс переменной с путем к файлу секрета: APP_POSTGRES_PASSWORD_FILE
+	fileSuffix = "_FILE"
+)
+
+// NewConfig собирает конфигурацию слоями: значения по умолчанию,
+// затем необязательный файл (флаг -config или APP_CONFIG_FILE),
+// затем переменные окружения с префиксом APP_ и секреты из файлов.
 func NewConfig() (*ConfigModel, error) {
 	var cfg ConfigModel
 	v := viper.New()
-	v.AddConfigPath("config")
-	v.SetConfigName("config")
-	v.SetConfigType("yml")
-	err := v.ReadInConfig()
+	setDefaults(v)
+
+	path, err := configPath(os.Args[1:])
 	if err != nil {
-		slog.Error("fail to read config", err)
+		slog.Error("fail to parse flags", "error", err)
 		return &cfg, err
 	}
-	err = v.Unmarshal(&cfg)
-	if err != nil {
-		slog.Error("", fmt.Errorf("unable to decode config into struct, %w", err))
+	if path != "" {
+		v.SetConfigFile(path)
+		v.SetConfigType("yaml")
+		if err = v.ReadInConfig(); err != nil {
+			slog.Error("fail to read config", "path", path, "error", err)
+			return &cfg, err
+		}
+	}
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	for _, key := range keys(v, reflect.TypeOf(cfg), "") {
+		if err = v.BindEnv(key); err != nil {
+			return &cfg, err
+		}
+		if err = readSecretFile(v, key); err != nil {
+			slog.Error("fail to read secret file", "key", key, "error", err)
+			return &cfg, err
+		}
+	}
+
+	if err = v.Unmarshal(&cfg); err != nil {
+		err = fmt.Errorf("unable to decode config into struct, %w", err)
+		slog.Error("fail to decode config", "error", err)
+		return &cfg, err
+	}
+	if err = validator.New().Struct(&cfg); err != nil {
+		err = fmt.Errorf("invalid config: %w", err)
+		slog.Error("fail to validate config", "error", err)
 		return &cfg, err
 	}
 	return &cfg, nil
 }
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.host", "0.0.0.0")
+	v.SetDefault("server.port", "8080")
+	v.SetDefault("postgres.host", "127.0.0.1")
+	v.SetDefault("postgres.port", "5432")
+	v.SetDefault("postgres.user", "postgres")
+	v.SetDefault("postgres.dbname", "bossdb")
+	v.SetDefault("postgres.sslmode", "disable")
+	v.SetDefault("postgres.applicationname", "hunt")
+	v.SetDefault("postgres.maxconns", 20)
+	v.SetDefault("postgres.minconns", 2)
+	v.SetDefault("postgres.maxconnlifetime", "1h")
+	v.SetDefault("postgres.maxconnidletime", "30m")
+	v.SetDefault("postgres.healthcheckperiod", "1m")
+	v.SetDefault("postgres.statementtimeout", "30s")
+	v.SetDefault("postgres.connecttimeout", "5s")
+	v.SetDefault("postgres.connectattempts", 5)
+	v.SetDefault("postgres.connectretrydelay", "500ms")
+	v.SetDefault("postgres.replicamaxlag", "5s")
+	v.SetDefault("postgres.replicacheckinterval", "5s")
+	v.SetDefault("postgres.readyourwriteswindow", "10s")
+	v.SetDefault("tracing.exporter", "none")
+	v.SetDefault("tracing.servicename", "hunt")
+	v.SetDefault("tracing.sampleratio", 1.0)
+	v.SetDefault("auth.initdatattl", "24h")
+	v.SetDefault("telegram.apibaseurl", "https://api.telegram.org")
+	v.SetDefault("telegram.requesttimeout", "10s")
+	v.SetDefault("moderation.pricelowratio", 0.2)
+	v.SetDefault("moderation.pricehighratio", 5.0)
+	v.SetDefault("moderation.priceminsamples", 5)
+	v.SetDefault("moderation.reportstohide", 5)
+	v.SetDefault("phone.defaultcountrycode", "7")
+	v.SetDefault("phone.codelength", 6)
+	v.SetDefault("phone.codettl", "5m")
+	v.SetDefault("phone.maxattempts", 5)
+	v.SetDefault("phone.resendinterval", "1m")
+	v.SetDefault("sms.apibaseurl", "https://sms.ru")
+	v.SetDefault("sms.requesttimeout", "10s")
+	v.SetDefault("publisher.interval", "30s")
+	v.SetDefault("publisher.batchsize", 100)
+	v.SetDefault("savedsearch.maxperuser", 20)
+	v.SetDefault("savedsearch.digestinterval", "10m")
+	v.SetDefault("savedsearch.digestbatchsize", 100)
+	v.SetDefault("notification.deliveryinterval", "10s")
+	v.SetDefault("notification.deliverybatchsize", 100)
+	v.SetDefault("notification.maxattempts", 5)
+	v.SetDefault("notification.retrydelay", "1m")
+	v.SetDefault("notification.promotionexpirynotice", "24h")
+	v.SetDefault("webhook.maxperuser", 5)
+	v.SetDefault("webhook.deliveryinterval", "5s")
+	v.SetDefault("webhook.deliverybatchsize", 50)
+	v.SetDefault("webhook.maxattempts", 8)
+	v.SetDefault("webhook.retrybasedelay", "30s")
+	v.SetDefault("webhook.retrymaxdelay", "6h")
+	v.SetDefault("webhook.requesttimeout", "10s")
+	v.SetDefault("outbox.interval", "1s")
+	v.SetDefault("outbox.batchsize", 100)
+	v.SetDefault("outbox.maxattempts", 10)
+	v.SetDefault("outbox.retrydelay", "10s")
+	v.SetDefault("cache.enabled", true)
+	v.SetDefault("cache.maxentries", 10000)
+	v.SetDefault("cache.adttl", "1m")
+	v.SetDefault("cache.profilettl", "1m")
+	v.SetDefault("cache.categoriesttl", "10m")
+	v.SetDefault("ratelimit.enabled", true)
+	v.SetDefault("ratelimit.groups", map[string]interface{}{
+		"read":  map[string]interface{}{"rate": 20, "burst": 40},
+		"write": map[string]interface{}{"rate": 1, "burst": 10},
+	})
+}
+
+// configPath ищет среди аргументов только -config: остальные флаги (оркестратора,
+// -test.* в тестах) принадлежат не нам и не должны ронять старт
+func configPath(args []string) (string, error) {
+	var path string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("flag needs an argument: -config")
+			}
+			i++
+			value = args[i]
+		}
+		path = value
+	}
+	if path == "" {
+		path = os.Getenv(EnvConfigFile)
+	}
+	return path, nil
+}
+
+// keys возвращает ключи viper для всех полей структуры по yaml-тегам. Для
+// map структур (RateLimit.Groups) ключи известны только из значений по
+// умолчанию и файла: переменными окружения переопределяются поля уже
+// объявленных элементов, например APP_RATELIMIT_GROUPS_READ_RATE, а новый
+// элемент добавляется только в файле.
+func keys(v *viper.Viper, t reflect.Type, prefix string) []string {
+	var res []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		key := strings.ToLower(prefix + name)
+		switch {
+		case f.Type.Kind() == reflect.Struct:
+			res = append(res, keys(v, f.Type, key+".")...)
+		case f.Type.Kind() == reflect.Map && f.Type.Elem().Kind() == reflect.Struct:
+			for elem := range v.GetStringMap(key) {
+				res = append(res, keys(v, f.Type.Elem(), key+"."+strings.ToLower(elem)+".")...)
+			}
+		default:
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+func readSecretFile(v *viper.Viper, key string) error {
+	env := EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_")) + fileSuffix
+	path, ok := os.LookupEnv(env)
+	if !ok || path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", env, err)
+	}
+	v.Set(key, strings.TrimSpace(string(data)))
+	return nil
+}
@@ -1,22 +1,226 @@
 package config
 
+import "time"
+
 type ConfigModel struct {
-	Server   ServerConfig   `yaml:"Server"`
-	Postgres PostgresConfig `yaml:"Postgres"`
+	Server       ServerConfig       `yaml:"Server"`
+	Postgres     PostgresConfig     `yaml:"Postgres"`
+	Tracing      TracingConfig      `yaml:"Tracing"`
+	RateLimit    RateLimitConfig    `yaml:"RateLimit"`
+	Auth         AuthConfig         `yaml:"Auth"`
+	Telegram     TelegramConfig     `yaml:"Telegram"`
+	Moderation   ModerationConfig   `yaml:"Moderation"`
+	Phone        PhoneConfig        `yaml:"Phone"`
+	SMS          SMSConfig          `yaml:"SMS"`
+	Publisher    PublisherConfig    `yaml:"Publisher"`
+	Geo          GeoConfig          `yaml:"Geo"`
+	SavedSearch  SavedSearchConfig  `yaml:"SavedSearch"`
+	Notification NotificationConfig `yaml:"Notification"`
+	Webhook      WebhookConfig      `yaml:"Webhook"`
+	Outbox       OutboxConfig       `yaml:"Outbox"`
+	Cache        CacheConfig        `yaml:"Cache"`
 }
 
 type PostgresConfig struct {
-	Host     string `yaml:"host"`
-	Port     string `yaml:"port"`
-	User     string `yaml:"user"`
+	Host     string `yaml:"host" validate:"required"`
+	Port     string `yaml:"port" validate:"required"`
+	User     string `yaml:"user" validate:"required"`
 	Password string `yaml:"password"`
-	DBName   string `yaml:"DBName"`
+	DBName   string `yaml:"DBName" validate:"required"`
 	SSLMode  string `yaml:"sslMode"`
 	PgDriver string `yaml:"pgDriver"`
+	// ApplicationName - имя приложения в pg_stat_activity
+	ApplicationName string `yaml:"applicationName"`
+	// Размер пула: MinConns соединений держится открытыми всегда
+	MaxConns int32 `yaml:"maxConns" validate:"gte=1"`
+	MinConns int32 `yaml:"minConns" validate:"gte=0,ltefield=MaxConns"`
+	// MaxConnLifetime - соединение старше закрывается, MaxConnIdleTime - простаивающее дольше
+	MaxConnLifetime time.Duration `yaml:"maxConnLifetime" validate:"gt=0"`
+	MaxConnIdleTime time.Duration `yaml:"maxConnIdleTime" validate:"gt=0"`
+	// HealthCheckPeriod - как часто пул проверяет простаивающие соединения
+	HealthCheckPeriod time.Duration `yaml:"healthCheckPeriod" validate:"gt=0"`
+	// StatementTimeout - statement_timeout сессии; 0 - без ограничения
+	StatementTimeout time.Duration `yaml:"statementTimeout" validate:"gte=0"`
+	// ConnectTimeout - таймаут одной попытки подключения
+	ConnectTimeout time.Duration `yaml:"connectTimeout" validate:"gt=0"`
+	// При старте подключение повторяется ConnectAttempts раз, пауза начинается
+	// с ConnectRetryDelay и удваивается
+	ConnectAttempts   int           `yaml:"connectAttempts" validate:"gte=1"`
+	ConnectRetryDelay time.Duration `yaml:"connectRetryDelay" validate:"gt=0"`
+	// Replicas - строки подключения к репликам для тяжелых чтений (профиль,
+	// лента, поиск); задаются через APP_POSTGRES_REPLICAS(_FILE) через запятую
+	Replicas []string `yaml:"replicas"`
+	// ReplicaMaxLag - реплика с большим отставанием не получает запросы
+	ReplicaMaxLag time.Duration `yaml:"replicaMaxLag" validate:"gt=0"`
+	// ReplicaCheckInterval - как часто проверяются доступность и отставание реплик
+	ReplicaCheckInterval time.Duration `yaml:"replicaCheckInterval" validate:"gt=0"`
+	// ReadYourWritesWindow - сколько после своего изменения пользователь читает
+	// из основной базы; должно быть больше ReplicaMaxLag
+	ReadYourWritesWindow time.Duration `yaml:"readYourWritesWindow" validate:"gtefield=ReplicaMaxLag"`
 }
 
 type ServerConfig struct {
 	AppVersion string `yaml:"appVersion"`
 	Host       string `yaml:"host" validate:"required"`
 	Port       string `yaml:"port" validate:"required"`
+	// ProxyHeader - заголовок с IP клиента за балансировщиком, например X-Forwarded-For
+	ProxyHeader string `yaml:"proxyHeader"`
+	// Dev - локальная разработка: разрешает заглушки, которые нельзя запускать
+	// с реальными пользователями (SMS.provider=log)
+	Dev bool `yaml:"dev"`
+}
+
+type TracingConfig struct {
+	// Exporter: none, stdout (локальная отладка) или otlp
+	Exporter    string  `yaml:"exporter" validate:"oneof=none stdout otlp"`
+	Endpoint    string  `yaml:"endpoint" validate:"required_if=Exporter otlp"`
+	Insecure    bool    `yaml:"insecure"`
+	ServiceName string  `yaml:"serviceName"`
+	SampleRatio float64 `yaml:"sampleRatio" validate:"gte=0,lte=1"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Groups - лимиты по группам маршрутов: read, write, ...
+	Groups map[string]RateLimitRule `yaml:"groups" validate:"dive"`
+}
+
+type RateLimitRule struct {
+	// Rate - токенов в секунду, Burst - емкость ведра
+	Rate  float64 `yaml:"rate" validate:"gt=0"`
+	Burst int     `yaml:"burst" validate:"gte=1"`
+}
+
+type AuthConfig struct {
+	// InitDataTTL - сколько живет подписанный initData Telegram Mini App
+	InitDataTTL time.Duration `yaml:"initDataTTL"`
+}
+
+type TelegramConfig struct {
+	// BotToken - секрет, задается через APP_TELEGRAM_BOTTOKEN(_FILE).
+	// Без него аутентификация выключена и закрытые маршруты отвечают 401.
+	BotToken string `yaml:"botToken"`
+	// APIBaseURL - адрес Bot API; в тестах подменяется локальной заглушкой
+	APIBaseURL string `yaml:"apiBaseURL" validate:"url"`
+	// RequestTimeout - таймаут одного запроса к Bot API
+	RequestTimeout time.Duration `yaml:"requestTimeout" validate:"gt=0"`
+}
+
+type ModerationConfig struct {
+	// BannedWords - слова, при которых объявление помечается флагом banned_words
+	BannedWords []string `yaml:"bannedWords"`
+	// Цена подозрительна, если она меньше PriceLowRatio или больше PriceHighRatio
+	// медианы одобренных объявлений категории; медиана учитывается от PriceMinSamples объявлений
+	PriceLowRatio   float64 `yaml:"priceLowRatio" validate:"gte=0"`
+	PriceHighRatio  float64 `yaml:"priceHighRatio" validate:"gte=0"`
+	PriceMinSamples int     `yaml:"priceMinSamples" validate:"gte=1"`
+	// ReportsToHide - после стольких жалоб разных пользователей объявление
+	// скрывается до решения модератора; 0 - не скрывать автоматически
+	ReportsToHide int `yaml:"reportsToHide" validate:"gte=0"`
+}
+
+type PhoneConfig struct {
+	// DefaultCountryCode - код страны для номеров без "+", например 7
+	DefaultCountryCode string        `yaml:"defaultCountryCode" validate:"omitempty,numeric,max=3"`
+	CodeLength         int           `yaml:"codeLength" validate:"gte=4,lte=8"`
+	CodeTTL            time.Duration `yaml:"codeTTL" validate:"gt=0"`
+	// MaxAttempts - сколько раз можно ввести код, дальше нужен новый
+	MaxAttempts int `yaml:"maxAttempts" validate:"gte=1"`
+	// ResendInterval - не чаще одного кода за интервал
+	ResendInterval time.Duration `yaml:"resendInterval"`
+	// CodeSecret - ключ HMAC для хранения кодов, задается через
+	// APP_PHONE_CODESECRET(_FILE). Если пуст, ключ генерируется при старте
+	// и выданные коды не переживают перезапуск.
+	CodeSecret string `yaml:"codeSecret"`
+}
+
+type SMSConfig struct {
+	// Provider - кто отправляет SMS с кодами: smsru или log. log пишет коды в
+	// лог и запускается только с Server.dev. Без провайдера сервис не стартует.
+	Provider string `yaml:"provider" validate:"omitempty,oneof=smsru log"`
+	// APIID - ключ sms.ru, задается через APP_SMS_APIID(_FILE)
+	APIID string `yaml:"apiID" validate:"required_if=Provider smsru"`
+	// From - согласованное в sms.ru имя отправителя; пусто - имя по умолчанию
+	From string `yaml:"from"`
+	// APIBaseURL - адрес API sms.ru; в тестах подменяется локальной заглушкой
+	APIBaseURL string `yaml:"apiBaseURL" validate:"required_if=Provider smsru,omitempty,url"`
+	// RequestTimeout - таймаут одного запроса к провайдеру
+	RequestTimeout time.Duration `yaml:"requestTimeout" validate:"gt=0"`
+}
+
+type PublisherConfig struct {
+	// Interval - как часто воркер публикует отложенные объявления
+	Interval time.Duration `yaml:"interval" validate:"gt=0"`
+	// BatchSize - сколько объявлений публикуется одним запросом
+	BatchSize int `yaml:"batchSize" validate:"gte=1"`
+}
+
+type GeoConfig struct {
+	// PostGIS - считать расстояния через расширение postgis (ST_DWithin/ST_Distance);
+	// без него используется формула гаверсинуса на чистом SQL
+	PostGIS bool `yaml:"postgis"`
+}
+
+type SavedSearchConfig struct {
+	// MaxPerUser - сколько поисков может сохранить один пользователь
+	MaxPerUser int `yaml:"maxPerUser" validate:"gte=1"`
+	// DigestInterval - как часто воркер проверяет, кому пора отправить суточный дайджест
+	DigestInterval time.Duration `yaml:"digestInterval" validate:"gt=0"`
+	// DigestBatchSize - сколько поисков обрабатывается одним запросом
+	DigestBatchSize int `yaml:"digestBatchSize" validate:"gte=1"`
+}
+
+type NotificationConfig struct {
+	// DeliveryInterval - как часто воркер отправляет уведомления во внешние каналы
+	DeliveryInterval time.Duration `yaml:"deliveryInterval" validate:"gt=0"`
+	// DeliveryBatchSize - сколько отправок берется за одну итерацию
+	DeliveryBatchSize int `yaml:"deliveryBatchSize" validate:"gte=1"`
+	// MaxAttempts - после стольких неудачных попыток отправка считается проваленной
+	MaxAttempts int `yaml:"maxAttempts" validate:"gte=1"`
+	// RetryDelay - пауза перед повтором, растет с каждой попыткой
+	RetryDelay time.Duration `yaml:"retryDelay" validate:"gt=0"`
+	// PromotionExpiryNotice - за сколько до окончания продвижения предупредить владельца
+	PromotionExpiryNotice time.Duration `yaml:"promotionExpiryNotice" validate:"gt=0"`
+}
+
+type WebhookConfig struct {
+	// MaxPerUser - сколько подписок может создать один пользователь
+	MaxPerUser int `yaml:"maxPerUser" validate:"gte=1"`
+	// DeliveryInterval - как часто воркер отправляет очередь вебхуков
+	DeliveryInterval time.Duration `yaml:"deliveryInterval" validate:"gt=0"`
+	// DeliveryBatchSize - сколько отправок берется за одну итерацию
+	DeliveryBatchSize int `yaml:"deliveryBatchSize" validate:"gte=1"`
+	// MaxAttempts - после стольких неудачных попыток отправка считается проваленной
+	MaxAttempts int `yaml:"maxAttempts" validate:"gte=1"`
+	// Пауза перед повтором удваивается с каждой попыткой, начиная с RetryBaseDelay,
+	// но не больше RetryMaxDelay
+	RetryBaseDelay time.Duration `yaml:"retryBaseDelay" validate:"gt=0"`
+	RetryMaxDelay  time.Duration `yaml:"retryMaxDelay" validate:"gtefield=RetryBaseDelay"`
+	// RequestTimeout - таймаут одного запроса к получателю
+	RequestTimeout time.Duration `yaml:"requestTimeout" validate:"gt=0"`
+	// AllowInsecure разрешает http:// и адреса локальной сети - только для
+	// разработки и тестов с локальной заглушкой
+	AllowInsecure bool `yaml:"allowInsecure"`
+}
+
+type OutboxConfig struct {
+	// Interval - как часто воркер-ретранслятор проверяет outbox
+	Interval time.Duration `yaml:"interval" validate:"gt=0"`
+	// BatchSize - сколько событий берется одной транзакцией
+	BatchSize int `yaml:"batchSize" validate:"gte=1"`
+	// MaxAttempts - после стольких неудачных обработок событие помечается failed
+	MaxAttempts int `yaml:"maxAttempts" validate:"gte=1"`
+	// RetryDelay - пауза перед повтором, растет с каждой попыткой
+	RetryDelay time.Duration `yaml:"retryDelay" validate:"gt=0"`
+}
+
+type CacheConfig struct {
+	// Enabled - кешировать карточки объявлений, профили и списки категорий
+	Enabled bool `yaml:"enabled"`
+	// MaxEntries - емкость кеша в памяти процесса
+	MaxEntries int `yaml:"maxEntries" validate:"gte=1"`
+	// Время жизни записей; изменения через API сбрасывают их сразу
+	AdTTL         time.Duration `yaml:"adTTL" validate:"gt=0"`
+	ProfileTTL    time.Duration `yaml:"profileTTL" validate:"gt=0"`
+	CategoriesTTL time.Duration `yaml:"categoriesTTL" validate:"gt=0"`
 }
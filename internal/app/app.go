@@ -2,10 +2,16 @@ package app
 
 import (
 	"backend/config"
+	"backend/internal/cache"
 	"backend/internal/domain/delivery"
 	"backend/internal/domain/repository"
 	"backend/internal/domain/usecase"
-	"context"
+	"backend/internal/metrics"
+	"backend/internal/notify"
+	"backend/internal/ratelimit"
+	"backend/internal/sms"
+	"backend/internal/tracing"
+	"backend/internal/webhook"
 
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
@@ -15,12 +21,18 @@ import (
 func New() *fx.App {
 	return fx.New(
 		fx.Options(
+			tracing.New(),
+			metrics.New(),
+			ratelimit.New(),
+			sms.New(),
+			notify.New(),
+			webhook.New(),
+			cache.New(),
 			repository.New(),
 			usecase.New(),
 			server.New(),
 		),
 		fx.Provide(
-			context.Background,
 			config.NewConfig,
 			zap.NewProduction,
 		),
@@ -30,4 +42,4 @@ func New() *fx.App {
 			},
 		),
 	)
-}
\ No newline at end of file
+}
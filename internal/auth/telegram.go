@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoHash      = errors.New("init data has no hash")
+	ErrBadHash     = errors.New("init data hash mismatch")
+	ErrExpired     = errors.New("init data expired")
+	ErrNoUser      = errors.New("init data has no user")
+	ErrBadAuthDate = errors.New("init data has invalid auth_date")
+)
+
+type TelegramUser struct {
+	ID        uint64 `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	PhotoURL  string `json:"photo_url"`
+}
+
+// ParseInitData проверяет подпись initData Telegram Mini App
+// (https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app)
+// и возвращает пользователя. ttl <= 0 отключает проверку срока.
+func ParseInitData(initData, botToken string, ttl time.Duration, now time.Time) (*TelegramUser, error) {
+	vals, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, err
+	}
+	hash := vals.Get("hash")
+	if hash == "" {
+		return nil, ErrNoHash
+	}
+
+	pairs := make([]string, 0, len(vals))
+	for k := range vals {
+		if k == "hash" {
+			continue
+		}
+		pairs = append(pairs, k+"="+vals.Get(k))
+	}
+	sort.Strings(pairs)
+
+	secret := hmacSHA256([]byte("WebAppData"), []byte(botToken))
+	expected := hex.EncodeToString(hmacSHA256(secret, []byte(strings.Join(pairs, "\n"))))
+	if !hmac.Equal([]byte(expected), []byte(hash)) {
+		return nil, ErrBadHash
+	}
+
+	authDate, err := strconv.ParseInt(vals.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, ErrBadAuthDate
+	}
+	if ttl > 0 && now.Sub(time.Unix(authDate, 0)) > ttl {
+		return nil, ErrExpired
+	}
+
+	var user TelegramUser
+	if err = json.Unmarshal([]byte(vals.Get("user")), &user); err != nil || user.ID == 0 {
+		return nil, ErrNoUser
+	}
+	return &user, nil
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
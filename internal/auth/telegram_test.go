@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBotToken = "123456:test-token"
+
+// signInitData собирает initData с подписью так же, как Telegram
+func signInitData(t *testing.T, botToken string, vals url.Values) string {
+	t.Helper()
+	pairs := make([]string, 0, len(vals))
+	for k := range vals {
+		pairs = append(pairs, k+"="+vals.Get(k))
+	}
+	sort.Strings(pairs)
+	secret := hmacSHA256([]byte("WebAppData"), []byte(botToken))
+	signed := url.Values{}
+	for k := range vals {
+		signed.Set(k, vals.Get(k))
+	}
+	signed.Set("hash", hex.EncodeToString(hmacSHA256(secret, []byte(strings.Join(pairs, "\n")))))
+	return signed.Encode()
+}
+
+func TestParseInitData(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	authDate := "1699999000" // за 1000 секунд до now
+	user := `{"id":42,"username":"ivan","first_name":"Иван","last_name":"Петров","photo_url":"https://t.me/i/42.jpg"}`
+
+	valid := signInitData(t, testBotToken, url.Values{
+		"auth_date": {authDate},
+		"query_id":  {"AAH"},
+		"user":      {user},
+	})
+
+	tests := []struct {
+		name     string
+		initData string
+		ttl      time.Duration
+		wantErr  error
+		wantID   uint64
+	}{
+		{name: "valid", initData: valid, ttl: time.Hour, wantID: 42},
+		{name: "ttl disabled", initData: valid, ttl: 0, wantID: 42},
+		{name: "expired", initData: valid, ttl: time.Minute, wantErr: ErrExpired},
+		{
+			name:     "no hash",
+			initData: url.Values{"auth_date": {authDate}, "user": {user}}.Encode(),
+			ttl:      time.Hour,
+			wantErr:  ErrNoHash,
+		},
+		{
+			name:     "tampered field",
+			initData: strings.Replace(valid, "query_id=AAH", "query_id=AAX", 1),
+			ttl:      time.Hour,
+			wantErr:  ErrBadHash,
+		},
+		{
+			name: "other bot token",
+			initData: signInitData(t, "654321:other", url.Values{
+				"auth_date": {authDate},
+				"user":      {user},
+			}),
+			ttl:     time.Hour,
+			wantErr: ErrBadHash,
+		},
+		{
+			name: "bad auth_date",
+			initData: signInitData(t, testBotToken, url.Values{
+				"auth_date": {"yesterday"},
+				"user":      {user},
+			}),
+			ttl:     time.Hour,
+			wantErr: ErrBadAuthDate,
+		},
+		{
+			name: "no user",
+			initData: signInitData(t, testBotToken, url.Values{
+				"auth_date": {authDate},
+			}),
+			ttl:     time.Hour,
+			wantErr: ErrNoUser,
+		},
+		{
+			name: "user without id",
+			initData: signInitData(t, testBotToken, url.Values{
+				"auth_date": {authDate},
+				"user":      {`{"username":"ivan"}`},
+			}),
+			ttl:     time.Hour,
+			wantErr: ErrNoUser,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInitData(tt.initData, testBotToken, tt.ttl, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("ID = %d, want %d", got.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestParseInitDataUserFields(t *testing.T) {
+	initData := signInitData(t, testBotToken, url.Values{
+		"auth_date": {"1700000000"},
+		"user":      {`{"id":7,"username":"anna","first_name":"Анна","last_name":"Смирнова","photo_url":"https://t.me/i/7.jpg"}`},
+	})
+	got, err := ParseInitData(initData, testBotToken, 0, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := TelegramUser{ID: 7, Username: "anna", FirstName: "Анна", LastName: "Смирнова", PhotoURL: "https://t.me/i/7.jpg"}
+	if *got != want {
+		t.Errorf("user = %+v, want %+v", *got, want)
+	}
+}
@@ -0,0 +1,17 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache - хранилище сериализованных ответов с временем жизни. Промах -
+// (nil, false, nil); ошибка означает, что хранилище недоступно, и вызывающий
+// должен пойти в базу.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Add записывает значение, только если ключа нет; false - ключ уже есть
+	Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+}
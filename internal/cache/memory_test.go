@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var cacheEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestMemoryCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		run     func(m *MemoryCache)
+		present []string
+		absent  []string
+	}{
+		{
+			name: "oldest is evicted",
+			run: func(m *MemoryCache) {
+				_ = m.Set(ctx, "a", []byte("1"), time.Minute)
+				_ = m.Set(ctx, "b", []byte("2"), time.Minute)
+				_ = m.Set(ctx, "c", []byte("3"), time.Minute)
+			},
+			present: []string{"b", "c"},
+			absent:  []string{"a"},
+		},
+		{
+			name: "read moves entry to front",
+			run: func(m *MemoryCache) {
+				_ = m.Set(ctx, "a", []byte("1"), time.Minute)
+				_ = m.Set(ctx, "b", []byte("2"), time.Minute)
+				_, _, _ = m.Get(ctx, "a")
+				_ = m.Set(ctx, "c", []byte("3"), time.Minute)
+			},
+			present: []string{"a", "c"},
+			absent:  []string{"b"},
+		},
+		{
+			name: "overwrite moves entry to front",
+			run: func(m *MemoryCache) {
+				_ = m.Set(ctx, "a", []byte("1"), time.Minute)
+				_ = m.Set(ctx, "b", []byte("2"), time.Minute)
+				_ = m.Set(ctx, "a", []byte("1'"), time.Minute)
+				_ = m.Set(ctx, "c", []byte("3"), time.Minute)
+			},
+			present: []string{"a", "c"},
+			absent:  []string{"b"},
+		},
+		{
+			name: "delete frees a slot",
+			run: func(m *MemoryCache) {
+				_ = m.Set(ctx, "a", []byte("1"), time.Minute)
+				_ = m.Set(ctx, "b", []byte("2"), time.Minute)
+				_ = m.Delete(ctx, "a", "missing")
+				_ = m.Set(ctx, "c", []byte("3"), time.Minute)
+			},
+			present: []string{"b", "c"},
+			absent:  []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemoryCache(2)
+			tt.run(m)
+			if m.order.Len() != len(m.entries) || len(m.entries) > 2 {
+				t.Fatalf("entries = %d, list = %d, want at most 2 in both", len(m.entries), m.order.Len())
+			}
+			for _, key := range tt.present {
+				if _, ok, _ := m.Get(ctx, key); !ok {
+					t.Errorf("%q was evicted", key)
+				}
+			}
+			for _, key := range tt.absent {
+				if _, ok, _ := m.Get(ctx, key); ok {
+					t.Errorf("%q is still cached", key)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryCache(10)
+	now := cacheEpoch
+	m.now = func() time.Time { return now }
+	_ = m.Set(ctx, "a", []byte("1"), time.Minute)
+
+	steps := []struct {
+		advance time.Duration
+		want    bool
+	}{
+		{0, true},
+		{time.Minute - time.Nanosecond, true},
+		{time.Nanosecond, false}, // ровно на expires запись уже просрочена
+	}
+	for _, st := range steps {
+		now = now.Add(st.advance)
+		value, ok, err := m.Get(ctx, "a")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok != st.want {
+			t.Fatalf("at %v: ok = %v, want %v", now.Format(time.RFC3339Nano), ok, st.want)
+		}
+		if ok && string(value) != "1" {
+			t.Fatalf("value = %q, want %q", value, "1")
+		}
+	}
+	if _, ok := m.entries["a"]; ok {
+		t.Error("expired entry was not removed on read")
+	}
+}
+
+func TestMemoryCacheAdd(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryCache(10)
+	now := cacheEpoch
+	m.now = func() time.Time { return now }
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		value   string
+		want    bool
+		cached  string
+	}{
+		{"empty slot", 0, "1", true, "1"},
+		{"live entry is kept", 0, "2", false, "1"},
+		{"expired entry is replaced", time.Minute, "3", true, "3"},
+	}
+	for _, st := range steps {
+		now = now.Add(st.advance)
+		added, err := m.Add(ctx, "a", []byte(st.value), time.Minute)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", st.name, err)
+		}
+		if added != st.want {
+			t.Errorf("%s: Add = %v, want %v", st.name, added, st.want)
+		}
+		if value, _, _ := m.Get(ctx, "a"); string(value) != st.cached {
+			t.Errorf("%s: cached = %q, want %q", st.name, value, st.cached)
+		}
+	}
+}
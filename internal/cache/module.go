@@ -0,0 +1,22 @@
+package cache
+
+import (
+	"backend/config"
+
+	"go.uber.org/fx"
+)
+
+// New отдает MemoryCache. Для нескольких инстансов Cache подменяется через
+// fx.Decorate на NewRedisCache с клиентом общего Redis.
+func New() fx.Option {
+	return fx.Module("cache",
+		fx.Provide(
+			fx.Annotate(
+				func(cfg *config.ConfigModel) *MemoryCache {
+					return NewMemoryCache(cfg.Cache.MaxEntries)
+				},
+				fx.As(new(Cache)),
+			),
+		),
+	)
+}
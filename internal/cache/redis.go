@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RedisClient - минимум, который нужен RedisCache: одна команда Redis-протокола.
+// Промах GET должен вернуться как (nil, nil), например для go-redis:
+// v, err := rdb.Do(ctx, args...).Result(); if err == redis.Nil { return nil, nil }.
+type RedisClient interface {
+	Do(ctx context.Context, args ...interface{}) (interface{}, error)
+}
+
+// RedisCache хранит записи в общем Redis (KeyDB, Dragonfly): инвалидация
+// видна всем инстансам сразу
+type RedisCache struct {
+	client RedisClient
+	prefix string
+}
+
+func NewRedisCache(client RedisClient, prefix string) *RedisCache {
+	return &RedisCache{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (r *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	raw, err := r.client.Do(ctx, "GET", r.prefix+key)
+	if err != nil {
+		return nil, false, err
+	}
+	switch v := raw.(type) {
+	case nil:
+		return nil, false, nil
+	case string:
+		return []byte(v), true, nil
+	case []byte:
+		return v, true, nil
+	}
+	return nil, false, fmt.Errorf("cache: unexpected redis reply %T", raw)
+}
+
+func (r *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := r.client.Do(ctx, "SET", r.prefix+key, value, "PX", ttl.Milliseconds())
+	return err
+}
+
+func (r *RedisCache) Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	raw, err := r.client.Do(ctx, "SET", r.prefix+key, value, "PX", ttl.Milliseconds(), "NX")
+	if err != nil {
+		return false, err
+	}
+	// без NX-записи Redis отвечает nil, иначе OK
+	return raw != nil, nil
+}
+
+func (r *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, r.prefix+key)
+	}
+	_, err := r.client.Do(ctx, args...)
+	return err
+}
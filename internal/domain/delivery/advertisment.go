@@ -0,0 +1,279 @@
+package server
+
+import (
+	"backend/common"
+	"backend/internal/domain/entities"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type advertismentRequest struct {
+	ID          uint64            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       float64           `json:"price"`
+	Location    string            `json:"location"`
+	CategoryID  uint64            `json:"category_id"`
+	Attributes  map[string]string `json:"attributes"`
+	Photos      []string          `json:"photos"`
+	// Status при создании: active (по умолчанию), draft или scheduled
+	Status string `json:"status"`
+	// PublishAt - время публикации для scheduled, RFC 3339
+	PublishAt *time.Time `json:"publish_at"`
+	CityID    uint64     `json:"city_id"`
+	Latitude  *float64   `json:"latitude"`
+	Longitude *float64   `json:"longitude"`
+}
+
+func (req *advertismentRequest) toAdvertisment(uID uint64) *entities.Advertisment {
+	advertisment := &entities.Advertisment{
+		ID:                   req.ID,
+		User:                 entities.User{ID: uID},
+		Name:                 req.Name,
+		Description:          req.Description,
+		Price:                req.Price,
+		Location:             req.Location,
+		AdvertismentCategory: entities.AdvertismentCategory{ID: req.CategoryID},
+		Status:               req.Status,
+		PublishAt:            req.PublishAt,
+		City:                 entities.City{ID: req.CityID},
+		Latitude:             req.Latitude,
+		Longitude:            req.Longitude,
+	}
+	for _, path := range req.Photos {
+		advertisment.Photos = append(advertisment.Photos, entities.AdPhoto{Path: path})
+	}
+	return advertisment
+}
+
+func (s *Server) CreateAdvertisment(FCtx *fiber.Ctx) error {
+	var req advertismentRequest
+	if err := FCtx.BodyParser(&req); err != nil {
+		s.requestLogger(FCtx).Error("Invalid advertisment body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	advertisment := req.toAdvertisment(uID)
+	advertisment.ID = 0
+	if err := s.Usecase.CreateAdvertisment(FCtx.UserContext(), advertisment, req.Attributes); err != nil {
+		s.requestLogger(FCtx).Error("Can not create advertisment", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.Status(fiber.StatusCreated).JSON(advertisment)
+}
+
+func (s *Server) UpdateAdvertisment(FCtx *fiber.Ctx) error {
+	var req advertismentRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.ID == 0 {
+		s.requestLogger(FCtx).Error("Invalid advertisment body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	advertisment := req.toAdvertisment(uID)
+	if err := s.Usecase.UpdateAdvertisment(FCtx.UserContext(), uID, advertisment, req.Attributes); err != nil {
+		s.requestLogger(FCtx).Error("Can not update advertisment", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.JSON(advertisment)
+}
+
+const attrParamPrefix = "attr."
+
+type advertismentIDRequest struct {
+	AdID uint64 `json:"ad_id"`
+}
+
+// changeAdvertismentStatus - общий обработчик архивации, удаления и восстановления
+func (s *Server) changeAdvertismentStatus(status string) fiber.Handler {
+	return func(FCtx *fiber.Ctx) error {
+		var req advertismentIDRequest
+		if err := FCtx.BodyParser(&req); err != nil || req.AdID == 0 {
+			s.requestLogger(FCtx).Error("Invalid advertisment status body", zap.Error(err))
+			return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+		}
+		uID, _ := userIDFromCtx(FCtx)
+		advertisment := &entities.Advertisment{
+			ID:     req.AdID,
+			Status: status,
+		}
+		if err := s.Usecase.ChangeAdvertismentStatus(FCtx.UserContext(), uID, advertisment); err != nil {
+			s.requestLogger(FCtx).Error("Can not change advertisment status", zap.String("status", status), zap.Error(err))
+			return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+		}
+		return FCtx.JSON(fiber.Map{
+			"ad_id":             advertisment.ID,
+			"status":            advertisment.Status,
+			"status_changed_at": advertisment.StatusChangedAt,
+		})
+	}
+}
+
+type publishAdvertismentRequest struct {
+	AdID uint64 `json:"ad_id"`
+	// PublishAt - опубликовать позже; без него объявление публикуется сразу
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+func (s *Server) PublishAdvertisment(FCtx *fiber.Ctx) error {
+	var req publishAdvertismentRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.AdID == 0 {
+		s.requestLogger(FCtx).Error("Invalid publish advertisment body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	advertisment := &entities.Advertisment{
+		ID:        req.AdID,
+		PublishAt: req.PublishAt,
+	}
+	if err := s.Usecase.PublishAdvertisment(FCtx.UserContext(), uID, advertisment); err != nil {
+		s.requestLogger(FCtx).Error("Can not publish advertisment", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.JSON(fiber.Map{
+		"ad_id":          advertisment.ID,
+		"status":         advertisment.Status,
+		"publish_at":     advertisment.PublishAt,
+		"date_placement": advertisment.DatePlacement,
+	})
+}
+
+type promoteAdvertismentRequest struct {
+	AdID   uint64 `json:"ad_id"`
+	TypeID uint64 `json:"type_id"`
+}
+
+func (s *Server) PromoteAdvertisment(FCtx *fiber.Ctx) error {
+	var req promoteAdvertismentRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.AdID == 0 || req.TypeID == 0 {
+		s.requestLogger(FCtx).Error("Invalid promote advertisment body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	advertisment := &entities.Advertisment{
+		ID:            req.AdID,
+		TypePromotion: entities.TypePromotion{ID: req.TypeID},
+	}
+	if err := s.Usecase.PromoteAdvertisment(FCtx.UserContext(), uID, advertisment); err != nil {
+		s.requestLogger(FCtx).Error("Can not promote advertisment", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.JSON(fiber.Map{
+		"ad_id":                 advertisment.ID,
+		"type_promotion":        advertisment.TypePromotion,
+		"date_expire_promotion": advertisment.DateExpirePromotion,
+	})
+}
+
+// parseAdvertismentFilter читает фильтр поиска из query:
+// category_id, q, price_min, price_max, sort, limit, offset,
+// city_id, lat, lon, radius_km,
+// attr.<code>=<value>, attr.<code>.min=<n>, attr.<code>.max=<n>
+func parseAdvertismentFilter(FCtx *fiber.Ctx) (*entities.AdvertismentFilter, error) {
+	filter := &entities.AdvertismentFilter{
+		Query: strings.TrimSpace(FCtx.Query("q")),
+		Sort:  FCtx.Query("sort"),
+	}
+	var err error
+	if filter.CategoryID, err = parseUintParam(FCtx, "category_id"); err != nil {
+		return nil, err
+	}
+	if filter.Limit, err = parseUintParam(FCtx, "limit"); err != nil {
+		return nil, err
+	}
+	if filter.Offset, err = parseUintParam(FCtx, "offset"); err != nil {
+		return nil, err
+	}
+	if filter.PriceMin, err = parseFloatParam(FCtx.Query("price_min")); err != nil {
+		return nil, err
+	}
+	if filter.PriceMax, err = parseFloatParam(FCtx.Query("price_max")); err != nil {
+		return nil, err
+	}
+	if filter.CityID, err = parseUintParam(FCtx, "city_id"); err != nil {
+		return nil, err
+	}
+	if filter.Lat, err = parseFloatParam(FCtx.Query("lat")); err != nil {
+		return nil, err
+	}
+	if filter.Lon, err = parseFloatParam(FCtx.Query("lon")); err != nil {
+		return nil, err
+	}
+	if filter.RadiusKm, err = parseFloatParam(FCtx.Query("radius_km")); err != nil {
+		return nil, err
+	}
+
+	attrs := map[string]*entities.AttributeFilter{}
+	var order []string
+	for key, value := range FCtx.Queries() {
+		if !strings.HasPrefix(key, attrParamPrefix) {
+			continue
+		}
+		code := strings.TrimPrefix(key, attrParamPrefix)
+		bound := ""
+		if i := strings.LastIndex(code, "."); i >= 0 {
+			code, bound = code[:i], code[i+1:]
+		}
+		attr, ok := attrs[code]
+		if !ok {
+			attr = &entities.AttributeFilter{Code: code}
+			attrs[code] = attr
+			order = append(order, code)
+		}
+		switch bound {
+		case "":
+			v := value
+			attr.Value = &v
+		case "min":
+			if attr.Min, err = parseFloatParam(value); err != nil {
+				return nil, err
+			}
+		case "max":
+			if attr.Max, err = parseFloatParam(value); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, strconv.ErrSyntax
+		}
+	}
+	for _, code := range order {
+		filter.Attributes = append(filter.Attributes, *attrs[code])
+	}
+	return filter, nil
+}
+
+func parseUintParam(FCtx *fiber.Ctx, name string) (uint64, error) {
+	raw := FCtx.Query(name)
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
+
+func parseFloatParam(raw string) (*float64, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (s *Server) SearchAdvertisments(FCtx *fiber.Ctx) error {
+	filter, err := parseAdvertismentFilter(FCtx)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Invalid search parameters", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	ads, err := s.Usecase.SearchAdvertisments(FCtx.UserContext(), filter)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not search advertisments", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(ads)
+}
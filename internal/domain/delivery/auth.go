@@ -0,0 +1,95 @@
+package server
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"backend/common"
+	"backend/internal/auth"
+	"backend/internal/domain/usecase"
+	"backend/internal/rbac"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// authScheme - Authorization: tma <initData Telegram Mini App>
+const authScheme = "tma "
+
+// authenticate проверяет initData, если клиент его передал, и кладет id
+// пользователя в Locals. Запрос без заголовка проходит анонимно.
+func (s *Server) authenticate(FCtx *fiber.Ctx) error {
+	header := FCtx.Get(fiber.HeaderAuthorization)
+	if header == "" {
+		return FCtx.Next()
+	}
+	if s.cfg.Telegram.BotToken == "" || !strings.HasPrefix(header, authScheme) {
+		return unauthorized(FCtx)
+	}
+	user, err := auth.ParseInitData(strings.TrimPrefix(header, authScheme), s.cfg.Telegram.BotToken, s.cfg.Auth.InitDataTTL, time.Now())
+	if err != nil {
+		s.requestLogger(FCtx).Warn("invalid init data", zap.Error(err))
+		return unauthorized(FCtx)
+	}
+	FCtx.Locals(localsUserID, user.ID)
+	return FCtx.Next()
+}
+
+func (s *Server) requireAuth(FCtx *fiber.Ctx) error {
+	if _, ok := userIDFromCtx(FCtx); !ok {
+		return unauthorized(FCtx)
+	}
+	return FCtx.Next()
+}
+
+// requirePermission пускает только пользователей, чья роль имеет право perm
+func (s *Server) requirePermission(perm rbac.Permission) fiber.Handler {
+	return func(FCtx *fiber.Ctx) error {
+		uID, ok := userIDFromCtx(FCtx)
+		if !ok {
+			return unauthorized(FCtx)
+		}
+		if err := s.Usecase.Authorize(FCtx.UserContext(), uID, perm); err != nil {
+			return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+		}
+		return FCtx.Next()
+	}
+}
+
+func unauthorized(FCtx *fiber.Ctx) error {
+	return FCtx.Status(fiber.StatusUnauthorized).JSON(
+		fiber.Map{
+			"message": fiber.Map{
+				"status": common.StatusUnauthorized,
+				"text":   common.ErrUnauthorized,
+			},
+		},
+	)
+}
+
+// writeError переводит известные ошибки usecase в HTTP-статус, остальные -
+// 400 с переданным статусом
+func (s *Server) writeError(FCtx *fiber.Ctx, err error, status int, text string) error {
+	code := fiber.StatusBadRequest
+	switch {
+	case errors.Is(err, usecase.ErrForbidden):
+		code, status, text = fiber.StatusForbidden, common.StatusForbidden, common.ErrForbidden
+	case errors.Is(err, usecase.ErrConflict):
+		code, status, text = fiber.StatusConflict, common.StatusConflict, common.ErrConflict
+	case errors.Is(err, usecase.ErrNotFound):
+		code, status, text = fiber.StatusNotFound, common.StatusNotFound, common.ErrNotFound
+	case errors.Is(err, usecase.ErrTooManyRequests):
+		code, status, text = fiber.StatusTooManyRequests, common.StatusTooManyRequests, common.ErrTooManyRequests
+	case errors.Is(err, usecase.ErrInvalidData):
+		status, text = common.StatusInvalidParams, common.ErrInvalidParams
+	}
+	return FCtx.Status(code).JSON(
+		fiber.Map{
+			"message": fiber.Map{
+				"status": status,
+				"text":   text,
+			},
+		},
+	)
+}
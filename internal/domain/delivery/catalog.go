@@ -0,0 +1,257 @@
+package server
+
+import (
+	"backend/common"
+	"backend/internal/domain/entities"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type categoryRequest struct {
+	ID       uint64 `json:"id"`
+	ParentID uint64 `json:"parent_id"`
+	Name     string `json:"name"`
+	IsActive *bool  `json:"is_active"`
+}
+
+type categoryAttributeRequest struct {
+	CategoryID uint64   `json:"category_id"`
+	Code       string   `json:"code"`
+	Name       string   `json:"name"`
+	ValueType  string   `json:"value_type"`
+	IsRequired bool     `json:"is_required"`
+	Options    []string `json:"options"`
+}
+
+type promotionTypeRequest struct {
+	ID    uint64  `json:"id"`
+	Name  string  `json:"name"`
+	Price float32 `json:"price"`
+	// TimeLive - длительность в формате Go: "72h", "168h"
+	TimeLive string `json:"time_live"`
+	IsActive *bool  `json:"is_active"`
+	// VerifiedOnly - тип доступен только верифицированным продавцам
+	VerifiedOnly bool `json:"verified_only"`
+}
+
+type deleteRequest struct {
+	ID uint64 `json:"id"`
+}
+
+func isActive(v *bool) bool {
+	return v == nil || *v
+}
+
+func (s *Server) GetCategories(FCtx *fiber.Ctx) error {
+	categories, err := s.Usecase.GetCategories(FCtx.UserContext(), true)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get categories", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(categories)
+}
+
+func (s *Server) GetPromotionTypes(FCtx *fiber.Ctx) error {
+	types, err := s.Usecase.GetPromotionTypes(FCtx.UserContext(), true)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get promotion types", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(types)
+}
+
+func (s *Server) GetCategoryTree(FCtx *fiber.Ctx) error {
+	tree, err := s.Usecase.GetCategoryTree(FCtx.UserContext())
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get category tree", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(tree)
+}
+
+func (s *Server) GetCategoryAttributes(FCtx *fiber.Ctx) error {
+	var categoryID int
+	var err error
+	if categoryID, err = strconv.Atoi(FCtx.Query("category_id")); err != nil || categoryID <= 0 {
+		s.requestLogger(FCtx).Error("Invalid category_id parameter", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	attributes, err := s.Usecase.GetCategoryAttributes(FCtx.UserContext(), uint64(categoryID))
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get category attributes", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(attributes)
+}
+
+func (s *Server) AdminCreateCategoryAttribute(FCtx *fiber.Ctx) error {
+	var req categoryAttributeRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.CategoryID == 0 {
+		s.requestLogger(FCtx).Error("Invalid category attribute body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	adminID, _ := userIDFromCtx(FCtx)
+	attr := &entities.CategoryAttribute{
+		CategoryID: req.CategoryID,
+		Code:       req.Code,
+		Name:       req.Name,
+		ValueType:  req.ValueType,
+		IsRequired: req.IsRequired,
+		Options:    req.Options,
+	}
+	if err := s.Usecase.CreateCategoryAttribute(FCtx.UserContext(), adminID, attr); err != nil {
+		s.requestLogger(FCtx).Error("Can not create category attribute", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.Status(fiber.StatusCreated).JSON(attr)
+}
+
+func (s *Server) AdminDeleteCategoryAttribute(FCtx *fiber.Ctx) error {
+	var req deleteRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.ID == 0 {
+		s.requestLogger(FCtx).Error("Invalid delete category attribute body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	adminID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.DeleteCategoryAttribute(FCtx.UserContext(), adminID, req.ID); err != nil {
+		s.requestLogger(FCtx).Error("Can not delete category attribute", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
+
+func (s *Server) AdminGetCategories(FCtx *fiber.Ctx) error {
+	categories, err := s.Usecase.GetCategories(FCtx.UserContext(), false)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get categories", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(categories)
+}
+
+func (s *Server) AdminCreateCategory(FCtx *fiber.Ctx) error {
+	var req categoryRequest
+	if err := FCtx.BodyParser(&req); err != nil {
+		s.requestLogger(FCtx).Error("Invalid category body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	adminID, _ := userIDFromCtx(FCtx)
+	category := &entities.AdvertismentCategory{
+		Name:     req.Name,
+		ParentID: req.ParentID,
+		IsActive: isActive(req.IsActive),
+	}
+	if err := s.Usecase.CreateCategory(FCtx.UserContext(), adminID, category); err != nil {
+		s.requestLogger(FCtx).Error("Can not create category", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.Status(fiber.StatusCreated).JSON(category)
+}
+
+func (s *Server) AdminUpdateCategory(FCtx *fiber.Ctx) error {
+	var req categoryRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.ID == 0 {
+		s.requestLogger(FCtx).Error("Invalid category body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	adminID, _ := userIDFromCtx(FCtx)
+	category := &entities.AdvertismentCategory{
+		ID:       req.ID,
+		Name:     req.Name,
+		ParentID: req.ParentID,
+		IsActive: isActive(req.IsActive),
+	}
+	if err := s.Usecase.UpdateCategory(FCtx.UserContext(), adminID, category); err != nil {
+		s.requestLogger(FCtx).Error("Can not update category", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.JSON(category)
+}
+
+func (s *Server) AdminDeleteCategory(FCtx *fiber.Ctx) error {
+	var req deleteRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.ID == 0 {
+		s.requestLogger(FCtx).Error("Invalid delete category body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	adminID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.DeleteCategory(FCtx.UserContext(), adminID, req.ID); err != nil {
+		s.requestLogger(FCtx).Error("Can not delete category", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
+
+func (s *Server) AdminGetPromotionTypes(FCtx *fiber.Ctx) error {
+	types, err := s.Usecase.GetPromotionTypes(FCtx.UserContext(), false)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get promotion types", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(types)
+}
+
+func parsePromotionType(FCtx *fiber.Ctx) (*entities.TypePromotion, error) {
+	var req promotionTypeRequest
+	if err := FCtx.BodyParser(&req); err != nil {
+		return nil, err
+	}
+	timeLive, err := time.ParseDuration(req.TimeLive)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.TypePromotion{
+		ID:           req.ID,
+		Name:         req.Name,
+		Price:        req.Price,
+		TimeLive:     timeLive,
+		IsActive:     isActive(req.IsActive),
+		VerifiedOnly: req.VerifiedOnly,
+	}, nil
+}
+
+func (s *Server) AdminCreatePromotionType(FCtx *fiber.Ctx) error {
+	tp, err := parsePromotionType(FCtx)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Invalid promotion type body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	adminID, _ := userIDFromCtx(FCtx)
+	tp.ID = 0
+	if err = s.Usecase.CreatePromotionType(FCtx.UserContext(), adminID, tp); err != nil {
+		s.requestLogger(FCtx).Error("Can not create promotion type", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.Status(fiber.StatusCreated).JSON(tp)
+}
+
+func (s *Server) AdminUpdatePromotionType(FCtx *fiber.Ctx) error {
+	tp, err := parsePromotionType(FCtx)
+	if err != nil || tp.ID == 0 {
+		s.requestLogger(FCtx).Error("Invalid promotion type body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	adminID, _ := userIDFromCtx(FCtx)
+	if err = s.Usecase.UpdatePromotionType(FCtx.UserContext(), adminID, tp); err != nil {
+		s.requestLogger(FCtx).Error("Can not update promotion type", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.JSON(tp)
+}
+
+func (s *Server) AdminDeletePromotionType(FCtx *fiber.Ctx) error {
+	var req deleteRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.ID == 0 {
+		s.requestLogger(FCtx).Error("Invalid delete promotion type body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	adminID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.DeletePromotionType(FCtx.UserContext(), adminID, req.ID); err != nil {
+		s.requestLogger(FCtx).Error("Can not delete promotion type", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/domain/entities"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Условные GET: ответ несет ETag и Last-Modified из updated_at, клиент
+// присылает их в If-None-Match / If-Modified-Since и получает 304, если
+// ресурс не менялся. Версия читается отдельным легким запросом до usecase.
+// FCtx.Fresh не подходит: с одним If-Modified-Since он не сравнивает даты.
+// Остальные маршруты /get версии не хранят, для них ETag - хеш тела ответа
+// (bodyETag): запрос в базу выполняется, но тело повторно не передается.
+
+// notModified отвечает на условный запрос: true, если у клиента актуальная
+// версия и ответ 304 уже записан. Без условных заголовков версия не читается.
+// Ошибку чтения версии (в том числе "не найдено") вернет обычный путь запроса.
+func (s *Server) notModified(FCtx *fiber.Ctx, version func(ctx context.Context) (time.Time, error)) bool {
+	noneMatch := FCtx.Get(fiber.HeaderIfNoneMatch)
+	modifiedSince := FCtx.Get(fiber.HeaderIfModifiedSince)
+	if noneMatch == "" && modifiedSince == "" {
+		return false
+	}
+	updatedAt, err := version(FCtx.UserContext())
+	if err != nil {
+		s.requestLogger(FCtx).Debug("fail to get resource version", zap.Error(err))
+		return false
+	}
+	if !isFresh(noneMatch, modifiedSince, s.etag(updatedAt), updatedAt) {
+		return false
+	}
+	s.setValidators(FCtx, updatedAt)
+	FCtx.Status(fiber.StatusNotModified)
+	return true
+}
+
+// setValidators ставит ETag и Last-Modified ответа. no-cache: клиент может
+// хранить ответ, но перед использованием должен его перепроверить.
+func (s *Server) setValidators(FCtx *fiber.Ctx, updatedAt time.Time) {
+	FCtx.Set(fiber.HeaderETag, s.etag(updatedAt))
+	FCtx.Set(fiber.HeaderLastModified, updatedAt.UTC().Format(http.TimeFormat))
+	FCtx.Set(fiber.HeaderCacheControl, "private, no-cache")
+}
+
+// etag - слабый: одинаковая версия данных может сериализоваться по-разному.
+// Версия приложения входит в тег, чтобы после релиза с другим форматом ответа
+// клиенты не получили 304 на старое тело.
+func (s *Server) etag(updatedAt time.Time) string {
+	tag := strconv.FormatInt(updatedAt.UnixMicro(), 36)
+	if v := s.cfg.Server.AppVersion; v != "" {
+		tag = v + "-" + tag
+	}
+	return `W/"` + tag + `"`
+}
+
+// bodyETag ставит ETag из хеша тела на успешные GET, которые не поставили
+// валидаторы сами, и заменяет ответ на 304 при совпадении с If-None-Match.
+// Тег сильный: одинаковое тело - одинаковые байты.
+func (s *Server) bodyETag(FCtx *fiber.Ctx) error {
+	if err := FCtx.Next(); err != nil {
+		return err
+	}
+	if FCtx.Method() != fiber.MethodGet || FCtx.Response().StatusCode() != fiber.StatusOK ||
+		FCtx.GetRespHeader(fiber.HeaderETag) != "" {
+		return nil
+	}
+	sum := sha256.Sum256(FCtx.Response().Body())
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:18]) + `"`
+	FCtx.Set(fiber.HeaderETag, etag)
+	FCtx.Set(fiber.HeaderCacheControl, "private, no-cache")
+	// без Last-Modified If-Modified-Since не с чем сравнивать
+	if noneMatch := FCtx.Get(fiber.HeaderIfNoneMatch); noneMatch != "" && isFresh(noneMatch, "", etag, time.Time{}) {
+		FCtx.Response().ResetBody()
+		FCtx.Status(fiber.StatusNotModified)
+	}
+	return nil
+}
+
+// isFresh проверяет предусловия по RFC 9110: If-None-Match главнее, и при нем
+// If-Modified-Since не учитывается
+func isFresh(noneMatch, modifiedSince, etag string, updatedAt time.Time) bool {
+	if noneMatch != "" {
+		for _, tag := range strings.Split(noneMatch, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(modifiedSince)
+	if err != nil {
+		return false
+	}
+	// Last-Modified передается с точностью до секунды
+	return !updatedAt.Truncate(time.Second).After(since)
+}
+
+// advertismentVersion - версия карточки так же, как ее считает
+// GetAdvertismentUpdatedAt: объявление с отзывами, фотографиями и атрибутами,
+// профиль продавца, профили авторов отзывов, категория, тип продвижения и город
+func advertismentVersion(ad *entities.Advertisment) (time.Time, bool) {
+	if ad.UpdatedAt == nil {
+		return time.Time{}, false
+	}
+	version := *ad.UpdatedAt
+	later := func(t *time.Time) {
+		if t != nil && t.After(version) {
+			version = *t
+		}
+	}
+	later(ad.User.UpdatedAt)
+	later(ad.AdvertismentCategory.UpdatedAt)
+	later(ad.TypePromotion.UpdatedAt)
+	later(ad.City.UpdatedAt)
+	for i := range ad.Reviews {
+		later(ad.Reviews[i].Reviewer.UpdatedAt)
+	}
+	return version, true
+}
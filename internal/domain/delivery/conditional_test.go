@@ -0,0 +1,199 @@
+package server
+
+import (
+	"backend/config"
+	"backend/internal/domain/entities"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestIsFresh(t *testing.T) {
+	updatedAt := time.Date(2024, 3, 1, 12, 0, 0, 500_000_000, time.UTC)
+	etag := `W/"abc"`
+	at := func(t time.Time) string { return t.Format(http.TimeFormat) }
+	tests := []struct {
+		name          string
+		noneMatch     string
+		modifiedSince string
+		want          bool
+	}{
+		{name: "no preconditions", want: false},
+		{name: "same etag", noneMatch: `W/"abc"`, want: true},
+		{name: "strong form of weak etag", noneMatch: `"abc"`, want: true},
+		{name: "etag in list", noneMatch: `"old", W/"abc" , "other"`, want: true},
+		{name: "any", noneMatch: "*", want: true},
+		{name: "other etag", noneMatch: `W/"old"`, want: false},
+		{
+			name:          "If-None-Match wins over If-Modified-Since",
+			noneMatch:     `W/"old"`,
+			modifiedSince: at(updatedAt.Add(time.Hour)),
+			want:          false,
+		},
+		{name: "modified since same second", modifiedSince: at(updatedAt), want: true},
+		{name: "modified since later", modifiedSince: at(updatedAt.Add(time.Hour)), want: true},
+		{name: "modified since earlier", modifiedSince: at(updatedAt.Add(-time.Second)), want: false},
+		{name: "bad date", modifiedSince: "yesterday", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isFresh(tt.noneMatch, tt.modifiedSince, etag, updatedAt); got != tt.want {
+				t.Errorf("isFresh(%q, %q) = %v, want %v", tt.noneMatch, tt.modifiedSince, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEtag(t *testing.T) {
+	updatedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		appVersion string
+		updatedAt  time.Time
+		want       string
+	}{
+		{"without app version", "", updatedAt, `W/"gtw7093400"`},
+		{"with app version", "1.4.0", updatedAt, `W/"1.4.0-gtw7093400"`},
+		{"microseconds change tag", "", updatedAt.Add(time.Microsecond), `W/"gtw7093401"`},
+		{"nanoseconds do not", "", updatedAt.Add(time.Nanosecond), `W/"gtw7093400"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{cfg: &config.ConfigModel{Server: config.ServerConfig{AppVersion: tt.appVersion}}}
+			if got := s.etag(tt.updatedAt); got != tt.want {
+				t.Errorf("etag() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodyETag(t *testing.T) {
+	s := &Server{}
+	app := fiber.New()
+	body := "{}"
+	app.Get("/list", s.bodyETag, func(FCtx *fiber.Ctx) error {
+		return FCtx.SendString(body)
+	})
+	app.Get("/own", s.bodyETag, func(FCtx *fiber.Ctx) error {
+		FCtx.Set(fiber.HeaderETag, `W/"own"`)
+		return FCtx.SendString(body)
+	})
+	app.Get("/fail", s.bodyETag, func(FCtx *fiber.Ctx) error {
+		return FCtx.Status(fiber.StatusBadRequest).SendString(body)
+	})
+	do := func(path, noneMatch string) *http.Response {
+		req := httptest.NewRequest(fiber.MethodGet, path, nil)
+		if noneMatch != "" {
+			req.Header.Set(fiber.HeaderIfNoneMatch, noneMatch)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	first := do("/list", "")
+	etag := first.Header.Get(fiber.HeaderETag)
+	if first.StatusCode != fiber.StatusOK || etag == "" {
+		t.Fatalf("first response: status %d, etag %q", first.StatusCode, etag)
+	}
+	tests := []struct {
+		name       string
+		path       string
+		noneMatch  string
+		body       string
+		wantStatus int
+		wantETag   string
+	}{
+		{"same body", "/list", etag, "{}", fiber.StatusNotModified, etag},
+		{"changed body", "/list", etag, `{"a":1}`, fiber.StatusOK, ""},
+		{"handler etag is kept", "/own", `W/"own"`, "{}", fiber.StatusOK, `W/"own"`},
+		{"errors are not tagged", "/fail", etag, "{}", fiber.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body = tt.body
+			resp := do(tt.path, tt.noneMatch)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			got := resp.Header.Get(fiber.HeaderETag)
+			switch {
+			case tt.wantETag != "" && got != tt.wantETag:
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			case tt.wantETag == "" && tt.wantStatus == fiber.StatusOK && (got == "" || got == etag):
+				t.Errorf("ETag = %q, want a new tag", got)
+			case tt.wantStatus != fiber.StatusOK && tt.wantStatus != fiber.StatusNotModified && got != "":
+				t.Errorf("ETag = %q on error response", got)
+			}
+		})
+	}
+}
+
+func TestAdvertismentVersion(t *testing.T) {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ptr := func(d time.Duration) *time.Time { v := base.Add(d); return &v }
+	reviewBy := func(updatedAt *time.Time) entities.Review {
+		return entities.Review{Reviewer: entities.User{UpdatedAt: updatedAt}}
+	}
+	tests := []struct {
+		name   string
+		ad     entities.Advertisment
+		want   time.Time
+		wantOK bool
+	}{
+		{name: "no version", ad: entities.Advertisment{}, wantOK: false},
+		{name: "ad only", ad: entities.Advertisment{UpdatedAt: ptr(0)}, want: base, wantOK: true},
+		{
+			name:   "seller changed later",
+			ad:     entities.Advertisment{UpdatedAt: ptr(0), User: entities.User{UpdatedAt: ptr(time.Hour)}},
+			want:   base.Add(time.Hour),
+			wantOK: true,
+		},
+		{
+			name: "review author changed later",
+			ad: entities.Advertisment{
+				UpdatedAt: ptr(0),
+				User:      entities.User{UpdatedAt: ptr(time.Minute)},
+				Reviews:   []entities.Review{reviewBy(nil), reviewBy(ptr(2 * time.Hour)), reviewBy(ptr(time.Hour))},
+			},
+			want:   base.Add(2 * time.Hour),
+			wantOK: true,
+		},
+		{
+			name: "catalog changed later",
+			ad: entities.Advertisment{
+				UpdatedAt:            ptr(0),
+				AdvertismentCategory: entities.AdvertismentCategory{UpdatedAt: ptr(time.Hour)},
+				TypePromotion:        entities.TypePromotion{UpdatedAt: ptr(3 * time.Hour)},
+				City:                 entities.City{UpdatedAt: ptr(2 * time.Hour)},
+			},
+			want:   base.Add(3 * time.Hour),
+			wantOK: true,
+		},
+		{
+			name:   "city renamed",
+			ad:     entities.Advertisment{UpdatedAt: ptr(0), City: entities.City{UpdatedAt: ptr(time.Minute)}},
+			want:   base.Add(time.Minute),
+			wantOK: true,
+		},
+		{
+			name:   "older profiles do not lower version",
+			ad:     entities.Advertisment{UpdatedAt: ptr(time.Hour), User: entities.User{UpdatedAt: ptr(0)}, Reviews: []entities.Review{reviewBy(ptr(0))}},
+			want:   base.Add(time.Hour),
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := advertismentVersion(&tt.ad)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("advertismentVersion() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"backend/internal/domain/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// lastWriteCookie и lastWriteHeader несут время последнего изменения
+	// пользователя (unix ms) к клиенту и обратно: так окно действует и на
+	// инстансах, которые изменения не видели. Клиенты без cookie повторяют
+	// заголовок из ответа на изменение.
+	lastWriteCookie = "last_write"
+	lastWriteHeader = "X-Last-Write"
+	// lastWriteClockSkew - допуск расхождения часов инстансов
+	lastWriteClockSkew = time.Second
+)
+
+// readYourWrites: после успешного изменяющего запроса пользователь в течение
+// Postgres.ReadYourWritesWindow читает из основной базы, чтобы не получить
+// с реплики данные до своего изменения. Время изменения запоминается в памяти
+// инстанса и отдается клиенту в cookie и заголовке X-Last-Write, поэтому
+// окно соблюдается и без sticky-сессий, если клиент их возвращает.
+// Подделанное значение лишь отправляет чтения самого клиента в основную базу.
+func (s *Server) readYourWrites(FCtx *fiber.Ctx) error {
+	uID, ok := userIDFromCtx(FCtx)
+	if !ok {
+		return FCtx.Next()
+	}
+	now := time.Now()
+	if s.writes.recent(uID, now) || s.clientWroteRecently(FCtx, now) {
+		FCtx.SetUserContext(usecase.ReadPrimary(FCtx.UserContext()))
+	}
+	err := FCtx.Next()
+	if err == nil && !isReadMethod(FCtx.Method()) && FCtx.Response().StatusCode() < fiber.StatusBadRequest {
+		now = time.Now()
+		s.writes.mark(uID, now)
+		value := strconv.FormatInt(now.UnixMilli(), 10)
+		FCtx.Set(lastWriteHeader, value)
+		FCtx.Cookie(&fiber.Cookie{
+			Name:     lastWriteCookie,
+			Value:    value,
+			Path:     "/",
+			Expires:  now.Add(s.writes.window),
+			HTTPOnly: true,
+			Secure:   FCtx.Protocol() == "https",
+			SameSite: fiber.CookieSameSiteLaxMode,
+		})
+	}
+	return err
+}
+
+// clientWroteRecently проверяет время изменения, которое вернул клиент
+func (s *Server) clientWroteRecently(FCtx *fiber.Ctx, now time.Time) bool {
+	value := FCtx.Get(lastWriteHeader)
+	if value == "" {
+		value = FCtx.Cookies(lastWriteCookie)
+	}
+	if value == "" {
+		return false
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+	at := time.UnixMilli(ms)
+	return !at.After(now.Add(lastWriteClockSkew)) && now.Sub(at) < s.writes.window
+}
+
+func isReadMethod(method string) bool {
+	return method == fiber.MethodGet || method == fiber.MethodHead || method == fiber.MethodOptions
+}
+
+// recentWrites - время последнего изменения по пользователям. Устаревшие
+// записи вычищаются не чаще раза в окно.
+type recentWrites struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	writes    map[uint64]time.Time
+	lastPrune time.Time
+}
+
+func newRecentWrites(window time.Duration) *recentWrites {
+	return &recentWrites{
+		window: window,
+		writes: map[uint64]time.Time{},
+	}
+}
+
+func (w *recentWrites) mark(uID uint64, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes[uID] = now
+	if now.Sub(w.lastPrune) < w.window {
+		return
+	}
+	for id, at := range w.writes {
+		if now.Sub(at) >= w.window {
+			delete(w.writes, id)
+		}
+	}
+	w.lastPrune = now
+}
+
+func (w *recentWrites) recent(uID uint64, now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	at, ok := w.writes[uID]
+	return ok && now.Sub(at) < w.window
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"backend/common"
+	"backend/internal/domain/entities"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// GetCities: ?q=<начало названия>&limit=
+func (s *Server) GetCities(FCtx *fiber.Ctx) error {
+	limit, err := parseUintParam(FCtx, "limit")
+	if err != nil {
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	cities, err := s.Usecase.GetCities(FCtx.UserContext(), FCtx.Query("q"), limit)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get cities", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(cities)
+}
+
+type cityRequest struct {
+	Name      string  `json:"name"`
+	Region    string  `json:"region"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (s *Server) AdminCreateCity(FCtx *fiber.Ctx) error {
+	var req cityRequest
+	if err := FCtx.BodyParser(&req); err != nil {
+		s.requestLogger(FCtx).Error("Invalid city body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	adminID, _ := userIDFromCtx(FCtx)
+	city := &entities.City{
+		Name:      req.Name,
+		Region:    req.Region,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}
+	if err := s.Usecase.CreateCity(FCtx.UserContext(), adminID, city); err != nil {
+		s.requestLogger(FCtx).Error("Can not create city", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.Status(fiber.StatusCreated).JSON(city)
+}
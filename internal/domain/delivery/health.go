@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"backend/internal/health"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	healthStatusOK   = "ok"
+	healthStatusFail = "fail"
+	readyTimeout     = 2 * time.Second
+)
+
+type healthCheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Detail - например, ошибка последней итерации воркера, который еще готов
+	Detail string `json:"detail,omitempty"`
+}
+
+type healthResponse struct {
+	Status string                       `json:"status"`
+	Checks map[string]healthCheckResult `json:"checks,omitempty"`
+}
+
+// Liveness: процесс жив и обслуживает запросы
+func (s *Server) Healthz(FCtx *fiber.Ctx) error {
+	return FCtx.JSON(healthResponse{Status: healthStatusOK})
+}
+
+// Readiness: все зависимости и фоновые воркеры в порядке
+func (s *Server) Readyz(FCtx *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(FCtx.UserContext(), readyTimeout)
+	defer cancel()
+
+	resp := healthResponse{
+		Status: healthStatusOK,
+		Checks: make(map[string]healthCheckResult, len(s.checks)),
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, checker := range s.checks {
+		wg.Add(1)
+		go func(checker health.Checker) {
+			defer wg.Done()
+			res := healthCheckResult{Status: healthStatusOK}
+			if err := checker.Check(ctx); err != nil {
+				res = healthCheckResult{Status: healthStatusFail, Error: err.Error()}
+			} else if d, ok := checker.(health.Detailer); ok {
+				res.Detail = d.Detail()
+			}
+			mu.Lock()
+			resp.Checks[checker.Name()] = res
+			mu.Unlock()
+		}(checker)
+	}
+	wg.Wait()
+
+	for _, res := range resp.Checks {
+		if res.Status != healthStatusOK {
+			resp.Status = healthStatusFail
+		}
+	}
+	if s.stopping.Load() {
+		resp.Status = healthStatusFail
+	}
+	if resp.Status != healthStatusOK {
+		return FCtx.Status(fiber.StatusServiceUnavailable).JSON(resp)
+	}
+	return FCtx.JSON(resp)
+}
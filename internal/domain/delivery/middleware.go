@@ -0,0 +1,145 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/logging"
+	"backend/internal/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+	maxRequestIDLen = 128
+
+	// localsUserID - id аутентифицированного пользователя в FCtx.Locals
+	localsUserID = "user_id"
+)
+
+func userIDFromCtx(FCtx *fiber.Ctx) (uint64, bool) {
+	uID, ok := FCtx.Locals(localsUserID).(uint64)
+	return uID, ok
+}
+
+var tracer = otel.Tracer("backend/internal/domain/delivery")
+
+func (s *Server) tracingMiddleware(FCtx *fiber.Ctx) error {
+	ctx := otel.GetTextMapPropagator().Extract(
+		FCtx.UserContext(),
+		propagation.HeaderCarrier(http.Header(FCtx.GetReqHeaders())),
+	)
+	ctx, span := tracer.Start(ctx, "HTTP "+FCtx.Method(),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", FCtx.Method()),
+			attribute.String("url.path", FCtx.Path()),
+		),
+	)
+	defer span.End()
+	FCtx.SetUserContext(ctx)
+
+	err := FCtx.Next()
+
+	status := responseStatus(FCtx, err)
+	span.SetName(FCtx.Method() + " " + routePath(FCtx, status))
+	span.SetAttributes(
+		attribute.String("http.route", routePath(FCtx, status)),
+		attribute.Int("http.response.status_code", status),
+	)
+	if status >= fiber.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// requestLogMiddleware принимает X-Request-ID клиента или выдает новый,
+// кладет в контекст логгер запроса и пишет строку access-лога.
+func (s *Server) requestLogMiddleware(FCtx *fiber.Ctx) error {
+	start := time.Now()
+	requestID := FCtx.Get(HeaderRequestID)
+	if requestID == "" || len(requestID) > maxRequestIDLen {
+		requestID = utils.UUIDv4()
+	}
+	FCtx.Set(HeaderRequestID, requestID)
+
+	ctx := FCtx.UserContext()
+	log := s.logger.With(zap.String("request_id", requestID)).With(tracing.LogFields(ctx)...)
+	ctx = logging.WithRequestID(ctx, requestID)
+	FCtx.SetUserContext(logging.WithLogger(ctx, log))
+
+	err := FCtx.Next()
+
+	status := responseStatus(FCtx, err)
+	fields := []zap.Field{
+		zap.String("method", FCtx.Method()),
+		zap.String("path", FCtx.Path()),
+		zap.String("route", routePath(FCtx, status)),
+		zap.Int("status", status),
+		zap.Duration("latency", time.Since(start)),
+		zap.String("ip", FCtx.IP()),
+		zap.Int("bytes_out", len(FCtx.Response().Body())),
+		zap.String("user_agent", FCtx.Get(fiber.HeaderUserAgent)),
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	switch {
+	case status >= fiber.StatusInternalServerError:
+		log.Error("access", fields...)
+	case status >= fiber.StatusBadRequest:
+		log.Warn("access", fields...)
+	default:
+		log.Info("access", fields...)
+	}
+	return err
+}
+
+func (s *Server) requestLogger(FCtx *fiber.Ctx) *zap.Logger {
+	return logging.FromContext(FCtx.UserContext(), s.logger)
+}
+
+func (s *Server) metricsMiddleware(FCtx *fiber.Ctx) error {
+	start := time.Now()
+	err := FCtx.Next()
+
+	status := responseStatus(FCtx, err)
+	labels := []string{FCtx.Method(), routePath(FCtx, status), strconv.Itoa(status)}
+	s.metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+	s.metrics.HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// responseStatus учитывает ошибку обработчика: статус из нее fiber выставит
+// только после прохождения всех middleware
+func responseStatus(FCtx *fiber.Ctx, err error) int {
+	var fErr *fiber.Error
+	if errors.As(err, &fErr) {
+		return fErr.Code
+	} else if err != nil {
+		return fiber.StatusInternalServerError
+	}
+	return FCtx.Response().StatusCode()
+}
+
+// routePath - шаблон маршрута, а не фактический URL, чтобы не раздувать кардинальность
+func routePath(FCtx *fiber.Ctx, status int) string {
+	route := FCtx.Route().Path
+	if status == fiber.StatusNotFound && route == "/" {
+		return "not_found"
+	}
+	return route
+}
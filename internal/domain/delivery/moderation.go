@@ -0,0 +1,97 @@
+package server
+
+import (
+	"backend/common"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type hideAdvertismentRequest struct {
+	AdID   uint64 `json:"ad_id"`
+	Hidden bool   `json:"hidden"`
+}
+
+func (s *Server) HideAdvertisment(FCtx *fiber.Ctx) error {
+	var req hideAdvertismentRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.AdID == 0 {
+		s.requestLogger(FCtx).Error("Invalid hide advertisment body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	moderatorID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.HideAdvertisment(FCtx.UserContext(), moderatorID, req.AdID, req.Hidden); err != nil {
+		s.requestLogger(FCtx).Error("Can not hide advertisment", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
+
+type hideReviewRequest struct {
+	ReviewID uint64 `json:"review_id"`
+	Hidden   bool   `json:"hidden"`
+}
+
+func (s *Server) HideReview(FCtx *fiber.Ctx) error {
+	var req hideReviewRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.ReviewID == 0 {
+		s.requestLogger(FCtx).Error("Invalid hide review body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	moderatorID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.HideReview(FCtx.UserContext(), moderatorID, req.ReviewID, req.Hidden); err != nil {
+		s.requestLogger(FCtx).Error("Can not hide review", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
+
+// GetModerationQueue: ?limit=&offset=
+func (s *Server) GetModerationQueue(FCtx *fiber.Ctx) error {
+	limit, err := parseUintParam(FCtx, "limit")
+	if err != nil {
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	offset, err := parseUintParam(FCtx, "offset")
+	if err != nil {
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	items, err := s.Usecase.GetModerationQueue(FCtx.UserContext(), limit, offset)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get moderation queue", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(items)
+}
+
+type moderationDecisionRequest struct {
+	AdID   uint64 `json:"ad_id"`
+	Reason string `json:"reason"`
+}
+
+func (s *Server) ApproveAdvertisment(FCtx *fiber.Ctx) error {
+	var req moderationDecisionRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.AdID == 0 {
+		s.requestLogger(FCtx).Error("Invalid approve advertisment body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	moderatorID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.ApproveAdvertisment(FCtx.UserContext(), moderatorID, req.AdID); err != nil {
+		s.requestLogger(FCtx).Error("Can not approve advertisment", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
+
+func (s *Server) RejectAdvertisment(FCtx *fiber.Ctx) error {
+	var req moderationDecisionRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.AdID == 0 {
+		s.requestLogger(FCtx).Error("Invalid reject advertisment body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	moderatorID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.RejectAdvertisment(FCtx.UserContext(), moderatorID, req.AdID, req.Reason); err != nil {
+		s.requestLogger(FCtx).Error("Can not reject advertisment", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
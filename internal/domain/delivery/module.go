@@ -1,6 +1,8 @@
 package server
 
 import (
+	"backend/internal/health"
+
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -8,7 +10,10 @@ import (
 func New() fx.Option {
 	return fx.Module("NewServer",
 		fx.Provide(
-			NewServer,
+			fx.Annotate(
+				NewServer,
+				fx.ParamTags(``, ``, ``, ``, ``, health.Group),
+			),
 		),
 		fx.Invoke(
 			func(lc fx.Lifecycle, s *Server) {
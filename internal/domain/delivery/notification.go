@@ -0,0 +1,92 @@
+package server
+
+import (
+	"backend/common"
+	"backend/internal/domain/entities"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// GetNotifications: ?unread=true&limit=&offset=
+func (s *Server) GetNotifications(FCtx *fiber.Ctx) error {
+	limit, err := parseUintParam(FCtx, "limit")
+	if err != nil {
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	offset, err := parseUintParam(FCtx, "offset")
+	if err != nil {
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	notifications, err := s.Usecase.GetNotifications(FCtx.UserContext(), uID, FCtx.QueryBool("unread"), limit, offset)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get notifications", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(notifications)
+}
+
+func (s *Server) CountUnreadNotifications(FCtx *fiber.Ctx) error {
+	uID, _ := userIDFromCtx(FCtx)
+	count, err := s.Usecase.CountUnreadNotifications(FCtx.UserContext(), uID)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not count unread notifications", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(fiber.Map{"count": count})
+}
+
+type readNotificationsRequest struct {
+	// NotificationIDs - пустой список отмечает прочитанными все уведомления
+	NotificationIDs []uint64 `json:"notification_ids"`
+}
+
+func (s *Server) MarkNotificationsRead(FCtx *fiber.Ctx) error {
+	var req readNotificationsRequest
+	if err := FCtx.BodyParser(&req); err != nil {
+		s.requestLogger(FCtx).Error("Invalid read notifications body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.MarkNotificationsRead(FCtx.UserContext(), uID, req.NotificationIDs); err != nil {
+		s.requestLogger(FCtx).Error("Can not mark notifications read", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
+
+func (s *Server) GetNotificationPreferences(FCtx *fiber.Ctx) error {
+	uID, _ := userIDFromCtx(FCtx)
+	prefs, err := s.Usecase.GetNotificationPreferences(FCtx.UserContext(), uID)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get notification preferences", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(prefs)
+}
+
+type notificationPreferenceRequest struct {
+	Kind    string `json:"kind"`
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (s *Server) SetNotificationPreference(FCtx *fiber.Ctx) error {
+	var req notificationPreferenceRequest
+	if err := FCtx.BodyParser(&req); err != nil {
+		s.requestLogger(FCtx).Error("Invalid notification preference body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	pref := &entities.NotificationPreference{
+		Kind:    req.Kind,
+		Channel: req.Channel,
+		Enabled: req.Enabled,
+	}
+	if err := s.Usecase.SetNotificationPreference(FCtx.UserContext(), uID, pref); err != nil {
+		s.requestLogger(FCtx).Error("Can not set notification preference", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
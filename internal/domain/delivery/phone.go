@@ -0,0 +1,44 @@
+package server
+
+import (
+	"backend/common"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type phoneCodeRequest struct {
+	Phone string `json:"phone"`
+}
+
+func (s *Server) RequestPhoneCode(FCtx *fiber.Ctx) error {
+	var req phoneCodeRequest
+	if err := FCtx.BodyParser(&req); err != nil {
+		s.requestLogger(FCtx).Error("Invalid phone code body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.RequestPhoneCode(FCtx.UserContext(), uID, req.Phone); err != nil {
+		s.requestLogger(FCtx).Error("Can not send phone code", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusAccepted)
+}
+
+type confirmPhoneRequest struct {
+	Code string `json:"code"`
+}
+
+func (s *Server) ConfirmPhone(FCtx *fiber.Ctx) error {
+	var req confirmPhoneRequest
+	if err := FCtx.BodyParser(&req); err != nil {
+		s.requestLogger(FCtx).Error("Invalid confirm phone body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.ConfirmPhone(FCtx.UserContext(), uID, req.Code); err != nil {
+		s.requestLogger(FCtx).Error("Can not confirm phone", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
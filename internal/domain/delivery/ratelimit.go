@@ -0,0 +1,62 @@
+package server
+
+import (
+	"math"
+	"strconv"
+
+	"backend/common"
+	"backend/internal/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// rateLimit ограничивает группу маршрутов по лимиту из RateLimit.Groups.
+// Ключ - пользователь, если запрос аутентифицирован, иначе IP клиента.
+func (s *Server) rateLimit(group string) fiber.Handler {
+	rule, ok := s.cfg.RateLimit.Groups[group]
+	if !s.cfg.RateLimit.Enabled || !ok {
+		return func(FCtx *fiber.Ctx) error {
+			return FCtx.Next()
+		}
+	}
+	limit := ratelimit.Limit{Rate: rule.Rate, Burst: rule.Burst}
+	return func(FCtx *fiber.Ctx) error {
+		key := group + ":ip:" + FCtx.IP()
+		if uID, ok := userIDFromCtx(FCtx); ok {
+			key = group + ":user:" + strconv.FormatUint(uID, 10)
+		}
+		res, err := s.limiter.Take(FCtx.UserContext(), key, limit)
+		if err != nil {
+			// Недоступное хранилище лимитов не должно ронять API
+			s.requestLogger(FCtx).Error("rate limit store failed", zap.String("group", group), zap.Error(err))
+			return FCtx.Next()
+		}
+		FCtx.Set("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+		FCtx.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		if !res.Allowed {
+			FCtx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+			return FCtx.Status(fiber.StatusTooManyRequests).JSON(
+				fiber.Map{
+					"message": fiber.Map{
+						"status": common.StatusTooManyRequests,
+						"text":   common.ErrTooManyRequests,
+					},
+				},
+			)
+		}
+		return FCtx.Next()
+	}
+}
+
+// rateLimitByMethod делит смешанную группу маршрутов: GET (списки, опрос
+// счетчиков) идут по лимиту read, изменения - по write
+func (s *Server) rateLimitByMethod() fiber.Handler {
+	read, write := s.rateLimit("read"), s.rateLimit("write")
+	return func(FCtx *fiber.Ctx) error {
+		if isReadMethod(FCtx.Method()) {
+			return read(FCtx)
+		}
+		return write(FCtx)
+	}
+}
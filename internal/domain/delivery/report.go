@@ -0,0 +1,75 @@
+package server
+
+import (
+	"backend/common"
+	"backend/internal/domain/entities"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type reportRequest struct {
+	TargetType string `json:"target_type"`
+	TargetID   uint64 `json:"target_id"`
+	Reason     string `json:"reason"`
+	Comment    string `json:"comment"`
+}
+
+func (s *Server) CreateReport(FCtx *fiber.Ctx) error {
+	var req reportRequest
+	if err := FCtx.BodyParser(&req); err != nil {
+		s.requestLogger(FCtx).Error("Invalid report body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	report := &entities.Report{
+		ReporterID: uID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+		Comment:    req.Comment,
+	}
+	if err := s.Usecase.CreateReport(FCtx.UserContext(), report); err != nil {
+		s.requestLogger(FCtx).Error("Can not create report", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.Status(fiber.StatusCreated).JSON(report)
+}
+
+// GetReports: ?status=open|resolved|dismissed&limit=&offset=
+func (s *Server) GetReports(FCtx *fiber.Ctx) error {
+	limit, err := parseUintParam(FCtx, "limit")
+	if err != nil {
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	offset, err := parseUintParam(FCtx, "offset")
+	if err != nil {
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	reports, err := s.Usecase.GetReports(FCtx.UserContext(), FCtx.Query("status"), limit, offset)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get reports", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(reports)
+}
+
+type triageReportRequest struct {
+	ReportID   uint64 `json:"report_id"`
+	Status     string `json:"status"`
+	HideTarget bool   `json:"hide_target"`
+}
+
+func (s *Server) TriageReport(FCtx *fiber.Ctx) error {
+	var req triageReportRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.ReportID == 0 {
+		s.requestLogger(FCtx).Error("Invalid triage report body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	moderatorID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.TriageReport(FCtx.UserContext(), moderatorID, req.ReportID, req.Status, req.HideTarget); err != nil {
+		s.requestLogger(FCtx).Error("Can not triage report", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
@@ -1,16 +1,107 @@
 package server
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"backend/internal/domain/entities"
+	"backend/internal/rbac"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
 
 func (s *Server) initRouter() {
+	s.app.Use(s.tracingMiddleware)
+	s.app.Use(s.requestLogMiddleware)
+	s.app.Use(s.metricsMiddleware)
+	s.app.Use(s.authenticate)
+	if len(s.cfg.Postgres.Replicas) > 0 {
+		s.app.Use(s.readYourWrites)
+	}
 	s.app.Get("/", func(c *fiber.Ctx) error {
 		err := c.SendString("And the API is UP!")
 		return err
 	})
-	s.app.Get("/get/advertisment/all_info", s.GetAdvertismentAllInfo)
-	s.app.Get("/get/profile/all_info", s.GetProfileUserAllInfo)
-	s.app.Get("/get/profile/statistics", s.GetProfileUserStatistics)
-	s.app.Get("/get/profile/my_ads", s.GetProfileMyAdvertisments)
-	s.app.Get("/get/profile/reviews", s.GetProfileReviews)
-	
-}
\ No newline at end of file
+	s.app.Get("/healthz", s.Healthz)
+	s.app.Get("/readyz", s.Readyz)
+	s.app.Get("/metrics", adaptor.HTTPHandler(s.metrics.Handler()))
+
+	get := s.app.Group("/get", s.rateLimit("read"), s.bodyETag)
+	get.Get("/advertisment/all_info", s.GetAdvertismentAllInfo)
+	get.Get("/profile/all_info", s.GetProfileUserAllInfo)
+	get.Get("/profile/statistics", s.GetProfileUserStatistics)
+	get.Get("/profile/my_ads", s.GetProfileMyAdvertisments)
+	get.Get("/profile/reviews", s.GetProfileReviews)
+	get.Get("/categories", s.GetCategories)
+	get.Get("/promotion_types", s.GetPromotionTypes)
+	get.Get("/categories/tree", s.GetCategoryTree)
+	get.Get("/category/attributes", s.GetCategoryAttributes)
+	get.Get("/advertisments/search", s.SearchAdvertisments)
+	get.Get("/cities", s.GetCities)
+
+	advertisment := s.app.Group("/advertisment", s.requireAuth, s.rateLimitByMethod())
+	advertisment.Post("/create", s.CreateAdvertisment)
+	advertisment.Post("/update", s.UpdateAdvertisment)
+	advertisment.Post("/publish", s.PublishAdvertisment)
+	advertisment.Post("/promote", s.PromoteAdvertisment)
+	advertisment.Post("/archive", s.changeAdvertismentStatus(entities.AdStatusArchived))
+	advertisment.Post("/delete", s.changeAdvertismentStatus(entities.AdStatusDeleted))
+	advertisment.Post("/restore", s.changeAdvertismentStatus(entities.AdStatusActive))
+
+	phone := s.app.Group("/phone", s.requireAuth, s.rateLimitByMethod())
+	phone.Post("/request_code", s.RequestPhoneCode)
+	phone.Post("/confirm", s.ConfirmPhone)
+
+	verification := s.app.Group("/verification", s.requireAuth, s.rateLimitByMethod())
+	verification.Post("/request", s.RequestVerification)
+	verification.Get("/my", s.GetMyVerification)
+
+	savedSearch := s.app.Group("/saved_search", s.requireAuth, s.rateLimitByMethod())
+	savedSearch.Post("/create", s.CreateSavedSearch)
+	savedSearch.Get("/my", s.GetMySavedSearches)
+	savedSearch.Post("/delete", s.DeleteSavedSearch)
+
+	notification := s.app.Group("/notification", s.requireAuth, s.rateLimitByMethod())
+	notification.Get("/list", s.GetNotifications)
+	notification.Get("/unread_count", s.CountUnreadNotifications)
+	notification.Post("/read", s.MarkNotificationsRead)
+	notification.Get("/preferences", s.GetNotificationPreferences)
+	notification.Post("/preferences", s.SetNotificationPreference)
+
+	webhook := s.app.Group("/webhook", s.requireAuth, s.rateLimitByMethod())
+	webhook.Post("/create", s.CreateWebhook)
+	webhook.Get("/my", s.GetMyWebhooks)
+	webhook.Post("/delete", s.DeleteWebhook)
+	webhook.Get("/deliveries", s.GetWebhookDeliveries)
+	webhook.Post("/delivery/replay", s.ReplayWebhookDelivery)
+
+	report := s.app.Group("/report", s.requireAuth, s.rateLimitByMethod())
+	report.Post("/create", s.CreateReport)
+
+	moderation := s.app.Group("/moderation", s.requireAuth, s.rateLimitByMethod())
+	moderation.Post("/advertisment/hide", s.requirePermission(rbac.PermHideAdvertisment), s.HideAdvertisment)
+	moderation.Post("/review/hide", s.requirePermission(rbac.PermHideReview), s.HideReview)
+	moderateAdvertisments := s.requirePermission(rbac.PermModerateAdvertisment)
+	moderation.Get("/advertisments/queue", moderateAdvertisments, s.GetModerationQueue)
+	moderation.Post("/advertisment/approve", moderateAdvertisments, s.ApproveAdvertisment)
+	moderation.Post("/advertisment/reject", moderateAdvertisments, s.RejectAdvertisment)
+	triageReports := s.requirePermission(rbac.PermTriageReports)
+	moderation.Get("/reports", triageReports, s.GetReports)
+	moderation.Post("/report/triage", triageReports, s.TriageReport)
+	reviewVerification := s.requirePermission(rbac.PermReviewVerification)
+	moderation.Get("/verification/queue", reviewVerification, s.GetVerificationQueue)
+	moderation.Post("/verification/review", reviewVerification, s.ReviewVerification)
+
+	admin := s.app.Group("/admin", s.requireAuth, s.rateLimitByMethod())
+	manageCategories := s.requirePermission(rbac.PermManageCategories)
+	admin.Get("/categories", manageCategories, s.AdminGetCategories)
+	admin.Post("/category/create", manageCategories, s.AdminCreateCategory)
+	admin.Post("/category/update", manageCategories, s.AdminUpdateCategory)
+	admin.Post("/category/delete", manageCategories, s.AdminDeleteCategory)
+	admin.Post("/category/attribute/create", manageCategories, s.AdminCreateCategoryAttribute)
+	admin.Post("/category/attribute/delete", manageCategories, s.AdminDeleteCategoryAttribute)
+	managePromotionTypes := s.requirePermission(rbac.PermManagePromotionTypes)
+	admin.Get("/promotion_types", managePromotionTypes, s.AdminGetPromotionTypes)
+	admin.Post("/promotion_type/create", managePromotionTypes, s.AdminCreatePromotionType)
+	admin.Post("/promotion_type/update", managePromotionTypes, s.AdminUpdatePromotionType)
+	admin.Post("/promotion_type/delete", managePromotionTypes, s.AdminDeletePromotionType)
+	admin.Post("/city/create", s.requirePermission(rbac.PermManageCities), s.AdminCreateCity)
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"backend/common"
+	"backend/internal/domain/entities"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type savedSearchRequest struct {
+	Name string `json:"name"`
+	// Notify: instant или daily
+	Notify string `json:"notify"`
+}
+
+// CreateSavedSearch сохраняет поиск: фильтр передается теми же query-параметрами,
+// что и в /get/advertisments/search, название и режим уведомлений - в теле
+func (s *Server) CreateSavedSearch(FCtx *fiber.Ctx) error {
+	var req savedSearchRequest
+	if err := FCtx.BodyParser(&req); err != nil {
+		s.requestLogger(FCtx).Error("Invalid saved search body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	filter, err := parseAdvertismentFilter(FCtx)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Invalid saved search parameters", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	search := &entities.SavedSearch{
+		Name:   req.Name,
+		Notify: req.Notify,
+		Filter: *filter,
+	}
+	if err = s.Usecase.CreateSavedSearch(FCtx.UserContext(), uID, search); err != nil {
+		s.requestLogger(FCtx).Error("Can not create saved search", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.Status(fiber.StatusCreated).JSON(search)
+}
+
+func (s *Server) GetMySavedSearches(FCtx *fiber.Ctx) error {
+	uID, _ := userIDFromCtx(FCtx)
+	searches, err := s.Usecase.GetSavedSearches(FCtx.UserContext(), uID)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get saved searches", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(searches)
+}
+
+type deleteSavedSearchRequest struct {
+	SavedSearchID uint64 `json:"saved_search_id"`
+}
+
+func (s *Server) DeleteSavedSearch(FCtx *fiber.Ctx) error {
+	var req deleteSavedSearchRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.SavedSearchID == 0 {
+		s.requestLogger(FCtx).Error("Invalid delete saved search body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.DeleteSavedSearch(FCtx.UserContext(), uID, req.SavedSearchID); err != nil {
+		s.requestLogger(FCtx).Error("Can not delete saved search", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
@@ -4,29 +4,46 @@ import (
 	"backend/config"
 	"backend/internal/domain/entities"
 	"backend/internal/domain/usecase"
+	"backend/internal/health"
+	"backend/internal/metrics"
+	"backend/internal/ratelimit"
 	"context"
 	// "database/sql"
 	// "log"
 	"strconv"
+	"sync/atomic"
+	"time"
 
+	"backend/common"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
-	"backend/common"
 )
 
 type Server struct {
-	logger  *zap.Logger
-	cfg     *config.ConfigModel
-	app 	*fiber.App
-	Usecase *usecase.Usecase
+	logger   *zap.Logger
+	cfg      *config.ConfigModel
+	app      *fiber.App
+	Usecase  *usecase.Usecase
+	metrics  *metrics.Metrics
+	limiter  ratelimit.Store
+	checks   []health.Checker
+	stopping atomic.Bool
+	// writes - кто из пользователей недавно что-то менял, см. readYourWrites
+	writes *recentWrites
 }
 
-func NewServer(logger *zap.Logger, cfg *config.ConfigModel, uc *usecase.Usecase) (*Server, error) {
+func NewServer(logger *zap.Logger, cfg *config.ConfigModel, uc *usecase.Usecase, m *metrics.Metrics, limiter ratelimit.Store, checks []health.Checker) (*Server, error) {
 	return &Server{
-		logger:  logger,
-		cfg:     cfg,
-		app:	 fiber.New(),
+		logger: logger,
+		cfg:    cfg,
+		app: fiber.New(fiber.Config{
+			ProxyHeader: cfg.Server.ProxyHeader,
+		}),
 		Usecase: uc,
+		metrics: m,
+		limiter: limiter,
+		checks:  checks,
+		writes:  newRecentWrites(cfg.Postgres.ReadYourWritesWindow),
 	}, nil
 }
 
@@ -34,7 +51,7 @@ func (s *Server) OnStart(_ context.Context) error {
 	go func() {
 		s.logger.Debug("fiber app started")
 		s.initRouter()
-		if err := s.app.Listen(s.cfg.Server.Host+":"+s.cfg.Server.Port); err != nil {
+		if err := s.app.Listen(s.cfg.Server.Host + ":" + s.cfg.Server.Port); err != nil {
 			s.logger.Error("failed to serve: " + err.Error())
 		}
 	}()
@@ -43,6 +60,7 @@ func (s *Server) OnStart(_ context.Context) error {
 
 func (s *Server) OnStop(_ context.Context) error {
 	s.logger.Debug("stop fiber app")
+	s.stopping.Store(true)
 	s.app.Shutdown()
 	return nil
 }
@@ -51,32 +69,41 @@ func (s *Server) GetAdvertismentAllInfo(FCtx *fiber.Ctx) error {
 	var adID int
 	var err error
 	adIDParam := FCtx.Query("ad_id")
-    // Преобразуем ad_id из строки в число (если требуется)
-    if adID, err = strconv.Atoi(adIDParam); err != nil {
-		s.logger.Error("Invalid ad_id parameter", zap.Error(err))
+	// Преобразуем ad_id из строки в число (если требуется)
+	if adID, err = strconv.Atoi(adIDParam); err != nil {
+		s.requestLogger(FCtx).Error("Invalid ad_id parameter", zap.Error(err))
 		return FCtx.Status(fiber.StatusBadRequest).JSON(
 			fiber.Map{
 				"message": fiber.Map{
 					"status": common.StatusInvalidParams,
-					"text": common.ErrInvalidParams,
+					"text":   common.ErrInvalidParams,
 				},
 			},
 		)
-    }
+	}
+	viewerID, _ := userIDFromCtx(FCtx)
+	if s.notModified(FCtx, func(ctx context.Context) (time.Time, error) {
+		return s.Usecase.AdvertismentUpdatedAt(ctx, viewerID, uint64(adID))
+	}) {
+		return nil
+	}
 	advertisment := &entities.Advertisment{
 		ID: uint64(adID),
 	}
-	if err = s.Usecase.GetAdvertismentAllInfo(FCtx.Context(), advertisment); err != nil {
-		s.logger.Error("Can not get all advertisment info", zap.Error(err))
+	if err = s.Usecase.GetAdvertismentAllInfo(FCtx.UserContext(), viewerID, advertisment); err != nil {
+		s.requestLogger(FCtx).Error("Can not get all advertisment info", zap.Error(err))
 		return FCtx.Status(fiber.StatusBadRequest).JSON(
 			fiber.Map{
 				"message": fiber.Map{
 					"status": common.StatusGetInfo,
-					"text": common.ErrGetInfo,
+					"text":   common.ErrGetInfo,
 				},
-        })
+			})
+	}
+	if version, ok := advertismentVersion(advertisment); ok {
+		s.setValidators(FCtx, version)
 	}
-    return FCtx.JSON(advertisment)
+	return FCtx.JSON(advertisment)
 }
 
 // func convertedByCreateUser(FCtx *fiber.Ctx, user *entities.User) error {
@@ -149,56 +176,64 @@ func (s *Server) GetProfileUserAllInfo(FCtx *fiber.Ctx) error {
 	var uID int
 	var err error
 	uIDParam := FCtx.Query("user_id")
-    if uID, err = strconv.Atoi(uIDParam); err != nil {
-		s.logger.Error("Invalid user_id parameter", zap.Error(err))
+	if uID, err = strconv.Atoi(uIDParam); err != nil {
+		s.requestLogger(FCtx).Error("Invalid user_id parameter", zap.Error(err))
 		return FCtx.Status(fiber.StatusBadRequest).JSON(
 			fiber.Map{
 				"message": fiber.Map{
 					"status": common.StatusInvalidParams,
-					"text": common.ErrInvalidParams,
+					"text":   common.ErrInvalidParams,
 				},
 			},
 		)
-    }
+	}
+	if s.notModified(FCtx, func(ctx context.Context) (time.Time, error) {
+		return s.Usecase.ProfileUpdatedAt(ctx, uint64(uID))
+	}) {
+		return nil
+	}
 	user := &entities.User{
 		ID: uint64(uID),
 	}
-	if err = s.Usecase.GetProfileUserAllInfo(FCtx.Context(), user); err != nil {
-		s.logger.Error("Can not get all user info", zap.Error(err))
+	if err = s.Usecase.GetProfileUserAllInfo(FCtx.UserContext(), user); err != nil {
+		s.requestLogger(FCtx).Error("Can not get all user info", zap.Error(err))
 		return FCtx.Status(fiber.StatusBadRequest).JSON(
 			fiber.Map{
 				"message": fiber.Map{
 					"status": common.StatusGetInfo,
-					"text": common.ErrGetInfo,
+					"text":   common.ErrGetInfo,
 				},
-        })
+			})
+	}
+	if user.UpdatedAt != nil {
+		s.setValidators(FCtx, *user.UpdatedAt)
 	}
-    return FCtx.JSON(user)
+	return FCtx.JSON(user)
 }
 
 func (s *Server) GetProfileUserStatistics(FCtx *fiber.Ctx) error {
 	var uID int
 	var err error
 	uIDParam := FCtx.Query("user_id")
-    if uID, err = strconv.Atoi(uIDParam); err != nil {
-		s.logger.Error("Invalid user_id parameter", zap.Error(err))
+	if uID, err = strconv.Atoi(uIDParam); err != nil {
+		s.requestLogger(FCtx).Error("Invalid user_id parameter", zap.Error(err))
 		return FCtx.Status(fiber.StatusBadRequest).JSON(
 			fiber.Map{
 				"message": fiber.Map{
 					"status": common.StatusInvalidParams,
-					"text": common.ErrInvalidParams,
+					"text":   common.ErrInvalidParams,
 				},
 			},
 		)
-    }
+	}
 	var statisticAdsInfo *[]*entities.ProfileStatistic
-	if statisticAdsInfo, err = s.Usecase.GetProfileUserStatistics(FCtx.Context(), uint64(uID)); err != nil {
-		s.logger.Error("Can not get statistics info", zap.Error(err))
+	if statisticAdsInfo, err = s.Usecase.GetProfileUserStatistics(FCtx.UserContext(), uint64(uID)); err != nil {
+		s.requestLogger(FCtx).Error("Can not get statistics info", zap.Error(err))
 		return FCtx.Status(fiber.StatusBadRequest).JSON(
 			fiber.Map{
 				"message": fiber.Map{
 					"status": common.StatusGetInfo,
-					"text": common.ErrGetInfo,
+					"text":   common.ErrGetInfo,
 				},
 			},
 		)
@@ -210,25 +245,26 @@ func (s *Server) GetProfileMyAdvertisments(FCtx *fiber.Ctx) error {
 	var uID int
 	var err error
 	uIDParam := FCtx.Query("user_id")
-    if uID, err = strconv.Atoi(uIDParam); err != nil {
-		s.logger.Error("Invalid user_id parameter", zap.Error(err))
+	if uID, err = strconv.Atoi(uIDParam); err != nil {
+		s.requestLogger(FCtx).Error("Invalid user_id parameter", zap.Error(err))
 		return FCtx.Status(fiber.StatusBadRequest).JSON(
 			fiber.Map{
 				"message": fiber.Map{
 					"status": common.StatusInvalidParams,
-					"text": common.ErrInvalidParams,
+					"text":   common.ErrInvalidParams,
 				},
 			},
 		)
-    }
+	}
 	var advertisements *[]*entities.MyAdvertisement
-	if advertisements, err = s.Usecase.GetProfileMyAdvertisments(FCtx.Context(), uint64(uID)); err != nil {
-		s.logger.Error("Can not get info for Profile My Ads", zap.Error(err))
+	viewerID, _ := userIDFromCtx(FCtx)
+	if advertisements, err = s.Usecase.GetProfileMyAdvertisments(FCtx.UserContext(), viewerID, uint64(uID)); err != nil {
+		s.requestLogger(FCtx).Error("Can not get info for Profile My Ads", zap.Error(err))
 		return FCtx.Status(fiber.StatusBadRequest).JSON(
 			fiber.Map{
 				"message": fiber.Map{
 					"status": common.StatusGetInfo,
-					"text": common.ErrGetInfo,
+					"text":   common.ErrGetInfo,
 				},
 			},
 		)
@@ -240,28 +276,28 @@ func (s *Server) GetProfileReviews(FCtx *fiber.Ctx) error {
 	var uID int
 	var err error
 	uIDParam := FCtx.Query("user_id")
-    if uID, err = strconv.Atoi(uIDParam); err != nil {
-		s.logger.Error("Invalid user_id parameter", zap.Error(err))
+	if uID, err = strconv.Atoi(uIDParam); err != nil {
+		s.requestLogger(FCtx).Error("Invalid user_id parameter", zap.Error(err))
 		return FCtx.Status(fiber.StatusBadRequest).JSON(
 			fiber.Map{
 				"message": fiber.Map{
 					"status": common.StatusInvalidParams,
-					"text": common.ErrInvalidParams,
+					"text":   common.ErrInvalidParams,
 				},
 			},
 		)
-    }
+	}
 	var reviews *[]*entities.ProfileReview
-	if reviews, err = s.Usecase.GetProfileReviews(FCtx.Context(), uint64(uID)); err != nil {
-		s.logger.Error("Can not get info for Profile Reviews", zap.Error(err))
+	if reviews, err = s.Usecase.GetProfileReviews(FCtx.UserContext(), uint64(uID)); err != nil {
+		s.requestLogger(FCtx).Error("Can not get info for Profile Reviews", zap.Error(err))
 		return FCtx.Status(fiber.StatusBadRequest).JSON(
 			fiber.Map{
 				"message": fiber.Map{
 					"status": common.StatusGetInfo,
-					"text": common.ErrGetInfo,
+					"text":   common.ErrGetInfo,
 				},
 			},
 		)
 	}
 	return FCtx.JSON(reviews)
-}
\ No newline at end of file
+}
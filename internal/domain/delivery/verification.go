@@ -0,0 +1,83 @@
+package server
+
+import (
+	"backend/common"
+	"backend/internal/domain/entities"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type verificationRequest struct {
+	// Method: document или phone
+	Method       string `json:"method"`
+	DocumentPath string `json:"document_path"`
+}
+
+func (s *Server) RequestVerification(FCtx *fiber.Ctx) error {
+	var req verificationRequest
+	if err := FCtx.BodyParser(&req); err != nil {
+		s.requestLogger(FCtx).Error("Invalid verification body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	vr := &entities.VerificationRequest{
+		UserID:       uID,
+		Method:       req.Method,
+		DocumentPath: req.DocumentPath,
+	}
+	if err := s.Usecase.RequestVerification(FCtx.UserContext(), vr); err != nil {
+		s.requestLogger(FCtx).Error("Can not request verification", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.Status(fiber.StatusCreated).JSON(vr)
+}
+
+func (s *Server) GetMyVerification(FCtx *fiber.Ctx) error {
+	uID, _ := userIDFromCtx(FCtx)
+	vr, err := s.Usecase.GetMyVerification(FCtx.UserContext(), uID)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get verification request", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(vr)
+}
+
+// GetVerificationQueue: ?limit=&offset=
+func (s *Server) GetVerificationQueue(FCtx *fiber.Ctx) error {
+	limit, err := parseUintParam(FCtx, "limit")
+	if err != nil {
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	offset, err := parseUintParam(FCtx, "offset")
+	if err != nil {
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	reqs, err := s.Usecase.GetVerificationQueue(FCtx.UserContext(), limit, offset)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get verification queue", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(reqs)
+}
+
+type reviewVerificationRequest struct {
+	RequestID uint64 `json:"request_id"`
+	// Status: verified или rejected
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+func (s *Server) ReviewVerification(FCtx *fiber.Ctx) error {
+	var req reviewVerificationRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.RequestID == 0 {
+		s.requestLogger(FCtx).Error("Invalid review verification body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	moderatorID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.ReviewVerification(FCtx.UserContext(), moderatorID, req.RequestID, req.Status, req.Reason); err != nil {
+		s.requestLogger(FCtx).Error("Can not review verification", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
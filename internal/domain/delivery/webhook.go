@@ -0,0 +1,110 @@
+package server
+
+import (
+	"backend/common"
+	"backend/internal/domain/entities"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type webhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	// Secret необязателен: без него ключ подписи генерируется сервером
+	Secret string `json:"secret"`
+}
+
+type webhookCreatedResponse struct {
+	*entities.Webhook
+	// Secret отдается только в ответе на создание
+	Secret string `json:"Secret"`
+}
+
+func (s *Server) CreateWebhook(FCtx *fiber.Ctx) error {
+	var req webhookRequest
+	if err := FCtx.BodyParser(&req); err != nil {
+		s.requestLogger(FCtx).Error("Invalid webhook body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	wh := &entities.Webhook{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+	}
+	if err := s.Usecase.CreateWebhook(FCtx.UserContext(), uID, wh); err != nil {
+		s.requestLogger(FCtx).Error("Can not create webhook", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.Status(fiber.StatusCreated).JSON(webhookCreatedResponse{Webhook: wh, Secret: wh.Secret})
+}
+
+func (s *Server) GetMyWebhooks(FCtx *fiber.Ctx) error {
+	uID, _ := userIDFromCtx(FCtx)
+	webhooks, err := s.Usecase.GetWebhooks(FCtx.UserContext(), uID)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get webhooks", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(webhooks)
+}
+
+type deleteWebhookRequest struct {
+	WebhookID uint64 `json:"webhook_id"`
+}
+
+func (s *Server) DeleteWebhook(FCtx *fiber.Ctx) error {
+	var req deleteWebhookRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.WebhookID == 0 {
+		s.requestLogger(FCtx).Error("Invalid delete webhook body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.DeleteWebhook(FCtx.UserContext(), uID, req.WebhookID); err != nil {
+		s.requestLogger(FCtx).Error("Can not delete webhook", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusNoContent)
+}
+
+// GetWebhookDeliveries: ?webhook_id=&status=pending|delivered|failed&limit=&offset=
+func (s *Server) GetWebhookDeliveries(FCtx *fiber.Ctx) error {
+	webhookID, err := parseUintParam(FCtx, "webhook_id")
+	if err != nil {
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	limit, err := parseUintParam(FCtx, "limit")
+	if err != nil {
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	offset, err := parseUintParam(FCtx, "offset")
+	if err != nil {
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	deliveries, err := s.Usecase.GetWebhookDeliveries(FCtx.UserContext(), uID, webhookID, FCtx.Query("status"), limit, offset)
+	if err != nil {
+		s.requestLogger(FCtx).Error("Can not get webhook deliveries", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusGetInfo, common.ErrGetInfo)
+	}
+	return FCtx.JSON(deliveries)
+}
+
+type replayWebhookDeliveryRequest struct {
+	DeliveryID uint64 `json:"delivery_id"`
+}
+
+func (s *Server) ReplayWebhookDelivery(FCtx *fiber.Ctx) error {
+	var req replayWebhookDeliveryRequest
+	if err := FCtx.BodyParser(&req); err != nil || req.DeliveryID == 0 {
+		s.requestLogger(FCtx).Error("Invalid replay webhook delivery body", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusInvalidParams, common.ErrInvalidParams)
+	}
+	uID, _ := userIDFromCtx(FCtx)
+	if err := s.Usecase.ReplayWebhookDelivery(FCtx.UserContext(), uID, req.DeliveryID); err != nil {
+		s.requestLogger(FCtx).Error("Can not replay webhook delivery", zap.Error(err))
+		return s.writeError(FCtx, err, common.StatusUpdateInfo, common.ErrUpdateInfo)
+	}
+	return FCtx.SendStatus(fiber.StatusAccepted)
+}
@@ -5,66 +5,173 @@ import (
 	"time"
 )
 
-
 type TypePromotion struct {
-	ID uint64
-	Name string
-	Price float32
+	ID       uint64
+	Name     string
+	Price    float32
 	TimeLive time.Duration
+	IsActive bool
+	// VerifiedOnly - доступно только верифицированным продавцам
+	VerifiedOnly bool
+	// UpdatedAt заполняется в карточке объявления и входит в ее версию
+	UpdatedAt *time.Time `json:",omitempty"`
 }
 
 type AdvertismentCategory struct {
-	ID uint64
-	Name string
+	ID       uint64
+	Name     string
+	IsActive bool
+	ParentID uint64
+	Children []*AdvertismentCategory `json:",omitempty"`
+	// UpdatedAt заполняется в карточке объявления и входит в ее версию
+	UpdatedAt *time.Time `json:",omitempty"`
+}
+
+const (
+	AttributeTypeString = "string"
+	AttributeTypeInt    = "int"
+	AttributeTypeFloat  = "float"
+	AttributeTypeBool   = "bool"
+	AttributeTypeEnum   = "enum"
+)
+
+type CategoryAttribute struct {
+	ID         uint64
+	CategoryID uint64
+	Code       string
+	Name       string
+	ValueType  string
+	IsRequired bool
+	Options    []string
+}
+
+type AdvertismentAttribute struct {
+	AttributeID uint64
+	Code        string
+	Name        string
+	Value       string
+	NumValue    *float64 `json:"-"`
+}
+
+// Значения advertisements.status
+const (
+	AdStatusDraft     = "draft"
+	AdStatusScheduled = "scheduled"
+	AdStatusActive    = "active"
+	AdStatusArchived  = "archived"
+	AdStatusDeleted   = "deleted"
+)
+
+const (
+	ModerationPending  = "pending"
+	ModerationApproved = "approved"
+	ModerationRejected = "rejected"
+)
+
+const (
+	FlagBannedWords     = "banned_words"
+	FlagSuspiciousPrice = "suspicious_price"
+	FlagDuplicatePhoto  = "duplicate_photo"
+)
+
+type AdvertismentModeration struct {
+	Status      string
+	Reason      string
+	Flags       []string
+	ModeratorID uint64
+	ModeratedAt *time.Time
+	// Hidden - скрыто модератором или по жалобам; видно только владельцу и модераторам
+	Hidden bool
+}
+
+type ModerationQueueItem struct {
+	AdID            uint64
+	UserID          uint64
+	CategoryID      uint64
+	AdName          string
+	AdDescription   string
+	AdPrice         float64
+	AdDatePlacement *time.Time
+	Flags           []string
+	Photos          []AdPhoto
 }
 
 type Advertisment struct {
-	ID	uint64
-	User User
-	Name string
-	Description string
-	Price float64
-	DatePlacement *time.Time
-	Location string
-	TypePromotion TypePromotion
-	ViewsCount uint32
-	DateExpirePromotion *time.Time
-	AdvertismentCategory AdvertismentCategory 
-	Reviews []Review
-	Photos []AdPhoto
+	ID                   uint64
+	User                 User
+	Name                 string
+	Description          string
+	Price                float64
+	DatePlacement        *time.Time
+	Location             string
+	TypePromotion        TypePromotion
+	ViewsCount           uint32
+	DateExpirePromotion  *time.Time
+	AdvertismentCategory AdvertismentCategory
+	Reviews              []Review
+	Photos               []AdPhoto
+	Attributes           []AdvertismentAttribute
+	Moderation           AdvertismentModeration
+	// Status: draft, scheduled, active, archived или deleted
+	Status          string
+	StatusChangedAt *time.Time
+	// PublishAt - время публикации для статуса scheduled
+	PublishAt *time.Time
+	City      City
+	// Latitude/Longitude - точка продавца; без нее в поиске берутся координаты города
+	Latitude  *float64
+	Longitude *float64
+	// UpdatedAt - время последнего изменения объявления или его отзывов
+	UpdatedAt *time.Time
 }
 
 type AdvertismentDTO struct {
-	ID	uint64 `json:"id" db:"id"`
-	User UserDTO
-	Name string `json:"name" db:"name"`
-	Description sql.NullString `json:"description" db:"description"`
-	Price float64 `json:"price" db:"price"`
-	DatePlacement sql.NullTime `json:"date_placement" db:"date_placement"` //TODO 
-	Location sql.NullString `json:"location" db:"location"`
-	TypePromotion TypePromotion 
-	ViewsCount uint32 `json:"views_count" db:"views_count"`
-	DateExpirePromotion sql.NullTime `json:"date_expire_promotion" db:"date_expire_promotion"` //TODO 
-	AdvertismentCategory AdvertismentCategory 
-	Reviews []Review
-	Photos []AdPhoto
+	ID                   uint64 `json:"id" db:"id"`
+	User                 UserDTO
+	Name                 string         `json:"name" db:"name"`
+	Description          sql.NullString `json:"description" db:"description"`
+	Price                float64        `json:"price" db:"price"`
+	DatePlacement        sql.NullTime   `json:"date_placement" db:"date_placement"` //TODO
+	Location             sql.NullString `json:"location" db:"location"`
+	TypePromotion        TypePromotion
+	ViewsCount           uint32       `json:"views_count" db:"views_count"`
+	DateExpirePromotion  sql.NullTime `json:"date_expire_promotion" db:"date_expire_promotion"` //TODO
+	AdvertismentCategory AdvertismentCategory
+	Reviews              []Review
+	Photos               []AdPhoto
+	Attributes           []AdvertismentAttribute
+	Moderation           AdvertismentModeration
+	Status               string       `json:"status" db:"status"`
+	StatusChangedAt      sql.NullTime `json:"status_changed_at" db:"status_changed_at"`
+	PublishAt            sql.NullTime `json:"publish_at" db:"publish_at"`
+	City                 City
+	Latitude             *float64  `json:"latitude" db:"latitude"`
+	Longitude            *float64  `json:"longitude" db:"longitude"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
 }
 
-
 func ConvertAdvertismentToDTO(a *Advertisment, dto *AdvertismentDTO) {
-		dto.ID = a.ID
-		ConvertUserToDTO(&a.User, &dto.User)
-		dto.Name = a.Name
-		dto.Description = *NewNullString(a.Description)
-		dto.Price = a.Price
-		dto.DatePlacement = *NewNullTime(a.DatePlacement)
-		dto.Location = *NewNullString(a.Location)
-		dto.TypePromotion = a.TypePromotion
-		dto.ViewsCount = a.ViewsCount
-		dto.DateExpirePromotion = *NewNullTime(a.DateExpirePromotion)
-		dto.AdvertismentCategory = a.AdvertismentCategory
-		dto.Reviews = a.Reviews
-		dto.Photos = a.Photos
+	dto.ID = a.ID
+	ConvertUserToDTO(&a.User, &dto.User)
+	dto.Name = a.Name
+	dto.Description = *NewNullString(a.Description)
+	dto.Price = a.Price
+	dto.DatePlacement = *NewNullTime(a.DatePlacement)
+	dto.Location = *NewNullString(a.Location)
+	dto.TypePromotion = a.TypePromotion
+	dto.ViewsCount = a.ViewsCount
+	dto.DateExpirePromotion = *NewNullTime(a.DateExpirePromotion)
+	dto.AdvertismentCategory = a.AdvertismentCategory
+	dto.Reviews = a.Reviews
+	dto.Photos = a.Photos
+	dto.Attributes = a.Attributes
+	dto.Moderation = a.Moderation
+	dto.Status = a.Status
+	dto.StatusChangedAt = *NewNullTime(a.StatusChangedAt)
+	dto.PublishAt = *NewNullTime(a.PublishAt)
+	dto.City = a.City
+	dto.Latitude = a.Latitude
+	dto.Longitude = a.Longitude
 }
 
 func ConvertDTOToAdvertisment(dto *AdvertismentDTO, a *Advertisment) {
@@ -85,4 +192,25 @@ func ConvertDTOToAdvertisment(dto *AdvertismentDTO, a *Advertisment) {
 	a.AdvertismentCategory = dto.AdvertismentCategory
 	a.Reviews = dto.Reviews
 	a.Photos = dto.Photos
-}
\ No newline at end of file
+	a.Attributes = dto.Attributes
+	a.Moderation = dto.Moderation
+	a.Status = dto.Status
+	if dto.StatusChangedAt.Valid {
+		a.StatusChangedAt = &dto.StatusChangedAt.Time
+	} else {
+		a.StatusChangedAt = nil
+	}
+	if dto.PublishAt.Valid {
+		a.PublishAt = &dto.PublishAt.Time
+	} else {
+		a.PublishAt = nil
+	}
+	a.City = dto.City
+	a.Latitude = dto.Latitude
+	a.Longitude = dto.Longitude
+	if !dto.UpdatedAt.IsZero() {
+		a.UpdatedAt = &dto.UpdatedAt
+	} else {
+		a.UpdatedAt = nil
+	}
+}
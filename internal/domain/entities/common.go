@@ -5,14 +5,13 @@ import (
 	"time"
 )
 
-
 func NewNullString(s string) *sql.NullString {
 	if len(s) == 0 {
 		return &sql.NullString{}
 	} else {
 		return &sql.NullString{
 			String: s,
-			Valid: true,
+			Valid:  true,
 		}
 	}
 }
@@ -22,7 +21,7 @@ func NewNullTime(t *time.Time) *sql.NullTime {
 		return &sql.NullTime{}
 	} else {
 		return &sql.NullTime{
-			Time: *t,
+			Time:  *t,
 			Valid: true,
 		}
 	}
@@ -6,20 +6,19 @@ import (
 	"time"
 )
 
-
 type ReviewDTO struct {
-	ID uint64
-	Text string
-	Mark uint16
+	ID       uint64
+	Text     string
+	Mark     uint16
 	Reviewer UserDTO
-	Deal Deal
+	Deal     Deal
 }
 type Review struct {
-	ID              uint64
-	Text            string
-	Mark            uint16
-	Reviewer        User
-	Deal Deal
+	ID       uint64
+	Text     string
+	Mark     uint16
+	Reviewer User
+	Deal     Deal
 }
 
 func ConvertReviewToDTO(review *Review, dto *ReviewDTO) {
@@ -33,7 +32,7 @@ func ConvertDTOToReview(dto *ReviewDTO, review *Review) {
 	review.ID = dto.ID
 	review.Text = dto.Text
 	review.Mark = dto.Mark
-	ConvertDTOToUser(&dto.Reviewer, &review.Reviewer,)
+	ConvertDTOToUser(&dto.Reviewer, &review.Reviewer)
 	review.Deal = dto.Deal
 }
 
@@ -46,54 +45,58 @@ type AdPhoto struct {
 type Deal struct {
 	ID              uint64
 	AdvertisementID uint64
-	BuyerID uint64
-	DateDeal time.Time
+	BuyerID         uint64
+	DateDeal        time.Time
 }
 
 type ProfileStatistic struct {
-	DealID 			uint64
-	AdID			uint64
-	DealReviewID	uint64
-	AdName			string
-	AdPrice			float32
-	AdReviewMark	uint16
-	AdPhotoPath		string
+	DealID       uint64
+	AdID         uint64
+	DealReviewID uint64
+	AdName       string
+	AdPrice      float32
+	AdReviewMark uint16
+	AdPhotoPath  string
 }
 
 type MyAdvertisement struct {
-	AdID					uint64
-	AdPhotoPath				string
-	AdName					string
-	AdPrice					float64
-	AdCountViews			uint32
-	AdTypePromotionID		uint64
-	AdTypePromotionName		string
-	AdDateExpirePromotion	*time.Time
+	AdID                  uint64
+	AdPhotoPath           string
+	AdName                string
+	AdPrice               float64
+	AdCountViews          uint32
+	AdTypePromotionID     uint64
+	AdTypePromotionName   string
+	AdDateExpirePromotion *time.Time
+	AdModerationStatus    string
+	AdModerationReason    string
+	AdStatus              string
+	AdPublishAt           *time.Time
 }
 
 type ProfileReview struct {
-	AdID uint64
-	DealID uint64
-	ReviewID uint64
-	ReviewerID uint64
-	ReviewText string
-	ReviewMark uint16
-	ReviewerPathAva string
-	ReviewerUsername string
+	AdID              uint64
+	DealID            uint64
+	ReviewID          uint64
+	ReviewerID        uint64
+	ReviewText        string
+	ReviewMark        uint16
+	ReviewerPathAva   string
+	ReviewerUsername  string
 	ReviewerFirstname string
-	ReviewerLastname string
+	ReviewerLastname  string
 }
 type ProfileReviewDTO struct {
-	AdID uint64
-	DealID uint64
-	ReviewID uint64
-	ReviewerID uint64
-	ReviewText string
-	ReviewMark uint16
-	ReviewerPathAva sql.NullString
-	ReviewerUsername sql.NullString
+	AdID              uint64
+	DealID            uint64
+	ReviewID          uint64
+	ReviewerID        uint64
+	ReviewText        string
+	ReviewMark        uint16
+	ReviewerPathAva   sql.NullString
+	ReviewerUsername  sql.NullString
 	ReviewerFirstname sql.NullString
-	ReviewerLastname sql.NullString
+	ReviewerLastname  sql.NullString
 }
 
 func ConvertDTOToProfileReview(dto *ProfileReviewDTO, review *ProfileReview) {
@@ -107,4 +110,4 @@ func ConvertDTOToProfileReview(dto *ProfileReviewDTO, review *ProfileReview) {
 	review.ReviewerUsername = dto.ReviewerUsername.String
 	review.ReviewerFirstname = dto.ReviewerFirstname.String
 	review.ReviewerLastname = dto.ReviewerLastname.String
-}
\ No newline at end of file
+}
@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Доменные события объявлений. Те же имена используются в подписках вебхуков.
+const (
+	EventAdPublished = "advertisment.published"
+	EventAdScheduled = "advertisment.scheduled"
+	EventAdApproved  = "advertisment.approved"
+	EventAdRejected  = "advertisment.rejected"
+	EventAdArchived  = "advertisment.archived"
+	EventAdDeleted   = "advertisment.deleted"
+	EventAdRestored  = "advertisment.restored"
+)
+
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusProcessed = "processed"
+	OutboxStatusFailed    = "failed"
+)
+
+// OutboxEvent - событие, записанное в outbox в одной транзакции с изменением
+type OutboxEvent struct {
+	ID        uint64
+	Type      string
+	Payload   json.RawMessage
+	Attempts  int
+	CreatedAt *time.Time
+}
+
+// AdvertismentEvent - данные событий объявления
+type AdvertismentEvent struct {
+	AdID           uint64     `json:"ad_id"`
+	UserID         uint64     `json:"user_id"`
+	Status         string     `json:"status,omitempty"`
+	PreviousStatus string     `json:"previous_status,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+	PublishAt      *time.Time `json:"publish_at,omitempty"`
+}
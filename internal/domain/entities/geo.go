@@ -0,0 +1,13 @@
+package entities
+
+import "time"
+
+type City struct {
+	ID        uint64
+	Name      string
+	Region    string
+	Latitude  float64
+	Longitude float64
+	// UpdatedAt заполняется в карточке объявления и входит в ее версию
+	UpdatedAt *time.Time `json:",omitempty"`
+}
@@ -0,0 +1,56 @@
+package entities
+
+import "time"
+
+const (
+	NotificationMessage           = "message"
+	NotificationDeal              = "deal"
+	NotificationReview            = "review"
+	NotificationPromotionExpiry   = "promotion_expiry"
+	NotificationSavedSearchMatch  = "saved_search_match"
+	NotificationSavedSearchDigest = "saved_search_digest"
+)
+
+// NotificationKinds - типы событий, для которых пользователь настраивает каналы
+var NotificationKinds = []string{
+	NotificationMessage,
+	NotificationDeal,
+	NotificationReview,
+	NotificationPromotionExpiry,
+	NotificationSavedSearchMatch,
+	NotificationSavedSearchDigest,
+}
+
+const (
+	DeliveryStatusPending = "pending"
+	DeliveryStatusSent    = "sent"
+	DeliveryStatusFailed  = "failed"
+)
+
+type Notification struct {
+	ID     uint64
+	UserID uint64
+	Kind   string
+	Title  string
+	Body   string
+	// Payload - данные для клиента, например id объявлений
+	Payload map[string]interface{}
+	// DedupKey - повторное уведомление с тем же ключом не создается
+	DedupKey  string
+	CreatedAt *time.Time
+	ReadAt    *time.Time
+}
+
+// NotificationDelivery - отправка уведомления в один внешний канал
+type NotificationDelivery struct {
+	ID           uint64
+	Channel      string
+	Attempts     int
+	Notification Notification
+}
+
+type NotificationPreference struct {
+	Kind    string
+	Channel string
+	Enabled bool
+}
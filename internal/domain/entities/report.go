@@ -0,0 +1,38 @@
+package entities
+
+import "time"
+
+const (
+	ReportTargetAdvertisment = "advertisment"
+	ReportTargetReview       = "review"
+	ReportTargetUser         = "user"
+)
+
+const (
+	ReportReasonSpam       = "spam"
+	ReportReasonFraud      = "fraud"
+	ReportReasonAbuse      = "abuse"
+	ReportReasonProhibited = "prohibited"
+	ReportReasonOther      = "other"
+)
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+type Report struct {
+	ID         uint64
+	ReporterID uint64
+	TargetType string
+	TargetID   uint64
+	Reason     string
+	Comment    string
+	Status     string
+	CreatedAt  *time.Time
+	ResolvedBy uint64
+	ResolvedAt *time.Time
+	// TargetReports - открытых жалоб на тот же объект, заполняется в списке для модератора
+	TargetReports uint64
+}
@@ -0,0 +1,27 @@
+package entities
+
+import "time"
+
+const (
+	// SavedSearchNotifyInstant - уведомление о каждом новом объявлении
+	SavedSearchNotifyInstant = "instant"
+	// SavedSearchNotifyDaily - одно уведомление в сутки со всеми новыми объявлениями
+	SavedSearchNotifyDaily = "daily"
+)
+
+type SavedSearch struct {
+	ID     uint64
+	UserID uint64
+	Name   string
+	// Filter - фильтр поиска без сортировки и пагинации
+	Filter       AdvertismentFilter
+	Notify       string
+	CreatedAt    *time.Time
+	LastDigestAt *time.Time
+}
+
+// SavedSearchDigest - накопленные для дайджеста объявления одного поиска
+type SavedSearchDigest struct {
+	SavedSearch SavedSearch
+	AdIDs       []uint64
+}
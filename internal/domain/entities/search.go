@@ -0,0 +1,51 @@
+package entities
+
+import "time"
+
+const (
+	SortDateDesc  = "date_desc"
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	// SortDistance - ближайшие к точке Lat/Lon первыми
+	SortDistance = "distance"
+)
+
+type AttributeFilter struct {
+	Code string
+	// Value - точное совпадение, Min/Max - диапазон для int/float
+	Value *string
+	Min   *float64
+	Max   *float64
+}
+
+type AdvertismentFilter struct {
+	// CategoryID - категория вместе со всеми дочерними
+	CategoryID uint64
+	Query      string
+	PriceMin   *float64
+	PriceMax   *float64
+	Attributes []AttributeFilter
+	CityID     uint64
+	// Lat/Lon - точка поиска, RadiusKm - искать не дальше N км от нее
+	Lat      *float64
+	Lon      *float64
+	RadiusKm *float64
+	Sort     string
+	Limit    uint64
+	Offset   uint64
+}
+
+type AdvertisementPreview struct {
+	AdID            uint64
+	AdName          string
+	AdPrice         float64
+	AdLocation      string
+	AdDatePlacement *time.Time
+	AdCategoryID    uint64
+	AdPhotoPath     string
+	SellerVerified  bool
+	CityID          uint64
+	CityName        string
+	// DistanceKm - расстояние до точки поиска, если она задана
+	DistanceKm *float64
+}
@@ -2,37 +2,43 @@ package entities
 
 import (
 	"database/sql"
+	"time"
 )
 
-
 type UserRole struct {
-	ID uint32
+	ID   uint32
 	Name string
 }
 
-
 type User struct {
-	ID uint64
-	PathAva string
-	Username string
-	Firstname string
-	Lastname string
-	NumberPhone string
-	Rating float32
+	ID                 uint64
+	PathAva            string
+	Username           string
+	Firstname          string
+	Lastname           string
+	NumberPhone        string
+	Rating             float32
 	VerificationStatus string
-	Role UserRole 
+	// Verified - бейдж проверенного продавца
+	Verified       bool
+	PhoneConfirmed bool
+	Role           UserRole
+	// UpdatedAt - время последнего изменения профиля, из него строятся ETag и Last-Modified
+	UpdatedAt *time.Time
 }
 
 type UserDTO struct {
-	ID uint64 `json:"id" db:"id"`
-	PathAva sql.NullString `json:"path_ava" db:"path_ava"`
-	Username sql.NullString `json:"username" db:"username"`
-	Firstname sql.NullString `json:"firstname" db:"firstname"`
-	Lastname sql.NullString `json:"lastname" db:"lastname"`
-	NumberPhone sql.NullString `json:"number_phone" db:"number_phone"`
-	Rating float32 `json:"rating" db:"rating"`
-	VerificationStatus string `json:"verification_status" db:"verification_status"`
-	Role UserRole
+	ID                 uint64         `json:"id" db:"id"`
+	PathAva            sql.NullString `json:"path_ava" db:"path_ava"`
+	Username           sql.NullString `json:"username" db:"username"`
+	Firstname          sql.NullString `json:"firstname" db:"firstname"`
+	Lastname           sql.NullString `json:"lastname" db:"lastname"`
+	NumberPhone        sql.NullString `json:"number_phone" db:"number_phone"`
+	Rating             float32        `json:"rating" db:"rating"`
+	VerificationStatus string         `json:"verification_status" db:"verification_status"`
+	PhoneConfirmed     bool           `json:"phone_confirmed" db:"phone_confirmed"`
+	Role               UserRole
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
 }
 
 func ConvertDTOToUser(dto *UserDTO, u *User) {
@@ -44,7 +50,15 @@ func ConvertDTOToUser(dto *UserDTO, u *User) {
 	u.NumberPhone = dto.NumberPhone.String
 	u.Rating = dto.Rating
 	u.VerificationStatus = dto.VerificationStatus
+	u.Verified = dto.VerificationStatus == VerificationVerified
+	u.PhoneConfirmed = dto.PhoneConfirmed
 	u.Role = dto.Role
+	// профиль в составе отзывов и списков читается без updated_at
+	if !dto.UpdatedAt.IsZero() {
+		u.UpdatedAt = &dto.UpdatedAt
+	} else {
+		u.UpdatedAt = nil
+	}
 }
 
 func ConvertUserToDTO(u *User, dto *UserDTO) {
@@ -56,6 +70,17 @@ func ConvertUserToDTO(u *User, dto *UserDTO) {
 	dto.NumberPhone = *NewNullString(u.NumberPhone)
 	dto.Rating = u.Rating
 	dto.VerificationStatus = u.VerificationStatus
+	dto.PhoneConfirmed = u.PhoneConfirmed
 	dto.Role = u.Role
 }
 
+// PhoneCode - одноразовый код подтверждения номера. Сам код не хранится,
+// только его HMAC.
+type PhoneCode struct {
+	ID       uint64
+	UserID   uint64
+	Phone    string
+	CodeHash []byte
+	Attempts int
+	Expired  bool
+}
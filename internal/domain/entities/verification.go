@@ -0,0 +1,28 @@
+package entities
+
+import "time"
+
+// Значения users.verification_status
+const (
+	VerificationUnverified = "unverified"
+	VerificationPending    = "pending"
+	VerificationVerified   = "verified"
+	VerificationRejected   = "rejected"
+)
+
+const (
+	VerificationMethodDocument = "document"
+	VerificationMethodPhone    = "phone"
+)
+
+type VerificationRequest struct {
+	ID           uint64
+	UserID       uint64
+	Method       string
+	DocumentPath string
+	Status       string
+	Reason       string
+	CreatedAt    *time.Time
+	ReviewedBy   uint64
+	ReviewedAt   *time.Time
+}
@@ -0,0 +1,52 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventTypes - события, на которые можно подписать вебхук
+var WebhookEventTypes = []string{
+	EventAdPublished,
+	EventAdScheduled,
+	EventAdApproved,
+	EventAdRejected,
+	EventAdArchived,
+	EventAdDeleted,
+	EventAdRestored,
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+	ID     uint64
+	UserID uint64
+	URL    string
+	// Secret показывается только при создании подписки
+	Secret     string `json:"-"`
+	EventTypes []string
+	CreatedAt  *time.Time
+}
+
+// WebhookDelivery - отправка события в одну подписку; хранится и после
+// доставки как запись журнала
+type WebhookDelivery struct {
+	ID             uint64
+	WebhookID      uint64
+	EventType      string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int
+	NextAttemptAt  *time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      *time.Time
+	DeliveredAt    *time.Time
+	// URL и Secret подписки заполняются для воркера
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
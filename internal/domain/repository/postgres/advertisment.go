@@ -0,0 +1,534 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const queryCreateAdvertisment = `
+INSERT INTO advertisements
+	(user_id, name, description, price, location, category_id, moderation_status, moderation_flags, status, publish_at,
+	city_id, latitude, longitude)
+VALUES
+	($1, $2, $3, $4, $5, $6, 'pending', $7, $8, $9, $10, $11, $12)
+RETURNING id, date_placement, moderation_status;
+`
+
+func (r *Repository) CreateAdvertisment(ctx context.Context, advertisment *entities.Advertisment) error {
+	if err := r.conn(ctx, "CreateAdvertisment").QueryRow(
+		ctx,
+		queryCreateAdvertisment,
+		advertisment.User.ID,
+		advertisment.Name,
+		entities.NewNullString(advertisment.Description),
+		advertisment.Price,
+		entities.NewNullString(advertisment.Location),
+		advertisment.AdvertismentCategory.ID,
+		moderationFlags(advertisment),
+		advertisment.Status,
+		advertisment.PublishAt,
+		nullableID(advertisment.City.ID),
+		advertisment.Latitude,
+		advertisment.Longitude,
+	).Scan(
+		&advertisment.ID,
+		&advertisment.DatePlacement,
+		&advertisment.Moderation.Status,
+	); err != nil {
+		r.logger(ctx).Error("CreateAdvertisment: error with INSERT INTO", zap.Error(err))
+		return mapPgError(err)
+	}
+	return nil
+}
+
+const queryUpdateAdvertisment = `
+UPDATE advertisements
+SET name = $2,
+	description = $3,
+	price = $4,
+	location = $5,
+	category_id = $6,
+	city_id = $8,
+	latitude = $9,
+	longitude = $10,
+	moderation_status = 'pending',
+	moderation_flags = $7,
+	moderation_reason = NULL,
+	moderated_by = NULL,
+	moderated_at = NULL
+WHERE id = $1;
+`
+
+func (r *Repository) UpdateAdvertisment(ctx context.Context, advertisment *entities.Advertisment) error {
+	result, err := r.conn(ctx, "UpdateAdvertisment").Exec(
+		ctx,
+		queryUpdateAdvertisment,
+		advertisment.ID,
+		advertisment.Name,
+		entities.NewNullString(advertisment.Description),
+		advertisment.Price,
+		entities.NewNullString(advertisment.Location),
+		advertisment.AdvertismentCategory.ID,
+		moderationFlags(advertisment),
+		nullableID(advertisment.City.ID),
+		advertisment.Latitude,
+		advertisment.Longitude,
+	)
+	if err != nil {
+		r.logger(ctx).Error("UpdateAdvertisment: error with UPDATE", zap.Error(err))
+		return mapPgError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	advertisment.Moderation.Status = entities.ModerationPending
+	return nil
+}
+
+func moderationFlags(advertisment *entities.Advertisment) []string {
+	if advertisment.Moderation.Flags == nil {
+		return []string{}
+	}
+	return advertisment.Moderation.Flags
+}
+
+const querySetAdvertismentPromotion = `
+UPDATE advertisements
+SET type_id = $2,
+	date_expire_promotion = CURRENT_TIMESTAMP + $3::interval
+WHERE id = $1
+RETURNING date_expire_promotion;
+`
+
+// SetAdvertismentPromotion продвигает объявление на timeLive от текущего момента
+func (r *Repository) SetAdvertismentPromotion(ctx context.Context, advertisment *entities.Advertisment, timeLive time.Duration) error {
+	if err := r.conn(ctx, "SetAdvertismentPromotion").QueryRow(
+		ctx,
+		querySetAdvertismentPromotion,
+		advertisment.ID,
+		advertisment.TypePromotion.ID,
+		timeLive,
+	).Scan(&advertisment.DateExpirePromotion); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		r.logger(ctx).Error("SetAdvertismentPromotion: error with UPDATE", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryGetAdvertismentStatus = `
+SELECT user_id, status
+FROM advertisements
+WHERE id = $1;
+`
+
+// GetAdvertismentStatus возвращает владельца и статус объявления (в том числе удаленного)
+func (r *Repository) GetAdvertismentStatus(ctx context.Context, adID uint64) (uint64, string, error) {
+	var uID uint64
+	var status string
+	if err := r.conn(ctx, "GetAdvertismentStatus").QueryRow(ctx, queryGetAdvertismentStatus, adID).Scan(&uID, &status); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, "", ErrNotFound
+		}
+		r.logger(ctx).Error("GetAdvertismentStatus: error with QueryRow", zap.Error(err))
+		return 0, "", err
+	}
+	return uID, status, nil
+}
+
+// Карточка объявления включает профили продавца и авторов отзывов, категорию,
+// тип продвижения и город, поэтому их изменения тоже меняют версию карточки.
+// Отзывы, фотографии и атрибуты сдвигают a.updated_at триггерами. GREATEST
+// пропускает NULL: отзывов, типа продвижения или города может не быть.
+// Вместе с версией читаются поля, по которым usecase решает, видно ли
+// объявление зрителю.
+const queryGetAdvertismentUpdatedAt = `
+SELECT
+	GREATEST(a.updated_at, u.updated_at, cp.updated_at, tp.updated_at, ci.updated_at, (
+		SELECT MAX(ru.updated_at)
+		FROM reviews r
+		JOIN users ru ON ru.id = r.reviewer_id
+		WHERE r.advertisement_id = a.id
+			AND NOT r.is_hidden
+	)),
+	a.user_id,
+	a.status,
+	a.moderation_status,
+	a.is_hidden
+FROM advertisements a
+JOIN users u ON u.id = a.user_id
+JOIN categories_product cp ON cp.id = a.category_id
+LEFT JOIN types_promotion tp ON tp.id = a.type_id
+LEFT JOIN cities ci ON ci.id = a.city_id
+WHERE a.id = $1;
+`
+
+// GetAdvertismentUpdatedAt возвращает время последнего изменения карточки
+// объявления и заполняет владельца, статус и модерацию advertisment
+func (r *Repository) GetAdvertismentUpdatedAt(ctx context.Context, advertisment *entities.Advertisment) (time.Time, error) {
+	var updatedAt time.Time
+	if err := r.readConn(ctx, "GetAdvertismentUpdatedAt").QueryRow(ctx, queryGetAdvertismentUpdatedAt, advertisment.ID).Scan(
+		&updatedAt,
+		&advertisment.User.ID,
+		&advertisment.Status,
+		&advertisment.Moderation.Status,
+		&advertisment.Moderation.Hidden,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return updatedAt, ErrNotFound
+		}
+		r.logger(ctx).Error("GetAdvertismentUpdatedAt: error with QueryRow", zap.Error(err))
+		return updatedAt, err
+	}
+	return updatedAt, nil
+}
+
+const queryGetUserAdvertismentIDs = `
+SELECT id
+FROM advertisements
+WHERE user_id = $1;
+`
+
+// GetUserAdvertismentIDs возвращает id всех объявлений пользователя
+func (r *Repository) GetUserAdvertismentIDs(ctx context.Context, uID uint64) ([]uint64, error) {
+	return r.getAdvertismentIDs(ctx, "GetUserAdvertismentIDs", queryGetUserAdvertismentIDs, uID)
+}
+
+// getAdvertismentIDs читает id объявлений запросом query с одним параметром.
+// Читает основная база: id нужны сразу после изменения, для сброса кеша.
+func (r *Repository) getAdvertismentIDs(ctx context.Context, op, query string, arg uint64) ([]uint64, error) {
+	rows, err := r.conn(ctx, op).Query(ctx, query, arg)
+	if err != nil {
+		r.logger(ctx).Error(op+": error with SELECT FROM", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []uint64{}
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			r.logger(ctx).Error(op+": error with scan row", zap.Error(err))
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error(op+": error iterating through rows", zap.Error(err))
+		return nil, err
+	}
+	return ids, nil
+}
+
+const querySetAdvertismentStatus = `
+UPDATE advertisements
+SET status = $3,
+	status_changed_at = CURRENT_TIMESTAMP
+WHERE id = $1
+	AND status = $2
+RETURNING status_changed_at;
+`
+
+const queryPublishAdvertisment = `
+UPDATE advertisements
+SET status = $3,
+	publish_at = $4,
+	date_placement = CASE WHEN $3 = 'active' THEN CURRENT_TIMESTAMP ELSE date_placement END,
+	status_changed_at = CURRENT_TIMESTAMP
+WHERE id = $1
+	AND status = $2
+RETURNING date_placement, status_changed_at;
+`
+
+// PublishAdvertisment публикует черновик сразу (active) или ставит на время publish_at (scheduled).
+// События пишутся в outbox той же транзакцией.
+func (r *Repository) PublishAdvertisment(ctx context.Context, advertisment *entities.Advertisment, from string, events ...entities.OutboxEvent) error {
+	return r.WithTx(ctx, func(ctx context.Context) error {
+		if err := r.conn(ctx, "PublishAdvertisment").QueryRow(
+			ctx,
+			queryPublishAdvertisment,
+			advertisment.ID,
+			from,
+			advertisment.Status,
+			advertisment.PublishAt,
+		).Scan(
+			&advertisment.DatePlacement,
+			&advertisment.StatusChangedAt,
+		); err != nil {
+			if err == pgx.ErrNoRows {
+				return ErrNotFound
+			}
+			r.logger(ctx).Error("PublishAdvertisment: error with UPDATE", zap.Error(err))
+			return err
+		}
+		return r.insertOutboxEvents(ctx, events)
+	})
+}
+
+// Несколько инстансов не публикуют одно объявление дважды благодаря SKIP LOCKED
+const queryPublishScheduledAdvertisments = `
+UPDATE advertisements
+SET status = 'active',
+	date_placement = CURRENT_TIMESTAMP,
+	status_changed_at = CURRENT_TIMESTAMP
+WHERE id IN (
+	SELECT id
+	FROM advertisements
+	WHERE status = 'scheduled'
+		AND publish_at <= CURRENT_TIMESTAMP
+	ORDER BY publish_at
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING id, user_id;
+`
+
+// PublishScheduledAdvertisments публикует до limit объявлений, время которых пришло,
+// и в той же транзакции пишет в outbox событие event для каждого из них
+func (r *Repository) PublishScheduledAdvertisments(
+	ctx context.Context,
+	limit int,
+	ads *[]*entities.Advertisment,
+	event func(ad *entities.Advertisment) entities.OutboxEvent,
+) error {
+	return r.WithTx(ctx, func(ctx context.Context) error {
+		rows, err := r.conn(ctx, "PublishScheduledAdvertisments").Query(ctx, queryPublishScheduledAdvertisments, limit)
+		if err != nil {
+			r.logger(ctx).Error("PublishScheduledAdvertisments: error with UPDATE", zap.Error(err))
+			return err
+		}
+		published := []*entities.Advertisment{}
+		for rows.Next() {
+			ad := entities.Advertisment{}
+			if err := rows.Scan(&ad.ID, &ad.User.ID); err != nil {
+				rows.Close()
+				r.logger(ctx).Error("PublishScheduledAdvertisments: error with scan row", zap.Error(err))
+				return err
+			}
+			published = append(published, &ad)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			r.logger(ctx).Error("PublishScheduledAdvertisments: error iterating through rows", zap.Error(err))
+			return err
+		}
+
+		events := make([]entities.OutboxEvent, 0, len(published))
+		for _, ad := range published {
+			events = append(events, event(ad))
+		}
+		if err := r.insertOutboxEvents(ctx, events); err != nil {
+			return err
+		}
+		*ads = append(*ads, published...)
+		return nil
+	})
+}
+
+// SetAdvertismentStatus меняет статус, только если он не изменился с момента чтения.
+// События пишутся в outbox той же транзакцией.
+func (r *Repository) SetAdvertismentStatus(ctx context.Context, advertisment *entities.Advertisment, from string, events ...entities.OutboxEvent) error {
+	return r.WithTx(ctx, func(ctx context.Context) error {
+		if err := r.conn(ctx, "SetAdvertismentStatus").QueryRow(
+			ctx,
+			querySetAdvertismentStatus,
+			advertisment.ID,
+			from,
+			advertisment.Status,
+		).Scan(&advertisment.StatusChangedAt); err != nil {
+			if err == pgx.ErrNoRows {
+				return ErrNotFound
+			}
+			r.logger(ctx).Error("SetAdvertismentStatus: error with UPDATE", zap.Error(err))
+			return err
+		}
+		return r.insertOutboxEvents(ctx, events)
+	})
+}
+
+const queryDeleteAdvertismentPhotos = `
+DELETE FROM ad_photos
+WHERE advertisement_id = $1;
+`
+
+const queryInsertAdvertismentPhoto = `
+INSERT INTO ad_photos
+	(path, advertisement_id)
+VALUES
+	($1, $2)
+RETURNING id;
+`
+
+// SetAdvertismentPhotos заменяет фотографии объявления; первая - главная
+func (r *Repository) SetAdvertismentPhotos(ctx context.Context, advertisment *entities.Advertisment) error {
+	if _, err := r.conn(ctx, "SetAdvertismentPhotos").Exec(ctx, queryDeleteAdvertismentPhotos, advertisment.ID); err != nil {
+		r.logger(ctx).Error("SetAdvertismentPhotos: error with DELETE", zap.Error(err))
+		return err
+	}
+	for i := range advertisment.Photos {
+		photo := &advertisment.Photos[i]
+		photo.AdvertisementID = advertisment.ID
+		if err := r.conn(ctx, "SetAdvertismentPhotos").QueryRow(ctx, queryInsertAdvertismentPhoto, photo.Path, advertisment.ID).Scan(&photo.ID); err != nil {
+			r.logger(ctx).Error("SetAdvertismentPhotos: error with INSERT INTO", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// searchQuery собирает SELECT по фильтру. Значения передаются только
+// параметрами, в текст запроса попадают лишь номера плейсхолдеров.
+type searchQuery struct {
+	with  []string
+	where []string
+	args  []interface{}
+}
+
+func (q *searchQuery) arg(v interface{}) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// newSearchQuery переводит фильтр в условия WHERE и возвращает выражение
+// расстояния до точки поиска. С postgis расстояние считается функциями PostGIS.
+func newSearchQuery(filter *entities.AdvertismentFilter, postgis bool) (*searchQuery, string) {
+	q := &searchQuery{}
+	q.where = append(q.where, "NOT a.is_hidden", "a.moderation_status = 'approved'", "a.status = 'active'")
+
+	if filter.CategoryID != 0 {
+		q.with = append(q.with, `subtree AS (
+	SELECT id
+	FROM categories_product
+	WHERE id = `+q.arg(filter.CategoryID)+`
+	UNION ALL
+	SELECT c.id
+	FROM categories_product c
+		JOIN subtree s ON c.parent_id = s.id
+)`)
+		q.where = append(q.where, "a.category_id IN (SELECT id FROM subtree)")
+	}
+	if filter.Query != "" {
+		pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(filter.Query) + "%"
+		p := q.arg(pattern)
+		q.where = append(q.where, "(a.name ILIKE "+p+" OR a.description ILIKE "+p+")")
+	}
+	if filter.PriceMin != nil {
+		q.where = append(q.where, "a.price >= "+q.arg(*filter.PriceMin))
+	}
+	if filter.PriceMax != nil {
+		q.where = append(q.where, "a.price <= "+q.arg(*filter.PriceMax))
+	}
+	for _, attr := range filter.Attributes {
+		cond := []string{"aa.advertisement_id = a.id", "ca.code = " + q.arg(attr.Code)}
+		if attr.Value != nil {
+			cond = append(cond, "aa.value = "+q.arg(*attr.Value))
+		}
+		if attr.Min != nil {
+			cond = append(cond, "aa.value_num >= "+q.arg(*attr.Min))
+		}
+		if attr.Max != nil {
+			cond = append(cond, "aa.value_num <= "+q.arg(*attr.Max))
+		}
+		q.where = append(q.where, `EXISTS (SELECT 1
+	FROM advertisement_attributes aa
+		JOIN category_attributes ca ON aa.attribute_id = ca.id
+	WHERE `+strings.Join(cond, " AND ")+`)`)
+	}
+
+	if filter.CityID != 0 {
+		q.where = append(q.where, "a.city_id = "+q.arg(filter.CityID))
+	}
+	distance := "NULL::double precision"
+	if filter.Lat != nil && filter.Lon != nil {
+		distance = distanceExpr(q.arg(*filter.Lat), q.arg(*filter.Lon), postgis)
+		if filter.RadiusKm != nil {
+			q.withinRadius(*filter.Lat, *filter.Lon, *filter.RadiusKm, distance, postgis)
+		}
+	}
+	return q, distance
+}
+
+func (q *searchQuery) writeWith(sb *strings.Builder) {
+	if len(q.with) > 0 {
+		sb.WriteString("WITH RECURSIVE " + strings.Join(q.with, ", ") + "\n")
+	}
+}
+
+func buildSearchQuery(filter *entities.AdvertismentFilter, postgis bool) (string, []interface{}) {
+	q, distance := newSearchQuery(filter, postgis)
+	var sb strings.Builder
+	q.writeWith(&sb)
+	sb.WriteString(`SELECT
+	a.id,
+	a.name,
+	a.price,
+	COALESCE(a.location, ''),
+	a.date_placement,
+	a.category_id,
+	COALESCE((SELECT ph.path FROM ad_photos ph WHERE ph.advertisement_id = a.id ORDER BY ph.id LIMIT 1), ''),
+	u.verification_status = 'verified',
+	COALESCE(a.city_id, 0),
+	COALESCE(ci.name, ''),
+	` + distance + ` AS distance
+FROM advertisements a
+	JOIN users u ON a.user_id = u.id
+	LEFT JOIN cities ci ON a.city_id = ci.id
+WHERE `)
+	sb.WriteString(strings.Join(q.where, "\n\tAND "))
+	switch filter.Sort {
+	case entities.SortPriceAsc:
+		sb.WriteString("\nORDER BY a.price, a.id DESC")
+	case entities.SortPriceDesc:
+		sb.WriteString("\nORDER BY a.price DESC, a.id DESC")
+	case entities.SortDistance:
+		sb.WriteString("\nORDER BY distance NULLS LAST, a.id DESC")
+	default:
+		sb.WriteString("\nORDER BY a.date_placement DESC, a.id DESC")
+	}
+	sb.WriteString("\nLIMIT " + q.arg(filter.Limit) + " OFFSET " + q.arg(filter.Offset) + ";")
+	return sb.String(), q.args
+}
+
+func (r *Repository) SearchAdvertisments(ctx context.Context, filter *entities.AdvertismentFilter, ads *[]*entities.AdvertisementPreview) error {
+	query, args := buildSearchQuery(filter, r.cfg.Geo.PostGIS)
+	rows, err := r.readConn(ctx, "SearchAdvertisments").Query(ctx, query, args...)
+	if err != nil {
+		r.logger(ctx).Error("SearchAdvertisments: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		ad := entities.AdvertisementPreview{}
+		if err := rows.Scan(
+			&ad.AdID,
+			&ad.AdName,
+			&ad.AdPrice,
+			&ad.AdLocation,
+			&ad.AdDatePlacement,
+			&ad.AdCategoryID,
+			&ad.AdPhotoPath,
+			&ad.SellerVerified,
+			&ad.CityID,
+			&ad.CityName,
+			&ad.DistanceKm,
+		); err != nil {
+			r.logger(ctx).Error("SearchAdvertisments: error with scan row", zap.Error(err))
+			return err
+		}
+		*ads = append(*ads, &ad)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("SearchAdvertisments: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var placeholderRe = regexp.MustCompile(`\$(\d+)`)
+
+func TestBuildSearchQuery(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	s := func(v string) *string { return &v }
+
+	tests := []struct {
+		name     string
+		filter   entities.AdvertismentFilter
+		postgis  bool
+		contains []string
+		absent   []string
+		args     []interface{}
+	}{
+		{
+			name:   "empty filter",
+			filter: entities.AdvertismentFilter{Limit: 20},
+			contains: []string{
+				"NOT a.is_hidden\n\tAND a.moderation_status = 'approved'\n\tAND a.status = 'active'",
+				"NULL::double precision AS distance",
+				"ORDER BY a.date_placement DESC, a.id DESC",
+				"LIMIT $1 OFFSET $2;",
+			},
+			absent: []string{"WITH RECURSIVE", "ILIKE", "a.price >=", "EXISTS"},
+			args:   []interface{}{uint64(20), uint64(0)},
+		},
+		{
+			name:   "category subtree",
+			filter: entities.AdvertismentFilter{CategoryID: 7, Limit: 10, Offset: 30},
+			contains: []string{
+				"WITH RECURSIVE subtree AS (",
+				"WHERE id = $1",
+				"a.category_id IN (SELECT id FROM subtree)",
+				"LIMIT $2 OFFSET $3;",
+			},
+			args: []interface{}{uint64(7), uint64(10), uint64(30)},
+		},
+		{
+			name:     "text query escapes LIKE wildcards",
+			filter:   entities.AdvertismentFilter{Query: `50%_off\`, Limit: 1},
+			contains: []string{"(a.name ILIKE $1 OR a.description ILIKE $1)"},
+			absent:   []string{"50%"},
+			args:     []interface{}{`%50\%\_off\\%`, uint64(1), uint64(0)},
+		},
+		{
+			name:     "price range",
+			filter:   entities.AdvertismentFilter{PriceMin: f(100), PriceMax: f(500), Sort: entities.SortPriceAsc, Limit: 5},
+			contains: []string{"a.price >= $1", "a.price <= $2", "ORDER BY a.price, a.id DESC"},
+			args:     []interface{}{100.0, 500.0, uint64(5), uint64(0)},
+		},
+		{
+			name: "attributes",
+			filter: entities.AdvertismentFilter{
+				Attributes: []entities.AttributeFilter{
+					{Code: "brand", Value: s("bmw")},
+					{Code: "mileage", Min: f(1000), Max: f(50000)},
+				},
+				Sort:  entities.SortPriceDesc,
+				Limit: 5,
+			},
+			contains: []string{
+				"aa.advertisement_id = a.id AND ca.code = $1 AND aa.value = $2)",
+				"aa.advertisement_id = a.id AND ca.code = $3 AND aa.value_num >= $4 AND aa.value_num <= $5)",
+				"ORDER BY a.price DESC, a.id DESC",
+			},
+			args: []interface{}{"brand", "bmw", "mileage", 1000.0, 50000.0, uint64(5), uint64(0)},
+		},
+		{
+			name:     "city",
+			filter:   entities.AdvertismentFilter{CityID: 3, Limit: 5},
+			contains: []string{"a.city_id = $1"},
+			args:     []interface{}{uint64(3), uint64(5), uint64(0)},
+		},
+		{
+			name:     "point without radius",
+			filter:   entities.AdvertismentFilter{Lat: f(55.75), Lon: f(37.62), Sort: entities.SortDistance, Limit: 5},
+			contains: []string{"2 * 6371 * asin(", "ORDER BY distance NULLS LAST, a.id DESC"},
+			absent:   []string{"BETWEEN", "NULL::double precision"},
+			args:     []interface{}{55.75, 37.62, uint64(5), uint64(0)},
+		},
+		{
+			name:     "radius without postgis",
+			filter:   entities.AdvertismentFilter{Lat: f(89.9), Lon: f(0), RadiusKm: f(111.2), Limit: 5},
+			contains: []string{"BETWEEN $3 AND $4", " <= $5"},
+			absent:   []string{"ST_"},
+			// широта ограничена полюсом
+			args: []interface{}{89.9, 0.0, 89.9 - 111.2/kmPerDegree, 90.0, 111.2, uint64(5), uint64(0)},
+		},
+		{
+			name:     "radius with postgis",
+			filter:   entities.AdvertismentFilter{Lat: f(55.75), Lon: f(37.62), RadiusKm: f(10), Limit: 5},
+			postgis:  true,
+			contains: []string{"ST_Distance(", "ST_DWithin(", "ST_MakePoint($3, $4)::geography, $5)"},
+			absent:   []string{"asin(", "BETWEEN"},
+			args:     []interface{}{55.75, 37.62, 37.62, 55.75, 10000.0, uint64(5), uint64(0)},
+		},
+		{
+			name:     "point is ignored without both coordinates",
+			filter:   entities.AdvertismentFilter{Lat: f(55.75), RadiusKm: f(10), Limit: 5},
+			contains: []string{"NULL::double precision AS distance"},
+			absent:   []string{"asin(", "BETWEEN"},
+			args:     []interface{}{uint64(5), uint64(0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := buildSearchQuery(&tt.filter, tt.postgis)
+			for _, part := range tt.contains {
+				if !strings.Contains(query, part) {
+					t.Errorf("query does not contain %q:\n%s", part, query)
+				}
+			}
+			for _, part := range tt.absent {
+				if strings.Contains(query, part) {
+					t.Errorf("query contains %q:\n%s", part, query)
+				}
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %#v, want %#v", args, tt.args)
+			}
+			// каждый аргумент используется, лишних плейсхолдеров нет
+			used := map[int]bool{}
+			for _, m := range placeholderRe.FindAllStringSubmatch(query, -1) {
+				n, _ := strconv.Atoi(m[1])
+				if n < 1 || n > len(args) {
+					t.Errorf("placeholder $%d out of range, %d args", n, len(args))
+				}
+				used[n] = true
+			}
+			if len(used) != len(args) {
+				t.Errorf("%d of %d args are used in query", len(used), len(args))
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// Атрибуты категории вместе с унаследованными от всех предков
+const queryGetCategoryAttributes = `
+WITH RECURSIVE ancestors AS (
+	SELECT id, parent_id
+	FROM categories_product
+	WHERE id = $1
+	UNION ALL
+	SELECT c.id, c.parent_id
+	FROM categories_product c
+		JOIN ancestors an ON c.id = an.parent_id
+)
+SELECT
+	ca.id,
+	ca.category_id,
+	ca.code,
+	ca.name,
+	ca.value_type,
+	ca.is_required,
+	ca.options
+FROM category_attributes ca
+	JOIN ancestors an ON ca.category_id = an.id
+ORDER BY ca.id;
+`
+
+func (r *Repository) GetCategoryAttributes(ctx context.Context, categoryID uint64, attributes *[]*entities.CategoryAttribute) error {
+	rows, err := r.conn(ctx, "GetCategoryAttributes").Query(ctx, queryGetCategoryAttributes, categoryID)
+	if err != nil {
+		r.logger(ctx).Error("GetCategoryAttributes: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		attr := entities.CategoryAttribute{}
+		if err := rows.Scan(
+			&attr.ID,
+			&attr.CategoryID,
+			&attr.Code,
+			&attr.Name,
+			&attr.ValueType,
+			&attr.IsRequired,
+			&attr.Options,
+		); err != nil {
+			r.logger(ctx).Error("GetCategoryAttributes: error with scan row", zap.Error(err))
+			return err
+		}
+		*attributes = append(*attributes, &attr)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetCategoryAttributes: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Код атрибута должен быть уникален на всей ветке: у предков и потомков
+const queryIsAttributeCodeUsedInBranch = `
+WITH RECURSIVE ancestors AS (
+	SELECT id, parent_id
+	FROM categories_product
+	WHERE id = $1
+	UNION ALL
+	SELECT c.id, c.parent_id
+	FROM categories_product c
+		JOIN ancestors an ON c.id = an.parent_id
+), descendants AS (
+	SELECT id
+	FROM categories_product
+	WHERE id = $1
+	UNION ALL
+	SELECT c.id
+	FROM categories_product c
+		JOIN descendants d ON c.parent_id = d.id
+)
+SELECT EXISTS (SELECT ca.id
+FROM category_attributes ca
+WHERE ca.code = $2
+	AND (ca.category_id IN (SELECT id FROM ancestors) OR ca.category_id IN (SELECT id FROM descendants)));
+`
+
+func (r *Repository) IsAttributeCodeUsedInBranch(ctx context.Context, categoryID uint64, code string) (bool, error) {
+	var res bool
+	if err := r.conn(ctx, "IsAttributeCodeUsedInBranch").QueryRow(ctx, queryIsAttributeCodeUsedInBranch, categoryID, code).Scan(&res); err != nil {
+		r.logger(ctx).Error("IsAttributeCodeUsedInBranch: error with QueryRow", zap.Error(err))
+		return false, err
+	}
+	return res, nil
+}
+
+const queryCreateCategoryAttribute = `
+INSERT INTO category_attributes
+	(category_id, code, name, value_type, is_required, options)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+RETURNING id;
+`
+
+func (r *Repository) CreateCategoryAttribute(ctx context.Context, attr *entities.CategoryAttribute) error {
+	if attr.Options == nil {
+		attr.Options = []string{}
+	}
+	if err := r.conn(ctx, "CreateCategoryAttribute").QueryRow(
+		ctx,
+		queryCreateCategoryAttribute,
+		attr.CategoryID,
+		attr.Code,
+		attr.Name,
+		attr.ValueType,
+		attr.IsRequired,
+		attr.Options,
+	).Scan(&attr.ID); err != nil {
+		r.logger(ctx).Error("CreateCategoryAttribute: error with INSERT INTO", zap.Error(err))
+		return mapPgError(err)
+	}
+	return nil
+}
+
+const queryGetAdvertismentIDsByAttribute = `
+SELECT advertisement_id
+FROM advertisement_attributes
+WHERE attribute_id = $1;
+`
+
+// GetAdvertismentIDsByAttribute возвращает id объявлений со значением атрибута
+func (r *Repository) GetAdvertismentIDsByAttribute(ctx context.Context, attributeID uint64) ([]uint64, error) {
+	return r.getAdvertismentIDs(ctx, "GetAdvertismentIDsByAttribute", queryGetAdvertismentIDsByAttribute, attributeID)
+}
+
+const queryDeleteCategoryAttribute = `
+DELETE FROM category_attributes
+WHERE id = $1;
+`
+
+func (r *Repository) DeleteCategoryAttribute(ctx context.Context, attributeID uint64) error {
+	result, err := r.conn(ctx, "DeleteCategoryAttribute").Exec(ctx, queryDeleteCategoryAttribute, attributeID)
+	if err != nil {
+		r.logger(ctx).Error("DeleteCategoryAttribute: error with DELETE", zap.Error(err))
+		return mapPgError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+const queryGetAdvertismentAttributes = `
+SELECT
+	ca.id,
+	ca.code,
+	ca.name,
+	aa.value
+FROM advertisement_attributes aa
+	JOIN category_attributes ca ON aa.attribute_id = ca.id
+WHERE aa.advertisement_id = $1
+ORDER BY ca.id;
+`
+
+func (r *Repository) GetAdvertismentAttributes(ctx context.Context, advertisment *entities.Advertisment) error {
+	rows, err := r.readConn(ctx, "GetAdvertismentAttributes").Query(ctx, queryGetAdvertismentAttributes, advertisment.ID)
+	if err != nil {
+		r.logger(ctx).Error("GetAdvertismentAttributes: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attr entities.AdvertismentAttribute
+		if err := rows.Scan(
+			&attr.AttributeID,
+			&attr.Code,
+			&attr.Name,
+			&attr.Value,
+		); err != nil {
+			r.logger(ctx).Error("GetAdvertismentAttributes: error with scan row", zap.Error(err))
+			return err
+		}
+		advertisment.Attributes = append(advertisment.Attributes, attr)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetAdvertismentAttributes: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryDeleteAdvertismentAttributes = `
+DELETE FROM advertisement_attributes
+WHERE advertisement_id = $1;
+`
+
+const queryInsertAdvertismentAttribute = `
+INSERT INTO advertisement_attributes
+	(advertisement_id, attribute_id, value, value_num)
+VALUES
+	($1, $2, $3, $4);
+`
+
+// SetAdvertismentAttributes заменяет все значения атрибутов объявления
+func (r *Repository) SetAdvertismentAttributes(ctx context.Context, advertisment *entities.Advertisment) error {
+	if _, err := r.conn(ctx, "SetAdvertismentAttributes").Exec(ctx, queryDeleteAdvertismentAttributes, advertisment.ID); err != nil {
+		r.logger(ctx).Error("SetAdvertismentAttributes: error with DELETE", zap.Error(err))
+		return err
+	}
+	for _, attr := range advertisment.Attributes {
+		if _, err := r.conn(ctx, "SetAdvertismentAttributes").Exec(
+			ctx,
+			queryInsertAdvertismentAttribute,
+			advertisment.ID,
+			attr.AttributeID,
+			attr.Value,
+			attr.NumValue,
+		); err != nil {
+			r.logger(ctx).Error("SetAdvertismentAttributes: error with INSERT INTO", zap.Error(err))
+			return mapPgError(err)
+		}
+	}
+	return nil
+}
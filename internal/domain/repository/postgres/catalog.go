@@ -0,0 +1,367 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrDuplicate - нарушение уникальности (23505)
+	ErrDuplicate = errors.New("duplicate value")
+	// ErrReferenced - на запись ссылаются другие таблицы (23503)
+	ErrReferenced = errors.New("referenced by other rows")
+	ErrNotFound   = errors.New("not found")
+)
+
+func mapPgError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return ErrDuplicate
+		case "23503":
+			return ErrReferenced
+		}
+	}
+	return err
+}
+
+const queryGetCategories = `
+SELECT
+	c.id,
+	c.name,
+	c.is_active,
+	COALESCE(c.parent_id, 0)
+FROM categories_product c
+WHERE c.is_active OR NOT $1
+ORDER BY c.name;
+`
+
+func (r *Repository) GetCategories(ctx context.Context, onlyActive bool, categories *[]*entities.AdvertismentCategory) error {
+	rows, err := r.conn(ctx, "GetCategories").Query(ctx, queryGetCategories, onlyActive)
+	if err != nil {
+		r.logger(ctx).Error("GetCategories: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		category := entities.AdvertismentCategory{}
+		if err := rows.Scan(
+			&category.ID,
+			&category.Name,
+			&category.IsActive,
+			&category.ParentID,
+		); err != nil {
+			r.logger(ctx).Error("GetCategories: error with scan row", zap.Error(err))
+			return err
+		}
+		*categories = append(*categories, &category)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetCategories: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryCreateCategory = `
+INSERT INTO categories_product
+	(name, is_active, parent_id)
+VALUES
+	($1, $2, $3)
+RETURNING id;
+`
+
+func (r *Repository) CreateCategory(ctx context.Context, category *entities.AdvertismentCategory) error {
+	if err := r.conn(ctx, "CreateCategory").QueryRow(ctx, queryCreateCategory, category.Name, category.IsActive, nullableID(category.ParentID)).Scan(&category.ID); err != nil {
+		r.logger(ctx).Error("CreateCategory: error with INSERT INTO", zap.Error(err))
+		return mapPgError(err)
+	}
+	return nil
+}
+
+const queryUpdateCategory = `
+UPDATE categories_product
+SET name = $2,
+	is_active = $3,
+	parent_id = $4
+WHERE id = $1;
+`
+
+func (r *Repository) UpdateCategory(ctx context.Context, category *entities.AdvertismentCategory) error {
+	result, err := r.conn(ctx, "UpdateCategory").Exec(ctx, queryUpdateCategory, category.ID, category.Name, category.IsActive, nullableID(category.ParentID))
+	if err != nil {
+		r.logger(ctx).Error("UpdateCategory: error with UPDATE", zap.Error(err))
+		return mapPgError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// nullableID: 0 означает отсутствие ссылки
+func nullableID(id uint64) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+const queryGetCategory = `
+SELECT EXISTS (SELECT id
+FROM categories_product
+WHERE id = $1);
+`
+
+func (r *Repository) IsCategoryExist(ctx context.Context, categoryID uint64) (bool, error) {
+	var res bool
+	if err := r.conn(ctx, "IsCategoryExist").QueryRow(ctx, queryGetCategory, categoryID).Scan(&res); err != nil {
+		r.logger(ctx).Error("IsCategoryExist: error with QueryRow", zap.Error(err))
+		return false, err
+	}
+	return res, nil
+}
+
+const queryIsCategoryActive = `
+SELECT EXISTS (SELECT id
+FROM categories_product
+WHERE id = $1 AND is_active);
+`
+
+func (r *Repository) IsCategoryActive(ctx context.Context, categoryID uint64) (bool, error) {
+	var res bool
+	if err := r.conn(ctx, "IsCategoryActive").QueryRow(ctx, queryIsCategoryActive, categoryID).Scan(&res); err != nil {
+		r.logger(ctx).Error("IsCategoryActive: error with QueryRow", zap.Error(err))
+		return false, err
+	}
+	return res, nil
+}
+
+// Входит ли candidateID в поддерево rootID (включая сам rootID)
+const queryIsCategoryInSubtree = `
+WITH RECURSIVE subtree AS (
+	SELECT id
+	FROM categories_product
+	WHERE id = $1
+	UNION ALL
+	SELECT c.id
+	FROM categories_product c
+		JOIN subtree s ON c.parent_id = s.id
+)
+SELECT EXISTS (SELECT id FROM subtree WHERE id = $2);
+`
+
+func (r *Repository) IsCategoryInSubtree(ctx context.Context, rootID, candidateID uint64) (bool, error) {
+	var res bool
+	if err := r.conn(ctx, "IsCategoryInSubtree").QueryRow(ctx, queryIsCategoryInSubtree, rootID, candidateID).Scan(&res); err != nil {
+		r.logger(ctx).Error("IsCategoryInSubtree: error with QueryRow", zap.Error(err))
+		return false, err
+	}
+	return res, nil
+}
+
+const queryCountAdsByCategory = `
+SELECT COUNT(*)
+FROM advertisements
+WHERE category_id = $1;
+`
+
+func (r *Repository) CountAdvertismentsByCategory(ctx context.Context, categoryID uint64) (uint64, error) {
+	var count uint64
+	if err := r.conn(ctx, "CountAdvertismentsByCategory").QueryRow(ctx, queryCountAdsByCategory, categoryID).Scan(&count); err != nil {
+		r.logger(ctx).Error("CountAdvertismentsByCategory: error with QueryRow", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+const queryGetAdvertismentIDsByCategory = `
+SELECT id
+FROM advertisements
+WHERE category_id = $1;
+`
+
+// GetAdvertismentIDsByCategory возвращает id объявлений категории: ее
+// название показывается в карточке
+func (r *Repository) GetAdvertismentIDsByCategory(ctx context.Context, categoryID uint64) ([]uint64, error) {
+	return r.getAdvertismentIDs(ctx, "GetAdvertismentIDsByCategory", queryGetAdvertismentIDsByCategory, categoryID)
+}
+
+const queryDeleteCategory = `
+DELETE FROM categories_product
+WHERE id = $1;
+`
+
+func (r *Repository) DeleteCategory(ctx context.Context, categoryID uint64) error {
+	result, err := r.conn(ctx, "DeleteCategory").Exec(ctx, queryDeleteCategory, categoryID)
+	if err != nil {
+		r.logger(ctx).Error("DeleteCategory: error with DELETE", zap.Error(err))
+		return mapPgError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+const queryGetPromotionTypes = `
+SELECT
+	tp.id,
+	tp.name,
+	tp.price,
+	tp.time_live,
+	tp.is_active,
+	tp.verified_only
+FROM types_promotion tp
+WHERE tp.is_active OR NOT $1
+ORDER BY tp.price;
+`
+
+func (r *Repository) GetPromotionTypes(ctx context.Context, onlyActive bool, types *[]*entities.TypePromotion) error {
+	rows, err := r.conn(ctx, "GetPromotionTypes").Query(ctx, queryGetPromotionTypes, onlyActive)
+	if err != nil {
+		r.logger(ctx).Error("GetPromotionTypes: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		tp := entities.TypePromotion{}
+		if err := rows.Scan(
+			&tp.ID,
+			&tp.Name,
+			&tp.Price,
+			&tp.TimeLive,
+			&tp.IsActive,
+			&tp.VerifiedOnly,
+		); err != nil {
+			r.logger(ctx).Error("GetPromotionTypes: error with scan row", zap.Error(err))
+			return err
+		}
+		*types = append(*types, &tp)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetPromotionTypes: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryCreatePromotionType = `
+INSERT INTO types_promotion
+	(name, price, time_live, is_active, verified_only)
+VALUES
+	($1, $2, $3, $4, $5)
+RETURNING id;
+`
+
+func (r *Repository) CreatePromotionType(ctx context.Context, tp *entities.TypePromotion) error {
+	if err := r.conn(ctx, "CreatePromotionType").QueryRow(ctx, queryCreatePromotionType, tp.Name, tp.Price, tp.TimeLive, tp.IsActive, tp.VerifiedOnly).Scan(&tp.ID); err != nil {
+		r.logger(ctx).Error("CreatePromotionType: error with INSERT INTO", zap.Error(err))
+		return mapPgError(err)
+	}
+	return nil
+}
+
+const queryUpdatePromotionType = `
+UPDATE types_promotion
+SET name = $2,
+	price = $3,
+	time_live = $4,
+	is_active = $5,
+	verified_only = $6
+WHERE id = $1;
+`
+
+func (r *Repository) UpdatePromotionType(ctx context.Context, tp *entities.TypePromotion) error {
+	result, err := r.conn(ctx, "UpdatePromotionType").Exec(ctx, queryUpdatePromotionType, tp.ID, tp.Name, tp.Price, tp.TimeLive, tp.IsActive, tp.VerifiedOnly)
+	if err != nil {
+		r.logger(ctx).Error("UpdatePromotionType: error with UPDATE", zap.Error(err))
+		return mapPgError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+const queryGetPromotionType = `
+SELECT
+	tp.name,
+	tp.price,
+	tp.time_live,
+	tp.is_active,
+	tp.verified_only
+FROM types_promotion tp
+WHERE tp.id = $1;
+`
+
+func (r *Repository) GetPromotionType(ctx context.Context, tp *entities.TypePromotion) error {
+	if err := r.conn(ctx, "GetPromotionType").QueryRow(ctx, queryGetPromotionType, tp.ID).Scan(
+		&tp.Name,
+		&tp.Price,
+		&tp.TimeLive,
+		&tp.IsActive,
+		&tp.VerifiedOnly,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		r.logger(ctx).Error("GetPromotionType: error with QueryRow", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryCountAdsByPromotionType = `
+SELECT COUNT(*)
+FROM advertisements
+WHERE type_id = $1;
+`
+
+func (r *Repository) CountAdvertismentsByPromotionType(ctx context.Context, typeID uint64) (uint64, error) {
+	var count uint64
+	if err := r.conn(ctx, "CountAdvertismentsByPromotionType").QueryRow(ctx, queryCountAdsByPromotionType, typeID).Scan(&count); err != nil {
+		r.logger(ctx).Error("CountAdvertismentsByPromotionType: error with QueryRow", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+const queryGetAdvertismentIDsByPromotionType = `
+SELECT id
+FROM advertisements
+WHERE type_id = $1;
+`
+
+// GetAdvertismentIDsByPromotionType возвращает id объявлений с типом
+// продвижения: его название, цена и срок показываются в карточке
+func (r *Repository) GetAdvertismentIDsByPromotionType(ctx context.Context, typeID uint64) ([]uint64, error) {
+	return r.getAdvertismentIDs(ctx, "GetAdvertismentIDsByPromotionType", queryGetAdvertismentIDsByPromotionType, typeID)
+}
+
+const queryDeletePromotionType = `
+DELETE FROM types_promotion
+WHERE id = $1;
+`
+
+func (r *Repository) DeletePromotionType(ctx context.Context, typeID uint64) error {
+	result, err := r.conn(ctx, "DeletePromotionType").Exec(ctx, queryDeletePromotionType, typeID)
+	if err != nil {
+		r.logger(ctx).Error("DeletePromotionType: error with DELETE", zap.Error(err))
+		return mapPgError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+	"math"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// kmPerDegree - длина градуса широты
+const kmPerDegree = 111.045
+
+// Координаты объявления: своя точка или центр города
+const (
+	adLatitude  = "COALESCE(a.latitude, ci.latitude)"
+	adLongitude = "COALESCE(a.longitude, ci.longitude)"
+)
+
+// distanceExpr - SQL-выражение расстояния в км от объявления до точки lat/lon
+// (плейсхолдеры). С PostGIS считается по геоиду, без него - по гаверсинусу.
+func distanceExpr(lat, lon string, postgis bool) string {
+	if postgis {
+		return "ST_Distance(ST_MakePoint(" + adLongitude + ", " + adLatitude + ")::geography, ST_MakePoint(" + lon + ", " + lat + ")::geography) / 1000"
+	}
+	// 6371 - средний радиус Земли в км
+	return "2 * 6371 * asin(sqrt(" +
+		"power(sin(radians(" + adLatitude + " - " + lat + ") / 2), 2) + " +
+		"cos(radians(" + lat + ")) * cos(radians(" + adLatitude + ")) * " +
+		"power(sin(radians(" + adLongitude + " - " + lon + ") / 2), 2)))"
+}
+
+// withinRadius добавляет условие "не дальше radiusKm от точки"
+func (q *searchQuery) withinRadius(lat, lon float64, radiusKm float64, distance string, postgis bool) {
+	if postgis {
+		q.where = append(q.where, "ST_DWithin(ST_MakePoint("+adLongitude+", "+adLatitude+")::geography, ST_MakePoint("+
+			q.arg(lon)+", "+q.arg(lat)+")::geography, "+q.arg(radiusKm*1000)+")")
+		return
+	}
+	// грубый отсев по широте, чтобы не считать формулу для всей таблицы
+	delta := radiusKm / kmPerDegree
+	q.where = append(q.where,
+		adLatitude+" BETWEEN "+q.arg(math.Max(lat-delta, -90))+" AND "+q.arg(math.Min(lat+delta, 90)),
+		distance+" <= "+q.arg(radiusKm),
+	)
+}
+
+const queryGetCities = `
+SELECT
+	id,
+	name,
+	region,
+	latitude,
+	longitude
+FROM cities
+WHERE lower(name) LIKE $1
+ORDER BY name, region
+LIMIT $2;
+`
+
+// GetCities ищет города по началу названия
+func (r *Repository) GetCities(ctx context.Context, prefix string, limit uint64, cities *[]*entities.City) error {
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(prefix)) + "%"
+	rows, err := r.conn(ctx, "GetCities").Query(ctx, queryGetCities, pattern, limit)
+	if err != nil {
+		r.logger(ctx).Error("GetCities: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		city := entities.City{}
+		if err := rows.Scan(
+			&city.ID,
+			&city.Name,
+			&city.Region,
+			&city.Latitude,
+			&city.Longitude,
+		); err != nil {
+			r.logger(ctx).Error("GetCities: error with scan row", zap.Error(err))
+			return err
+		}
+		*cities = append(*cities, &city)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetCities: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryCreateCity = `
+INSERT INTO cities
+	(name, region, latitude, longitude)
+VALUES
+	($1, $2, $3, $4)
+RETURNING id;
+`
+
+func (r *Repository) CreateCity(ctx context.Context, city *entities.City) error {
+	if err := r.conn(ctx, "CreateCity").QueryRow(ctx, queryCreateCity, city.Name, city.Region, city.Latitude, city.Longitude).Scan(&city.ID); err != nil {
+		r.logger(ctx).Error("CreateCity: error with INSERT INTO", zap.Error(err))
+		return mapPgError(err)
+	}
+	return nil
+}
+
+const queryIsCityExist = `
+SELECT EXISTS (SELECT id
+FROM cities
+WHERE id = $1);
+`
+
+func (r *Repository) IsCityExist(ctx context.Context, cityID uint64) (bool, error) {
+	var res bool
+	if err := r.conn(ctx, "IsCityExist").QueryRow(ctx, queryIsCityExist, cityID).Scan(&res); err != nil {
+		r.logger(ctx).Error("IsCityExist: error with QueryRow", zap.Error(err))
+		return false, err
+	}
+	return res, nil
+}
@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const queryHideAdvertisment = `
+UPDATE advertisements
+SET is_hidden = $2
+WHERE id = $1;
+`
+
+func (r *Repository) HideAdvertisment(ctx context.Context, adID uint64, hidden bool) error {
+	result, err := r.conn(ctx, "HideAdvertisment").Exec(ctx, queryHideAdvertisment, adID, hidden)
+	if err != nil {
+		r.logger(ctx).Error("HideAdvertisment: error with UPDATE", zap.Error(err))
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("no rows affected, advertisment %d may not exist", adID)
+	}
+	return nil
+}
+
+const queryGetReview = `
+SELECT EXISTS (SELECT id
+FROM reviews
+WHERE id = $1);
+`
+
+func (r *Repository) IsReviewExist(ctx context.Context, reviewID uint64) (bool, error) {
+	var res bool
+	err := r.conn(ctx, "IsReviewExist").QueryRow(ctx, queryGetReview, reviewID).Scan(&res)
+	if err != nil {
+		r.logger(ctx).Error("IsReviewExist: error with QueryRow", zap.Error(err))
+		return false, err
+	}
+	return res, nil
+}
+
+const queryGetReviewTarget = `
+SELECT r.advertisement_id, a.user_id
+FROM reviews r
+JOIN advertisements a ON a.id = r.advertisement_id
+WHERE r.id = $1;
+`
+
+// GetReviewTarget возвращает объявление отзыва и его продавца
+func (r *Repository) GetReviewTarget(ctx context.Context, reviewID uint64) (adID, sellerID uint64, err error) {
+	if err = r.conn(ctx, "GetReviewTarget").QueryRow(ctx, queryGetReviewTarget, reviewID).Scan(&adID, &sellerID); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, 0, ErrNotFound
+		}
+		r.logger(ctx).Error("GetReviewTarget: error with QueryRow", zap.Error(err))
+		return 0, 0, err
+	}
+	return adID, sellerID, nil
+}
+
+const queryHideReview = `
+UPDATE reviews
+SET is_hidden = $2
+WHERE id = $1;
+`
+
+func (r *Repository) HideReview(ctx context.Context, reviewID uint64, hidden bool) error {
+	result, err := r.conn(ctx, "HideReview").Exec(ctx, queryHideReview, reviewID, hidden)
+	if err != nil {
+		r.logger(ctx).Error("HideReview: error with UPDATE", zap.Error(err))
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("no rows affected, review %d may not exist", reviewID)
+	}
+	return nil
+}
+
+const queryGetModerationQueue = `
+SELECT
+	a.id,
+	a.user_id,
+	a.category_id,
+	a.name,
+	COALESCE(a.description, ''),
+	a.price,
+	a.date_placement,
+	a.moderation_flags
+FROM advertisements a
+WHERE a.moderation_status = 'pending'
+	AND a.status = 'active'
+	AND NOT a.is_hidden
+ORDER BY cardinality(a.moderation_flags) > 0 DESC, a.date_placement, a.id
+LIMIT $1 OFFSET $2;
+`
+
+// GetModerationQueue: сначала объявления с флагами автопроверки, затем по времени подачи
+func (r *Repository) GetModerationQueue(ctx context.Context, limit, offset uint64, items *[]*entities.ModerationQueueItem) error {
+	rows, err := r.conn(ctx, "GetModerationQueue").Query(ctx, queryGetModerationQueue, limit, offset)
+	if err != nil {
+		r.logger(ctx).Error("GetModerationQueue: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := entities.ModerationQueueItem{}
+		if err := rows.Scan(
+			&item.AdID,
+			&item.UserID,
+			&item.CategoryID,
+			&item.AdName,
+			&item.AdDescription,
+			&item.AdPrice,
+			&item.AdDatePlacement,
+			&item.Flags,
+		); err != nil {
+			r.logger(ctx).Error("GetModerationQueue: error with scan row", zap.Error(err))
+			return err
+		}
+		*items = append(*items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetModerationQueue: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const querySetAdvertismentModeration = `
+UPDATE advertisements
+SET moderation_status = $2,
+	moderation_reason = $3,
+	moderated_by = $4,
+	moderated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+	AND moderation_status = 'pending';
+`
+
+// SetAdvertismentModeration принимает решение только по объявлению из очереди.
+// События пишутся в outbox той же транзакцией.
+func (r *Repository) SetAdvertismentModeration(ctx context.Context, adID, moderatorID uint64, status, reason string, events ...entities.OutboxEvent) error {
+	return r.WithTx(ctx, func(ctx context.Context) error {
+		result, err := r.conn(ctx, "SetAdvertismentModeration").Exec(
+			ctx,
+			querySetAdvertismentModeration,
+			adID,
+			status,
+			entities.NewNullString(reason),
+			moderatorID,
+		)
+		if err != nil {
+			r.logger(ctx).Error("SetAdvertismentModeration: error with UPDATE", zap.Error(err))
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		return r.insertOutboxEvents(ctx, events)
+	})
+}
+
+const queryGetCategoryPriceMedian = `
+SELECT
+	COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY a.price), 0),
+	COUNT(*)
+FROM advertisements a
+WHERE a.category_id = $1
+	AND a.id <> $2
+	AND a.moderation_status = 'approved'
+	AND a.status <> 'deleted'
+	AND NOT a.is_hidden;
+`
+
+func (r *Repository) GetCategoryPriceMedian(ctx context.Context, categoryID, excludeAdID uint64) (float64, uint64, error) {
+	var median float64
+	var count uint64
+	if err := r.conn(ctx, "GetCategoryPriceMedian").QueryRow(ctx, queryGetCategoryPriceMedian, categoryID, excludeAdID).Scan(&median, &count); err != nil {
+		r.logger(ctx).Error("GetCategoryPriceMedian: error with QueryRow", zap.Error(err))
+		return 0, 0, err
+	}
+	return median, count, nil
+}
+
+const queryHasDuplicatePhotos = `
+SELECT EXISTS (SELECT ph.id
+FROM ad_photos ph
+WHERE ph.path = ANY($2)
+	AND ph.advertisement_id <> $1);
+`
+
+// HasDuplicatePhotos: используется ли один из путей в другом объявлении
+func (r *Repository) HasDuplicatePhotos(ctx context.Context, adID uint64, paths []string) (bool, error) {
+	var res bool
+	if err := r.conn(ctx, "HasDuplicatePhotos").QueryRow(ctx, queryHasDuplicatePhotos, adID, paths).Scan(&res); err != nil {
+		r.logger(ctx).Error("HasDuplicatePhotos: error with QueryRow", zap.Error(err))
+		return false, err
+	}
+	return res, nil
+}
@@ -0,0 +1,330 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Вместе с уведомлением создаются отправки во внешние каналы, кроме
+// выключенных пользователем для этого типа событий
+const queryCreateNotification = `
+WITH created AS (
+	INSERT INTO notifications
+		(user_id, kind, title, body, payload, dedup_key)
+	VALUES
+		($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (user_id, dedup_key) DO NOTHING
+	RETURNING id, created_at
+), deliveries AS (
+	INSERT INTO notification_deliveries
+		(notification_id, channel)
+	SELECT created.id, ch
+	FROM created, unnest($7::text[]) ch
+	WHERE NOT EXISTS (SELECT 1
+		FROM notification_preferences p
+		WHERE p.user_id = $1
+			AND p.kind = $2
+			AND p.channel = ch
+			AND NOT p.enabled)
+)
+SELECT id, created_at
+FROM created;
+`
+
+// CreateNotification возвращает false, если уведомление с тем же
+// DedupKey у пользователя уже есть
+func (r *Repository) CreateNotification(ctx context.Context, notification *entities.Notification, channels []string) (bool, error) {
+	payload, err := json.Marshal(notification.Payload)
+	if err != nil {
+		return false, err
+	}
+	if notification.Payload == nil {
+		payload = []byte("{}")
+	}
+	if err = r.conn(ctx, "CreateNotification").QueryRow(
+		ctx,
+		queryCreateNotification,
+		notification.UserID,
+		notification.Kind,
+		notification.Title,
+		notification.Body,
+		payload,
+		notification.DedupKey,
+		channels,
+	).Scan(
+		&notification.ID,
+		&notification.CreatedAt,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		r.logger(ctx).Error("CreateNotification: error with INSERT", zap.Error(err))
+		return false, mapPgError(err)
+	}
+	return true, nil
+}
+
+const queryGetNotifications = `
+SELECT
+	id,
+	user_id,
+	kind,
+	title,
+	body,
+	payload,
+	created_at,
+	read_at
+FROM notifications
+WHERE user_id = $1
+	AND (NOT $2 OR read_at IS NULL)
+ORDER BY created_at DESC, id DESC
+LIMIT $3 OFFSET $4;
+`
+
+func (r *Repository) GetNotifications(ctx context.Context, uID uint64, unreadOnly bool, limit, offset uint64, notifications *[]*entities.Notification) error {
+	rows, err := r.conn(ctx, "GetNotifications").Query(ctx, queryGetNotifications, uID, unreadOnly, limit, offset)
+	if err != nil {
+		r.logger(ctx).Error("GetNotifications: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		notification := entities.Notification{}
+		var payload []byte
+		if err := rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.Kind,
+			&notification.Title,
+			&notification.Body,
+			&payload,
+			&notification.CreatedAt,
+			&notification.ReadAt,
+		); err != nil {
+			r.logger(ctx).Error("GetNotifications: error with scan row", zap.Error(err))
+			return err
+		}
+		if err := json.Unmarshal(payload, &notification.Payload); err != nil {
+			r.logger(ctx).Error("GetNotifications: error with unmarshal payload", zap.Error(err))
+			return err
+		}
+		*notifications = append(*notifications, &notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetNotifications: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryCountUnreadNotifications = `
+SELECT COUNT(*)
+FROM notifications
+WHERE user_id = $1
+	AND read_at IS NULL;
+`
+
+func (r *Repository) CountUnreadNotifications(ctx context.Context, uID uint64) (uint64, error) {
+	var count uint64
+	if err := r.conn(ctx, "CountUnreadNotifications").QueryRow(ctx, queryCountUnreadNotifications, uID).Scan(&count); err != nil {
+		r.logger(ctx).Error("CountUnreadNotifications: error with QueryRow", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+const queryMarkNotificationsRead = `
+UPDATE notifications
+SET read_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+	AND read_at IS NULL
+	AND ($2::bigint[] IS NULL OR id = ANY($2));
+`
+
+// MarkNotificationsRead отмечает прочитанными уведомления ids, при nil - все
+func (r *Repository) MarkNotificationsRead(ctx context.Context, uID uint64, ids []uint64) (int64, error) {
+	result, err := r.conn(ctx, "MarkNotificationsRead").Exec(ctx, queryMarkNotificationsRead, uID, ids)
+	if err != nil {
+		r.logger(ctx).Error("MarkNotificationsRead: error with UPDATE", zap.Error(err))
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const queryGetNotificationPreferences = `
+SELECT
+	kind,
+	channel,
+	enabled
+FROM notification_preferences
+WHERE user_id = $1;
+`
+
+// GetNotificationPreferences возвращает только явно заданные настройки
+func (r *Repository) GetNotificationPreferences(ctx context.Context, uID uint64, prefs *[]*entities.NotificationPreference) error {
+	rows, err := r.conn(ctx, "GetNotificationPreferences").Query(ctx, queryGetNotificationPreferences, uID)
+	if err != nil {
+		r.logger(ctx).Error("GetNotificationPreferences: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		pref := entities.NotificationPreference{}
+		if err := rows.Scan(&pref.Kind, &pref.Channel, &pref.Enabled); err != nil {
+			r.logger(ctx).Error("GetNotificationPreferences: error with scan row", zap.Error(err))
+			return err
+		}
+		*prefs = append(*prefs, &pref)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetNotificationPreferences: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const querySetNotificationPreference = `
+INSERT INTO notification_preferences
+	(user_id, kind, channel, enabled)
+VALUES
+	($1, $2, $3, $4)
+ON CONFLICT (user_id, kind, channel) DO UPDATE
+SET enabled = EXCLUDED.enabled;
+`
+
+func (r *Repository) SetNotificationPreference(ctx context.Context, uID uint64, pref *entities.NotificationPreference) error {
+	if _, err := r.conn(ctx, "SetNotificationPreference").Exec(ctx, querySetNotificationPreference, uID, pref.Kind, pref.Channel, pref.Enabled); err != nil {
+		r.logger(ctx).Error("SetNotificationPreference: error with INSERT", zap.Error(err))
+		return mapPgError(err)
+	}
+	return nil
+}
+
+// Берет готовые к отправке и сразу откладывает их на retryDelay * номер
+// попытки: если воркер упадет посреди отправки, она повторится позже
+const queryTakeNotificationDeliveries = `
+WITH due AS (
+	SELECT id
+	FROM notification_deliveries
+	WHERE status = 'pending'
+		AND next_attempt_at <= CURRENT_TIMESTAMP
+	ORDER BY next_attempt_at
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED
+)
+UPDATE notification_deliveries d
+SET attempts = d.attempts + 1,
+	next_attempt_at = CURRENT_TIMESTAMP + $2::interval * (d.attempts + 1)
+FROM due, notifications n
+WHERE d.id = due.id
+	AND n.id = d.notification_id
+RETURNING d.id, d.channel, d.attempts, n.id, n.user_id, n.kind, n.title, n.body;
+`
+
+func (r *Repository) TakeNotificationDeliveries(ctx context.Context, limit int, retryDelay time.Duration, deliveries *[]*entities.NotificationDelivery) error {
+	rows, err := r.conn(ctx, "TakeNotificationDeliveries").Query(ctx, queryTakeNotificationDeliveries, limit, retryDelay)
+	if err != nil {
+		r.logger(ctx).Error("TakeNotificationDeliveries: error with UPDATE", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery := entities.NotificationDelivery{}
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.Channel,
+			&delivery.Attempts,
+			&delivery.Notification.ID,
+			&delivery.Notification.UserID,
+			&delivery.Notification.Kind,
+			&delivery.Notification.Title,
+			&delivery.Notification.Body,
+		); err != nil {
+			r.logger(ctx).Error("TakeNotificationDeliveries: error with scan row", zap.Error(err))
+			return err
+		}
+		*deliveries = append(*deliveries, &delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("TakeNotificationDeliveries: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const querySetNotificationDeliveryStatus = `
+UPDATE notification_deliveries
+SET status = $2,
+	last_error = $3,
+	sent_at = CASE WHEN $2 = 'sent' THEN CURRENT_TIMESTAMP END
+WHERE id = $1;
+`
+
+// SetNotificationDeliveryStatus: sent, failed или pending для повтора
+func (r *Repository) SetNotificationDeliveryStatus(ctx context.Context, deliveryID uint64, status, lastError string) error {
+	if _, err := r.conn(ctx, "SetNotificationDeliveryStatus").Exec(ctx, querySetNotificationDeliveryStatus, deliveryID, status, entities.NewNullString(lastError)); err != nil {
+		r.logger(ctx).Error("SetNotificationDeliveryStatus: error with UPDATE", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Отмечает на объявлении срок продвижения, о котором предупредили, чтобы
+// продление (новый срок) снова дало уведомление
+const queryTakeExpiringPromotions = `
+WITH due AS (
+	SELECT id
+	FROM advertisements
+	WHERE status = 'active'
+		AND date_expire_promotion BETWEEN CURRENT_TIMESTAMP AND CURRENT_TIMESTAMP + $1::interval
+		AND promotion_expiry_notified_for IS DISTINCT FROM date_expire_promotion
+	ORDER BY date_expire_promotion
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED
+)
+UPDATE advertisements a
+SET promotion_expiry_notified_for = a.date_expire_promotion
+FROM due
+WHERE a.id = due.id
+RETURNING a.id, a.user_id, a.name, a.date_expire_promotion;
+`
+
+func (r *Repository) TakeExpiringPromotions(ctx context.Context, notice time.Duration, limit int, ads *[]*entities.Advertisment) error {
+	rows, err := r.conn(ctx, "TakeExpiringPromotions").Query(ctx, queryTakeExpiringPromotions, notice, limit)
+	if err != nil {
+		r.logger(ctx).Error("TakeExpiringPromotions: error with UPDATE", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		ad := entities.Advertisment{}
+		if err := rows.Scan(
+			&ad.ID,
+			&ad.User.ID,
+			&ad.Name,
+			&ad.DateExpirePromotion,
+		); err != nil {
+			r.logger(ctx).Error("TakeExpiringPromotions: error with scan row", zap.Error(err))
+			return err
+		}
+		*ads = append(*ads, &ad)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("TakeExpiringPromotions: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const queryInsertOutboxEvent = `
+INSERT INTO outbox
+	(event_type, payload)
+VALUES
+	($1, $2);
+`
+
+// insertOutboxEvents пишет события в outbox. Вызывается внутри WithTx вместе
+// с изменением, к которому относятся события.
+func (r *Repository) insertOutboxEvents(ctx context.Context, events []entities.OutboxEvent) error {
+	for _, event := range events {
+		if _, err := r.conn(ctx, "insertOutboxEvents").Exec(ctx, queryInsertOutboxEvent, event.Type, []byte(event.Payload)); err != nil {
+			r.logger(ctx).Error("insertOutboxEvents: error with INSERT",
+				zap.String("event_type", event.Type),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+	return nil
+}
+
+// Строки остаются заблокированными до конца транзакции, поэтому несколько
+// инстансов разбирают outbox параллельно и не берут одно событие дважды
+const queryTakeOutboxEvents = `
+SELECT id, event_type, payload, attempts, created_at
+FROM outbox
+WHERE status = 'pending'
+	AND available_at <= CURRENT_TIMESTAMP
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+`
+
+const queryMarkOutboxProcessed = `
+UPDATE outbox
+SET status = 'processed',
+	attempts = attempts + 1,
+	last_error = NULL,
+	processed_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+`
+
+// Пауза перед повтором растет линейно с числом попыток
+const queryMarkOutboxFailed = `
+UPDATE outbox
+SET attempts = attempts + 1,
+	last_error = $2,
+	status = CASE WHEN attempts + 1 >= $3 THEN 'failed' ELSE 'pending' END,
+	available_at = CURRENT_TIMESTAMP + $4::interval * (attempts + 1)
+WHERE id = $1;
+`
+
+// ProcessOutbox берет до limit готовых событий и передает их handle. Результат
+// обработки фиксируется в той же транзакции, что держит блокировку: событие,
+// на котором процесс упал, вернется в очередь после отката. Поэтому доставка
+// at-least-once, и обработчики должны быть идемпотентны.
+// Каждый обработчик получает ctx с точкой сохранения этой транзакции: его
+// изменения фиксируются вместе с отметкой processed, а при ошибке откатываются.
+// Возвращает число взятых событий.
+func (r *Repository) ProcessOutbox(
+	ctx context.Context,
+	limit int,
+	maxAttempts int,
+	retryDelay time.Duration,
+	handle func(ctx context.Context, event *entities.OutboxEvent) error,
+) (int, error) {
+	taken := 0
+	err := r.WithTx(ctx, func(ctx context.Context) error {
+		rows, err := r.conn(ctx, "ProcessOutbox").Query(ctx, queryTakeOutboxEvents, limit)
+		if err != nil {
+			r.logger(ctx).Error("ProcessOutbox: error with SELECT", zap.Error(err))
+			return err
+		}
+		events := []*entities.OutboxEvent{}
+		for rows.Next() {
+			event := entities.OutboxEvent{}
+			if err := rows.Scan(
+				&event.ID,
+				&event.Type,
+				&event.Payload,
+				&event.Attempts,
+				&event.CreatedAt,
+			); err != nil {
+				rows.Close()
+				r.logger(ctx).Error("ProcessOutbox: error with scan row", zap.Error(err))
+				return err
+			}
+			events = append(events, &event)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			r.logger(ctx).Error("ProcessOutbox: error iterating through rows", zap.Error(err))
+			return err
+		}
+		taken = len(events)
+
+		for _, event := range events {
+			handleErr := r.WithTx(ctx, func(ctx context.Context) error {
+				return handle(ctx, event)
+			})
+			if handleErr != nil {
+				_, err = r.conn(ctx, "ProcessOutbox").Exec(ctx, queryMarkOutboxFailed, event.ID, handleErr.Error(), maxAttempts, retryDelay)
+			} else {
+				_, err = r.conn(ctx, "ProcessOutbox").Exec(ctx, queryMarkOutboxProcessed, event.ID)
+			}
+			if err != nil {
+				r.logger(ctx).Error("ProcessOutbox: error with UPDATE", zap.Uint64("event_id", event.ID), zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+	return taken, err
+}
@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeRows отдает заранее заданные строки. Scan раскладывает значения строки
+// по указателям, типы должны совпадать.
+type fakeRows struct {
+	pgx.Rows
+	rows [][]interface{}
+	cur  int
+}
+
+func (r *fakeRows) Next() bool {
+	r.cur++
+	return r.cur <= len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	for i, v := range r.rows[r.cur-1] {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+	}
+	return nil
+}
+
+func (r *fakeRows) Close()     {}
+func (r *fakeRows) Err() error { return nil }
+
+func outboxRow(id uint64, eventType string) []interface{} {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []interface{}{id, eventType, json.RawMessage(`{"ad_id":1}`), 0, &createdAt}
+}
+
+func TestProcessOutbox(t *testing.T) {
+	handlerErr := errors.New("handler failed")
+	updateErr := errors.New("update failed")
+	tests := []struct {
+		name string
+		rows [][]interface{}
+		// failEvent - событие, на котором обработчик вернет ошибку
+		failEvent uint64
+		execErr   func(sql string) error
+		wantTaken int
+		wantErr   error
+		// wantMarks - отметки событий: id и processed/failed
+		wantMarks  []string
+		wantEvents []string
+	}{
+		{
+			name:      "empty outbox",
+			wantTaken: 0,
+			wantEvents: []string{
+				"begin 1", "commit 1",
+			},
+		},
+		{
+			name:      "all events processed",
+			rows:      [][]interface{}{outboxRow(1, "a"), outboxRow(2, "b")},
+			wantTaken: 2,
+			wantMarks: []string{"1 processed", "2 processed"},
+			wantEvents: []string{
+				"begin 1",
+				"begin 2", "commit 2",
+				"begin 2", "commit 2",
+				"commit 1",
+			},
+		},
+		{
+			name:      "failed handler is rolled back and marked failed",
+			rows:      [][]interface{}{outboxRow(1, "a"), outboxRow(2, "b")},
+			failEvent: 1,
+			wantTaken: 2,
+			wantMarks: []string{"1 failed", "2 processed"},
+			wantEvents: []string{
+				"begin 1",
+				"begin 2", "rollback 2",
+				"begin 2", "commit 2",
+				"commit 1",
+			},
+		},
+		{
+			name: "mark error rolls back the batch",
+			rows: [][]interface{}{outboxRow(1, "a")},
+			execErr: func(sql string) error {
+				if sql == queryMarkOutboxProcessed {
+					return updateErr
+				}
+				return nil
+			},
+			wantTaken: 1,
+			wantErr:   updateErr,
+			wantEvents: []string{
+				"begin 1",
+				"begin 2", "commit 2",
+				"rollback 1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRepository()
+			journal := &txJournal{
+				execErr: tt.execErr,
+				query: func(sql string, args []interface{}) (pgx.Rows, error) {
+					if sql != queryTakeOutboxEvents || args[0] != 10 {
+						t.Fatalf("unexpected query %q %v", sql, args)
+					}
+					return &fakeRows{rows: tt.rows}, nil
+				},
+			}
+			taken, err := r.ProcessOutbox(inTx(context.Background(), journal), 10, 3, time.Minute,
+				func(ctx context.Context, event *entities.OutboxEvent) error {
+					// запись обработчика идет в его точку сохранения
+					if _, err := r.conn(ctx, "handler").Exec(ctx, "handler write", event.ID); err != nil {
+						return err
+					}
+					if event.ID == tt.failEvent {
+						return handlerErr
+					}
+					return nil
+				},
+			)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if taken != tt.wantTaken {
+				t.Errorf("taken = %d, want %d", taken, tt.wantTaken)
+			}
+			if !reflect.DeepEqual(journal.events, tt.wantEvents) {
+				t.Errorf("events = %v, want %v", journal.events, tt.wantEvents)
+			}
+			var marks []string
+			for _, e := range journal.execs {
+				switch e.sql {
+				case "handler write":
+					if e.depth != 2 {
+						t.Errorf("handler wrote at depth %d, want its own savepoint", e.depth)
+					}
+				case queryMarkOutboxProcessed:
+					marks = append(marks, fmt.Sprintf("%v processed", e.args[0]))
+				case queryMarkOutboxFailed:
+					marks = append(marks, fmt.Sprintf("%v failed", e.args[0]))
+					want := []interface{}{tt.failEvent, handlerErr.Error(), 3, time.Minute}
+					if !reflect.DeepEqual(e.args, want) {
+						t.Errorf("failed mark args = %v, want %v", e.args, want)
+					}
+				}
+			}
+			if !reflect.DeepEqual(marks, tt.wantMarks) {
+				t.Errorf("marks = %v, want %v", marks, tt.wantMarks)
+			}
+		})
+	}
+}
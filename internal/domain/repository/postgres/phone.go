@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const queryIsPhoneUsed = `
+SELECT EXISTS (SELECT id
+FROM users
+WHERE number_phone = $1 AND id <> $2);
+`
+
+// IsPhoneUsed: занят ли номер другим пользователем
+func (r *Repository) IsPhoneUsed(ctx context.Context, phone string, exceptUserID uint64) (bool, error) {
+	var res bool
+	if err := r.conn(ctx, "IsPhoneUsed").QueryRow(ctx, queryIsPhoneUsed, phone, exceptUserID).Scan(&res); err != nil {
+		r.logger(ctx).Error("IsPhoneUsed: error with QueryRow", zap.Error(err))
+		return false, err
+	}
+	return res, nil
+}
+
+const queryHasRecentPhoneCode = `
+SELECT EXISTS (SELECT id
+FROM phone_codes
+WHERE user_id = $1 AND created_at > CURRENT_TIMESTAMP - $2::interval);
+`
+
+func (r *Repository) HasRecentPhoneCode(ctx context.Context, userID uint64, interval time.Duration) (bool, error) {
+	var res bool
+	if err := r.conn(ctx, "HasRecentPhoneCode").QueryRow(ctx, queryHasRecentPhoneCode, userID, interval).Scan(&res); err != nil {
+		r.logger(ctx).Error("HasRecentPhoneCode: error with QueryRow", zap.Error(err))
+		return false, err
+	}
+	return res, nil
+}
+
+// Новый код отменяет все неиспользованные коды пользователя
+const queryCreatePhoneCode = `
+WITH old AS (
+	UPDATE phone_codes
+	SET consumed_at = CURRENT_TIMESTAMP
+	WHERE user_id = $1 AND consumed_at IS NULL
+)
+INSERT INTO phone_codes
+	(user_id, phone, code_hash, expires_at)
+VALUES
+	($1, $2, $3, CURRENT_TIMESTAMP + $4::interval)
+RETURNING id;
+`
+
+func (r *Repository) CreatePhoneCode(ctx context.Context, code *entities.PhoneCode, ttl time.Duration) error {
+	if err := r.conn(ctx, "CreatePhoneCode").QueryRow(
+		ctx,
+		queryCreatePhoneCode,
+		code.UserID,
+		code.Phone,
+		code.CodeHash,
+		ttl,
+	).Scan(&code.ID); err != nil {
+		r.logger(ctx).Error("CreatePhoneCode: error with INSERT", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryGetActivePhoneCode = `
+SELECT
+	id,
+	phone,
+	code_hash,
+	attempts,
+	expires_at <= CURRENT_TIMESTAMP
+FROM phone_codes
+WHERE user_id = $1 AND consumed_at IS NULL
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
+`
+
+func (r *Repository) GetActivePhoneCode(ctx context.Context, code *entities.PhoneCode) error {
+	if err := r.conn(ctx, "GetActivePhoneCode").QueryRow(ctx, queryGetActivePhoneCode, code.UserID).Scan(
+		&code.ID,
+		&code.Phone,
+		&code.CodeHash,
+		&code.Attempts,
+		&code.Expired,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		r.logger(ctx).Error("GetActivePhoneCode: error with QueryRow", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryIncPhoneCodeAttempts = `
+UPDATE phone_codes
+SET attempts = attempts + 1
+WHERE id = $1 AND consumed_at IS NULL AND attempts < $2
+RETURNING attempts;
+`
+
+// IncPhoneCodeAttempts учитывает попытку ввода. false - попытки кончились.
+func (r *Repository) IncPhoneCodeAttempts(ctx context.Context, code *entities.PhoneCode, maxAttempts int) (bool, error) {
+	if err := r.conn(ctx, "IncPhoneCodeAttempts").QueryRow(ctx, queryIncPhoneCodeAttempts, code.ID, maxAttempts).Scan(&code.Attempts); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		r.logger(ctx).Error("IncPhoneCodeAttempts: error with UPDATE", zap.Error(err))
+		return false, err
+	}
+	return true, nil
+}
+
+// Код гасится и номер сохраняется одним запросом; занятый номер дает 23505
+const queryConfirmPhone = `
+WITH code AS (
+	UPDATE phone_codes
+	SET consumed_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND consumed_at IS NULL
+	RETURNING user_id, phone
+)
+UPDATE users u
+SET number_phone = code.phone,
+	phone_confirmed_at = CURRENT_TIMESTAMP
+FROM code
+WHERE u.id = code.user_id
+RETURNING u.id;
+`
+
+func (r *Repository) ConfirmPhone(ctx context.Context, code *entities.PhoneCode) error {
+	var uID uint64
+	if err := r.conn(ctx, "ConfirmPhone").QueryRow(ctx, queryConfirmPhone, code.ID).Scan(&uID); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		r.logger(ctx).Error("ConfirmPhone: error with UPDATE", zap.Error(err))
+		return mapPgError(err)
+	}
+	return nil
+}
@@ -3,53 +3,171 @@ package postgres
 import (
 	"backend/config"
 	"backend/internal/domain/entities"
+	"backend/internal/logging"
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
 
-	"github.com/jackc/pgx/v4/pgxpool"
-	// "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
-	// "log"
-	// "strconv"
-	// "time"
 )
 
+// maxConnectRetryDelay - предел паузы между попытками подключения при старте
+const maxConnectRetryDelay = 10 * time.Second
+
 type Repository struct {
-	ctx context.Context
 	log *zap.Logger
 	cfg *config.ConfigModel
 	DB  *pgxpool.Pool
+	// replicas - nil, если реплики не заданы
+	replicas *replicaSet
 }
 
-func NewRepository(log *zap.Logger, cfg *config.ConfigModel, ctx context.Context) (*Repository, error) {
+func NewRepository(log *zap.Logger, cfg *config.ConfigModel) (*Repository, error) {
 	return &Repository{
-		ctx: ctx,
 		log: log,
 		cfg: cfg,
 	}, nil
 }
 
-func (r *Repository) OnStart(_ context.Context) error {
-	connectionUrl := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		r.cfg.Postgres.Host,
-		r.cfg.Postgres.Port,
-		r.cfg.Postgres.User,
-		r.cfg.Postgres.Password,
-		r.cfg.Postgres.DBName,
-		r.cfg.Postgres.SSLMode)
-	pool, err := pgxpool.Connect(r.ctx, connectionUrl)
+// OnStart подключается к базе, повторяя попытки с растущей паузой, пока
+// не истечет контекст старта. Наружу уходит ошибка последней попытки.
+func (r *Repository) OnStart(ctx context.Context) error {
+	poolCfg, err := newPoolConfig(&r.cfg.Postgres)
+	if err != nil {
+		return fmt.Errorf("invalid postgres config: %w", err)
+	}
+	pool, err := r.connect(ctx, poolCfg)
 	if err != nil {
 		return err
 	}
 	r.DB = pool
+
+	cfg := &r.cfg.Postgres
+	if len(cfg.Replicas) == 0 {
+		return nil
+	}
+	r.replicas, err = newReplicaSet(r.log, pool, cfg.Replicas, cfg.ReplicaMaxLag, cfg.ReplicaCheckInterval,
+		func(dsn string) (*pgxpool.Config, error) { return parsePoolConfig(dsn, cfg) },
+	)
+	if err != nil {
+		pool.Close()
+		return err
+	}
+	r.replicas.start(ctx)
 	return nil
 }
 
+func (r *Repository) connect(ctx context.Context, poolCfg *pgxpool.Config) (*pgxpool.Pool, error) {
+	cfg := r.cfg.Postgres
+	delay := cfg.ConnectRetryDelay
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+		if err == nil {
+			// пул подключается лениво, Ping проверяет первое соединение
+			if err = pool.Ping(ctx); err == nil {
+				return pool, nil
+			}
+			pool.Close()
+		}
+		// ошибка из-за истекшего контекста ничего не говорит о причине
+		if lastErr == nil || ctx.Err() == nil {
+			lastErr = err
+		}
+		if attempt >= cfg.ConnectAttempts {
+			return nil, fmt.Errorf("connect to postgres after %d attempts: %w", attempt, lastErr)
+		}
+		r.log.Warn("fail to connect to postgres, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("connect to postgres: %w", lastErr)
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxConnectRetryDelay)
+	}
+}
+
+// newPoolConfig собирает настройки пула. Адрес собирается как URL, чтобы
+// пароль со спецсимволами не ломал строку подключения.
+func newPoolConfig(cfg *config.PostgresConfig) (*pgxpool.Config, error) {
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(cfg.User, cfg.Password),
+		Host:   net.JoinHostPort(cfg.Host, cfg.Port),
+		Path:   "/" + cfg.DBName,
+	}
+	if cfg.SSLMode != "" {
+		dsn.RawQuery = url.Values{"sslmode": {cfg.SSLMode}}.Encode()
+	}
+	return parsePoolConfig(dsn.String(), cfg)
+}
+
+// parsePoolConfig разбирает строку подключения и применяет к ней настройки
+// пула из cfg. Реплики настраиваются так же, как основная база.
+func parsePoolConfig(dsn string, cfg *config.PostgresConfig) (*pgxpool.Config, error) {
+	poolCfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	poolCfg.MaxConns = cfg.MaxConns
+	poolCfg.MinConns = cfg.MinConns
+	poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	poolCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
+	poolCfg.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+
+	params := poolCfg.ConnConfig.RuntimeParams
+	if cfg.ApplicationName != "" {
+		params["application_name"] = cfg.ApplicationName
+	}
+	if cfg.StatementTimeout > 0 {
+		params["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+	return poolCfg, nil
+}
+
 func (r *Repository) OnStop(_ context.Context) error {
+	if r.replicas != nil {
+		r.replicas.stop()
+	}
 	r.DB.Close()
 	return nil
 }
 
+// conn - транзакция из ctx или основная база. op - метод репозитория,
+// по нему называются спаны запросов.
+func (r *Repository) conn(ctx context.Context, op string) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tracedQuerier{q: tx, op: op}
+	}
+	return tracedQuerier{q: r.DB, op: op}
+}
+
+func (r *Repository) logger(ctx context.Context) *zap.Logger {
+	return logging.FromContext(ctx, r.log)
+}
+
+func (r *Repository) Name() string {
+	return "postgres"
+}
+
+func (r *Repository) Check(ctx context.Context) error {
+	if r.DB == nil {
+		return errors.New("pool is not connected")
+	}
+	return r.DB.Ping(ctx)
+}
+
 const queryGetAd = `
 SELECT EXISTS (SELECT id
 FROM advertisements
@@ -58,16 +176,14 @@ WHERE id = $1);
 
 func (r *Repository) IsAdExist(ctx context.Context, advertisment *entities.Advertisment) (bool, error) {
 	var res bool
-	err := r.DB.QueryRow(ctx, queryGetAd, advertisment.ID).Scan(&res)
-	if err != nil{
-		r.log.Error("IsAdExist: error with QueryRow", zap.Error(err))
+	err := r.conn(ctx, "IsAdExist").QueryRow(ctx, queryGetAd, advertisment.ID).Scan(&res)
+	if err != nil {
+		r.logger(ctx).Error("IsAdExist: error with QueryRow", zap.Error(err))
 		return false, err
 	}
 	return res, nil
 }
 
-
-
 const queryGetAdInfo = `
 SELECT 
     a.user_id,
@@ -79,23 +195,44 @@ SELECT
     a.views_count,
     a.date_expire_promotion,
     a.category_id,
-    a.type_id,
-    tp.name AS type_promotion_name,
-    tp.price AS type_promotion_name,
-    tp.time_live AS type_promotion_name,
-    cp.name AS category_name
+    COALESCE(a.type_id, 0),
+    COALESCE(tp.name, '') AS type_promotion_name,
+    COALESCE(tp.price, 0) AS type_promotion_name,
+    COALESCE(tp.time_live, '0'::interval) AS type_promotion_name,
+    cp.name AS category_name,
+    COALESCE(cp.parent_id, 0),
+    a.moderation_status,
+    COALESCE(a.moderation_reason, ''),
+    a.moderation_flags,
+    a.is_hidden,
+    a.status,
+    a.status_changed_at,
+    a.publish_at,
+    COALESCE(a.city_id, 0),
+    COALESCE(ci.name, ''),
+    COALESCE(ci.region, ''),
+    COALESCE(ci.latitude, 0),
+    COALESCE(ci.longitude, 0),
+    a.latitude,
+    a.longitude,
+    a.updated_at,
+    cp.updated_at,
+    tp.updated_at,
+    ci.updated_at
 FROM advertisements a
-JOIN types_promotion tp ON a.type_id = tp.id
+LEFT JOIN types_promotion tp ON a.type_id = tp.id
+LEFT JOIN cities ci ON a.city_id = ci.id
 JOIN categories_product cp ON a.category_id = cp.id
 WHERE a.id = $1;
 `
+
 // JOIN categories_product u ON a.user_id = u.id
 
 func (r *Repository) GetAdvertismentAllInfo(ctx context.Context, advertisment *entities.Advertisment) error {
 	adto := &entities.AdvertismentDTO{
 		ID: advertisment.ID,
 	}
-	if err := r.DB.QueryRow(
+	if err := r.readConn(ctx, "GetAdvertismentAllInfo").QueryRow(
 		ctx,
 		queryGetAdInfo,
 		adto.ID,
@@ -114,15 +251,33 @@ func (r *Repository) GetAdvertismentAllInfo(ctx context.Context, advertisment *e
 		&adto.TypePromotion.Price,
 		&adto.TypePromotion.TimeLive,
 		&adto.AdvertismentCategory.Name,
-	); err != nil{
-		r.log.Error("GetAdvertismentAllInfo: error with SELECT FROM", zap.Error(err))
+		&adto.AdvertismentCategory.ParentID,
+		&adto.Moderation.Status,
+		&adto.Moderation.Reason,
+		&adto.Moderation.Flags,
+		&adto.Moderation.Hidden,
+		&adto.Status,
+		&adto.StatusChangedAt,
+		&adto.PublishAt,
+		&adto.City.ID,
+		&adto.City.Name,
+		&adto.City.Region,
+		&adto.City.Latitude,
+		&adto.City.Longitude,
+		&adto.Latitude,
+		&adto.Longitude,
+		&adto.UpdatedAt,
+		&adto.AdvertismentCategory.UpdatedAt,
+		&adto.TypePromotion.UpdatedAt,
+		&adto.City.UpdatedAt,
+	); err != nil {
+		r.logger(ctx).Error("GetAdvertismentAllInfo: error with SELECT FROM", zap.Error(err))
 		return err
 	}
 	entities.ConvertDTOToAdvertisment(adto, advertisment)
 	return nil
 }
 
-
 const queryGetUserInfo = `
 SELECT 
     u.path_ava,
@@ -132,8 +287,10 @@ SELECT
     u.number_phone,
     u.rating,
     u.verification_status,
+    u.phone_confirmed_at IS NOT NULL,
     u.role_id,
-    r.name AS role_name
+    r.name AS role_name,
+    u.updated_at
 FROM users u
 JOIN user_roles r ON u.role_id = r.id
 WHERE u.id = $1;
@@ -144,7 +301,7 @@ func (r *Repository) GetUserInfo(ctx context.Context, user *entities.User) error
 	udto := &entities.UserDTO{
 		ID: user.ID,
 	}
-	err := r.DB.QueryRow(
+	err := r.conn(ctx, "GetUserInfo").QueryRow(
 		ctx,
 		queryGetUserInfo,
 		user.ID,
@@ -156,18 +313,37 @@ func (r *Repository) GetUserInfo(ctx context.Context, user *entities.User) error
 		&udto.NumberPhone,
 		&udto.Rating,
 		&udto.VerificationStatus,
+		&udto.PhoneConfirmed,
 		&udto.Role.ID,
 		&udto.Role.Name,
-		
+		&udto.UpdatedAt,
 	)
 	entities.ConvertDTOToUser(udto, user)
-	if err != nil{
-		r.log.Error("GetUserInfo: error with SELECT FROM", zap.Error(err))
+	if err != nil {
+		r.logger(ctx).Error("GetUserInfo: error with SELECT FROM", zap.Error(err))
 		return err
 	}
 	return nil
 }
 
+const queryGetUserUpdatedAt = `
+SELECT updated_at
+FROM users
+WHERE id = $1;
+`
+
+// GetUserUpdatedAt возвращает время последнего изменения профиля
+func (r *Repository) GetUserUpdatedAt(ctx context.Context, uID uint64) (time.Time, error) {
+	var updatedAt time.Time
+	if err := r.readConn(ctx, "GetUserUpdatedAt").QueryRow(ctx, queryGetUserUpdatedAt, uID).Scan(&updatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return updatedAt, ErrNotFound
+		}
+		r.logger(ctx).Error("GetUserUpdatedAt: error with QueryRow", zap.Error(err))
+		return updatedAt, err
+	}
+	return updatedAt, nil
+}
 
 const queryGetUser = `
 SELECT EXISTS (SELECT id
@@ -177,15 +353,14 @@ WHERE id = $1);
 
 func (r *Repository) IsUserExist(ctx context.Context, user *entities.User) (bool, error) {
 	var res bool
-	err := r.DB.QueryRow(ctx, queryGetUser, user.ID).Scan(&res)
-	if err != nil{
-		r.log.Error("IsAdExist: error with QueryRow", zap.Error(err))
+	err := r.conn(ctx, "IsUserExist").QueryRow(ctx, queryGetUser, user.ID).Scan(&res)
+	if err != nil {
+		r.logger(ctx).Error("IsAdExist: error with QueryRow", zap.Error(err))
 		return false, err
 	}
 	return res, nil
 }
 
-
 const queryGetReviews = `
 SELECT 
     r.id,
@@ -200,22 +375,23 @@ SELECT
 	u.rating,
 	u.verification_status,
 	u.role_id,
-	ur.name
+	ur.name,
+	u.updated_at
 FROM reviews r
 JOIN deals d ON d.id = r.deal_id
 JOIN users u ON d.buyer_id = u.id
 JOIN user_roles ur ON u.role_id = ur.id
-WHERE d.advertisement_id = $1;
+WHERE d.advertisement_id = $1 AND NOT r.is_hidden;
 `
 
 func (r *Repository) GetAdvertismentReviews(ctx context.Context, advertisment *entities.Advertisment) error {
-	rows, err := r.DB.Query(
+	rows, err := r.readConn(ctx, "GetAdvertismentReviews").Query(
 		ctx,
 		queryGetReviews,
 		advertisment.ID,
 	)
-	if err != nil{
-		r.log.Error("GetReviews: error with SELECT FROM", zap.Error(err))
+	if err != nil {
+		r.logger(ctx).Error("GetReviews: error with SELECT FROM", zap.Error(err))
 		return err
 	}
 	defer rows.Close()
@@ -238,16 +414,17 @@ func (r *Repository) GetAdvertismentReviews(ctx context.Context, advertisment *e
 			&rdto.Reviewer.VerificationStatus,
 			&rdto.Reviewer.Role.ID,
 			&rdto.Reviewer.Role.Name,
+			&rdto.Reviewer.UpdatedAt,
 		); err != nil {
-			r.log.Error("GetReviews: error with scan row", zap.Error(err))
-			return err		
+			r.logger(ctx).Error("GetReviews: error with scan row", zap.Error(err))
+			return err
 		}
 		entities.ConvertDTOToReview(&rdto, &review)
 		advertisment.Reviews = append(advertisment.Reviews, review)
 	}
 
 	if err := rows.Err(); err != nil {
-		r.log.Error("GetReviews: error iterating through rows", zap.Error(err))
+		r.logger(ctx).Error("GetReviews: error iterating through rows", zap.Error(err))
 		return err
 	}
 	return nil
@@ -261,13 +438,13 @@ WHERE ph.advertisement_id = $1;
 `
 
 func (r *Repository) GetAdvertismentPhotos(ctx context.Context, advertisment *entities.Advertisment) error {
-	rows, err := r.DB.Query(
+	rows, err := r.readConn(ctx, "GetAdvertismentPhotos").Query(
 		ctx,
 		queryGetPhotos,
 		advertisment.ID,
 	)
-	if err != nil{
-		r.log.Error("GetPhotos: error with SELECT FROM", zap.Error(err))
+	if err != nil {
+		r.logger(ctx).Error("GetPhotos: error with SELECT FROM", zap.Error(err))
 		return err
 	}
 	defer rows.Close()
@@ -278,14 +455,14 @@ func (r *Repository) GetAdvertismentPhotos(ctx context.Context, advertisment *en
 		if err := rows.Scan(
 			&adPhoto.Path,
 		); err != nil {
-			r.log.Error("GetPhotos: error with scan row", zap.Error(err))
-			return err		
+			r.logger(ctx).Error("GetPhotos: error with scan row", zap.Error(err))
+			return err
 		}
 		advertisment.Photos = append(advertisment.Photos, adPhoto)
 	}
 
 	if err := rows.Err(); err != nil {
-		r.log.Error("GetPhotos: error iterating through rows", zap.Error(err))
+		r.logger(ctx).Error("GetPhotos: error iterating through rows", zap.Error(err))
 		return err
 	}
 	return nil
@@ -293,15 +470,15 @@ func (r *Repository) GetAdvertismentPhotos(ctx context.Context, advertisment *en
 
 func (r *Repository) GetMainAdPhotoByAdID(ctx context.Context, adID uint64) (string, error) {
 	var adPhotoPath string
-	if err := r.DB.QueryRow(ctx, queryGetPhotos, adID,).Scan(&adPhotoPath,); err != nil{
-		r.log.Error("GetStatisticAdPhoto: error with SELECT FROM", zap.Error(err))
+	if err := r.readConn(ctx, "GetMainAdPhotoByAdID").QueryRow(ctx, queryGetPhotos, adID).Scan(&adPhotoPath); err != nil {
+		r.logger(ctx).Error("GetStatisticAdPhoto: error with SELECT FROM", zap.Error(err))
 		return "", err
 	}
-	
+
 	return adPhotoPath, nil
 }
 
-const  queryGetReviewByDealID = `
+const queryGetReviewByDealID = `
 SELECT EXIST	(SELECT 
 					r.id
 				FROM reviews r
@@ -310,9 +487,9 @@ SELECT EXIST	(SELECT
 
 func (r *Repository) IsReviewExistByDealID(ctx context.Context, dealID uint64) (bool, error) {
 	var res bool
-	err := r.DB.QueryRow(ctx, queryGetReviewByDealID, dealID).Scan(&res)
-	if err != nil{
-		r.log.Error("IsAdExist: error with QueryRow", zap.Error(err))
+	err := r.conn(ctx, "IsReviewExistByDealID").QueryRow(ctx, queryGetReviewByDealID, dealID).Scan(&res)
+	if err != nil {
+		r.logger(ctx).Error("IsAdExist: error with QueryRow", zap.Error(err))
 		return false, err
 	}
 	return res, nil
@@ -327,20 +504,19 @@ WHERE r.deal_id = $1;
 `
 
 func (r *Repository) GetStatisticAdReviewMark(ctx context.Context, stat *entities.ProfileStatistic) error {
-	if err := r.DB.QueryRow(ctx, queryGetGetStatisticAdReviewMark, stat.AdID,).Scan(
+	if err := r.readConn(ctx, "GetStatisticAdReviewMark").QueryRow(ctx, queryGetGetStatisticAdReviewMark, stat.AdID).Scan(
 		&stat.DealReviewID,
 		&stat.AdReviewMark,
-		); err != nil{
-		r.log.Error("GetStatisticAdPhoto: error with SELECT FROM", zap.Error(err))
+	); err != nil {
+		r.logger(ctx).Error("GetStatisticAdPhoto: error with SELECT FROM", zap.Error(err))
 		return err
 	}
-	
+
 	return nil
 }
 
-
 // const queryGetPhone = `
-// SELECT 
+// SELECT
 //     *
 // FROM users
 // WHERE number_phone = $1
@@ -359,7 +535,7 @@ func (r *Repository) GetStatisticAdReviewMark(ctx context.Context, stat *entitie
 // }
 
 // const queryGetUsername = `
-// SELECT 
+// SELECT
 //     *
 // FROM users
 // WHERE username = $1
@@ -378,8 +554,8 @@ func (r *Repository) GetStatisticAdReviewMark(ctx context.Context, stat *entitie
 // }
 
 // const queryCreateUser = `
-// INSERT INTO users 
-//     (id, path_ava, username, firstname, lastname, number_phone) 
+// INSERT INTO users
+//     (id, path_ava, username, firstname, lastname, number_phone)
 // VALUES
 //     ($1, $2, $3, $4, $5, $6)
 // `
@@ -409,7 +585,6 @@ func (r *Repository) GetStatisticAdReviewMark(ctx context.Context, stat *entitie
 // 	return nil
 // }
 
-
 const queryGetAdsByBuyerID = `
 	SELECT 
 		d.id,
@@ -420,13 +595,14 @@ const queryGetAdsByBuyerID = `
 		JOIN advertisements a ON d.advertisement_id = a.id
 	WHERE d.buyer_id = $1;
 `
-		// r.mark
-	// JOIN reviews r ON d.id = r.deal_id
+
+// r.mark
+// JOIN reviews r ON d.id = r.deal_id
 
 func (r *Repository) GetProfileUserStatistics(ctx context.Context, uID uint64, stats *[]*entities.ProfileStatistic) error {
-	rows, err := r.DB.Query(ctx, queryGetAdsByBuyerID, uID)
-	if err != nil{
-		r.log.Error("GetAdvertismentsByBuyerID: error with SELECT FROM", zap.Error(err))
+	rows, err := r.readConn(ctx, "GetProfileUserStatistics").Query(ctx, queryGetAdsByBuyerID, uID)
+	if err != nil {
+		r.logger(ctx).Error("GetAdvertismentsByBuyerID: error with SELECT FROM", zap.Error(err))
 		return err
 	}
 	defer rows.Close()
@@ -442,43 +618,48 @@ func (r *Repository) GetProfileUserStatistics(ctx context.Context, uID uint64, s
 			&stat.AdID,
 			&stat.AdName,
 			&stat.AdPrice,
-			// &stat.AdReviewMark,
-			); err != nil {
-			r.log.Error("GetAdvertismentsByBuyerID: error with scan row", zap.Error(err))
-			return err		
+		// &stat.AdReviewMark,
+		); err != nil {
+			r.logger(ctx).Error("GetAdvertismentsByBuyerID: error with scan row", zap.Error(err))
+			return err
 		}
 		// entities.ConvertDTOToStatistic(dto, stat)
 		*stats = append(*stats, stat)
 	}
 
 	if err := rows.Err(); err != nil {
-		r.log.Error("GetAdvertismentsByBuyerID: error iterating through rows", zap.Error(err))
+		r.logger(ctx).Error("GetAdvertismentsByBuyerID: error iterating through rows", zap.Error(err))
 		return err
 	}
 	return nil
 }
 
-
 const queryGetProfileMyAdvertisments = `
 SELECT
 	a.id,
 	a.name,
 	a.price,
 	a.views_count,
-	a.type_id,
-	tp.name,
-	a.date_expire_promotion
+	COALESCE(a.type_id, 0),
+	COALESCE(tp.name, ''),
+	a.date_expire_promotion,
+	a.moderation_status,
+	COALESCE(a.moderation_reason, ''),
+	a.status,
+	a.publish_at
 FROM advertisements a
-	JOIN types_promotion tp ON a.type_id = tp.id
+	LEFT JOIN types_promotion tp ON a.type_id = tp.id
 WHERE 
-	a.user_id = $1;
+	a.user_id = $1
+	AND a.status <> 'deleted'
+	AND (a.status NOT IN ('draft', 'scheduled') OR $2);
 `
 
-
-func (r *Repository) GetProfileMyAdvertisments(ctx context.Context, uID uint64, advertisements *[]*entities.MyAdvertisement) error {
-	rows, err := r.DB.Query(ctx, queryGetProfileMyAdvertisments, uID)
-	if err != nil{
-		r.log.Error("GetProfileMyAdvertisments: error with SELECT FROM", zap.Error(err))
+// GetProfileMyAdvertisments: черновики и отложенные попадают в список только с withDrafts
+func (r *Repository) GetProfileMyAdvertisments(ctx context.Context, uID uint64, withDrafts bool, advertisements *[]*entities.MyAdvertisement) error {
+	rows, err := r.readConn(ctx, "GetProfileMyAdvertisments").Query(ctx, queryGetProfileMyAdvertisments, uID, withDrafts)
+	if err != nil {
+		r.logger(ctx).Error("GetProfileMyAdvertisments: error with SELECT FROM", zap.Error(err))
 		return err
 	}
 	defer rows.Close()
@@ -494,15 +675,19 @@ func (r *Repository) GetProfileMyAdvertisments(ctx context.Context, uID uint64,
 			&ad.AdTypePromotionID,
 			&ad.AdTypePromotionName,
 			&ad.AdDateExpirePromotion,
-			); err != nil {
-			r.log.Error("GetProfileMyAdvertisments: error with scan row", zap.Error(err))
-			return err		
+			&ad.AdModerationStatus,
+			&ad.AdModerationReason,
+			&ad.AdStatus,
+			&ad.AdPublishAt,
+		); err != nil {
+			r.logger(ctx).Error("GetProfileMyAdvertisments: error with scan row", zap.Error(err))
+			return err
 		}
 		*advertisements = append(*advertisements, &ad)
 	}
 
 	if err := rows.Err(); err != nil {
-		r.log.Error("GetProfileMyAdvertisments: error iterating through rows", zap.Error(err))
+		r.logger(ctx).Error("GetProfileMyAdvertisments: error iterating through rows", zap.Error(err))
 		return err
 	}
 	return nil
@@ -525,13 +710,14 @@ FROM advertisements a
 	JOIN reviews r ON d.id = r.deal_id
 	JOIN users u ON d.buyer_id = u.id
 WHERE 
-	a.user_id = $1;
+	a.user_id = $1
+	AND NOT r.is_hidden;
 `
 
 func (r *Repository) GetProfileReviews(ctx context.Context, uID uint64, reviews *[]*entities.ProfileReview) error {
-	rows, err := r.DB.Query(ctx, queryGetProfileReviews, uID)
-	if err != nil{
-		r.log.Error("GetProfileReviews: error with SELECT FROM", zap.Error(err))
+	rows, err := r.readConn(ctx, "GetProfileReviews").Query(ctx, queryGetProfileReviews, uID)
+	if err != nil {
+		r.logger(ctx).Error("GetProfileReviews: error with SELECT FROM", zap.Error(err))
 		return err
 	}
 	defer rows.Close()
@@ -551,17 +737,17 @@ func (r *Repository) GetProfileReviews(ctx context.Context, uID uint64, reviews
 			&dto.ReviewerUsername,
 			&dto.ReviewerFirstname,
 			&dto.ReviewerLastname,
-			); err != nil {
-			r.log.Error("GetProfileReviews: error with scan row", zap.Error(err))
-			return err		
+		); err != nil {
+			r.logger(ctx).Error("GetProfileReviews: error with scan row", zap.Error(err))
+			return err
 		}
 		entities.ConvertDTOToProfileReview(&dto, &review)
 		*reviews = append(*reviews, &review)
 	}
 
 	if err := rows.Err(); err != nil {
-		r.log.Error("GetProfileReviews: error iterating through rows", zap.Error(err))
+		r.logger(ctx).Error("GetProfileReviews: error iterating through rows", zap.Error(err))
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
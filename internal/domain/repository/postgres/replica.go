@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// primaryKey - ключ контекста, по которому чтения идут в основную базу
+type primaryKey struct{}
+
+// WithPrimary направляет все чтения с этим ctx в основную базу: для чтения
+// только что записанных данных, которые реплика еще могла не получить
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func usePrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+type replica struct {
+	host    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// replicaSet - реплики для чтения. Фоновая проверка снимает с раздачи
+// недоступные и отстающие больше ReplicaMaxLag реплики и возвращает их,
+// когда они догонят основную базу.
+type replicaSet struct {
+	log *zap.Logger
+	// primary - основная база, с ее позицией WAL сравниваются реплики
+	primary  *pgxpool.Pool
+	maxLag   time.Duration
+	interval time.Duration
+	replicas []*replica
+	next     atomic.Uint64
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newReplicaSet(log *zap.Logger, primary *pgxpool.Pool, dsns []string, maxLag, interval time.Duration, parse func(dsn string) (*pgxpool.Config, error)) (*replicaSet, error) {
+	set := &replicaSet{
+		log:      log.Named("replicas"),
+		primary:  primary,
+		maxLag:   maxLag,
+		interval: interval,
+	}
+	for i, dsn := range dsns {
+		poolCfg, err := parse(dsn)
+		if err != nil {
+			// сама строка может содержать пароль, поэтому только номер
+			return nil, fmt.Errorf("replica %d: invalid dsn", i)
+		}
+		// пул подключается лениво: недоступная реплика не мешает старту
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolCfg)
+		if err != nil {
+			set.close()
+			return nil, fmt.Errorf("replica %s: %w", poolCfg.ConnConfig.Host, err)
+		}
+		set.replicas = append(set.replicas, &replica{host: poolCfg.ConnConfig.Host, pool: pool})
+	}
+	return set, nil
+}
+
+// start проверяет реплики сразу и дальше раз в interval
+func (s *replicaSet) start(ctx context.Context) {
+	s.checkAll(ctx)
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.run(runCtx)
+}
+
+func (s *replicaSet) stop() {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+	s.close()
+}
+
+func (s *replicaSet) close() {
+	for _, rep := range s.replicas {
+		rep.pool.Close()
+	}
+}
+
+func (s *replicaSet) run(ctx context.Context) {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkAll(ctx)
+		}
+	}
+}
+
+// pick возвращает следующую исправную реплику по кругу или nil
+func (s *replicaSet) pick() *replica {
+	n := uint64(len(s.replicas))
+	start := s.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		rep := s.replicas[(start+i)%n]
+		if rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+const queryPrimaryWALPosition = `SELECT pg_current_wal_lsn()::text;`
+
+// Реплика, применившая WAL до позиции основной базы на момент проверки, не
+// отстает, даже если основная база давно ничего не писала. Иначе отставание -
+// время с последней примененной транзакции: у реплики с оборванным приемом
+// WAL оно растет, пока основная база пишет. NULL (реплика еще ничего не
+// применила) - отставание неизвестно, реплика не используется.
+const queryReplicaLag = `
+SELECT
+	CASE
+		WHEN NOT pg_is_in_recovery() THEN 0::float8
+		WHEN pg_last_wal_replay_lsn() >= $1::pg_lsn THEN 0::float8
+		ELSE COALESCE(EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - pg_last_xact_replay_timestamp())::float8, 'Infinity'::float8)
+	END;
+`
+
+func (s *replicaSet) checkAll(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, s.interval)
+	defer cancel()
+	var primaryLSN string
+	if err := s.primary.QueryRow(ctx, queryPrimaryWALPosition).Scan(&primaryLSN); err != nil {
+		// без позиции основной базы отставание не измерить
+		s.log.Warn("fail to get primary wal position", zap.Error(err))
+		for _, rep := range s.replicas {
+			s.setHealthy(rep, false, 0, err)
+		}
+		return
+	}
+	for _, rep := range s.replicas {
+		s.check(ctx, rep, primaryLSN)
+	}
+}
+
+func (s *replicaSet) check(ctx context.Context, rep *replica, primaryLSN string) {
+	var seconds float64
+	err := rep.pool.QueryRow(ctx, queryReplicaLag, primaryLSN).Scan(&seconds)
+	lag := time.Duration(seconds * float64(time.Second))
+	if math.IsInf(seconds, 1) {
+		lag = time.Duration(math.MaxInt64)
+	}
+	s.setHealthy(rep, err == nil && lag <= s.maxLag, lag, err)
+}
+
+func (s *replicaSet) setHealthy(rep *replica, healthy bool, lag time.Duration, err error) {
+	if rep.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		s.log.Info("replica added to rotation", zap.String("host", rep.host), zap.Duration("lag", lag))
+		return
+	}
+	s.log.Warn("replica removed from rotation",
+		zap.String("host", rep.host),
+		zap.Duration("lag", lag),
+		zap.Error(err),
+	)
+}
+
+// readConn - соединение для тяжелых чтений, которым допустимо отставание
+// реплики не больше ReplicaMaxLag. Внутри транзакции, с WithPrimary и без
+// исправных реплик чтение идет в основную базу.
+func (r *Repository) readConn(ctx context.Context, op string) querier {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok || usePrimary(ctx) || r.replicas == nil {
+		return r.conn(ctx, op)
+	}
+	if rep := r.replicas.pick(); rep != nil {
+		return tracedQuerier{q: rep.pool, op: op}
+	}
+	return r.conn(ctx, op)
+}
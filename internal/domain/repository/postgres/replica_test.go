@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// closedPortDSN - адрес, на котором никто не слушает: пул создается, но
+// любой запрос падает на подключении
+const closedPortDSN = "postgres://test@127.0.0.1:1/test?connect_timeout=1"
+
+func newLazyPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	pool, err := pgxpool.New(context.Background(), closedPortDSN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// newReplicaRepository - репозиторий с основной базой и репликой на каждый
+// элемент healthy; без healthy реплик нет
+func newReplicaRepository(t *testing.T, healthy ...bool) *Repository {
+	r := newTestRepository()
+	r.DB = newLazyPool(t)
+	if healthy == nil {
+		return r
+	}
+	r.replicas = &replicaSet{log: zap.NewNop(), primary: r.DB, interval: time.Second}
+	for i, ok := range healthy {
+		rep := &replica{host: fmt.Sprintf("replica%d", i+1), pool: newLazyPool(t)}
+		rep.healthy.Store(ok)
+		r.replicas.replicas = append(r.replicas.replicas, rep)
+	}
+	return r
+}
+
+// target - куда ушло бы чтение: "primary", хост реплики или "tx"
+func target(r *Repository, q querier) string {
+	switch conn := q.(tracedQuerier).q.(type) {
+	case pgx.Tx:
+		return "tx"
+	case *pgxpool.Pool:
+		if conn == r.DB {
+			return "primary"
+		}
+		for _, rep := range r.replicas.replicas {
+			if conn == rep.pool {
+				return rep.host
+			}
+		}
+	}
+	return "unknown"
+}
+
+func TestReadConn(t *testing.T) {
+	tests := []struct {
+		name    string
+		healthy []bool
+		ctx     func(ctx context.Context) context.Context
+		want    []string
+	}{
+		{name: "no replicas", want: []string{"primary", "primary"}},
+		{name: "healthy replicas in turn", healthy: []bool{true, true}, want: []string{"replica2", "replica1", "replica2"}},
+		{name: "unhealthy replica is skipped", healthy: []bool{false, true}, want: []string{"replica2", "replica2"}},
+		{name: "all replicas unhealthy", healthy: []bool{false, false}, want: []string{"primary", "primary"}},
+		{
+			name:    "WithPrimary",
+			healthy: []bool{true},
+			ctx:     WithPrimary,
+			want:    []string{"primary"},
+		},
+		{
+			name:    "inside transaction",
+			healthy: []bool{true},
+			ctx:     func(ctx context.Context) context.Context { return inTx(ctx, &txJournal{}) },
+			want:    []string{"tx"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReplicaRepository(t, tt.healthy...)
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx(ctx)
+			}
+			for i, want := range tt.want {
+				if got := target(r, r.readConn(ctx, "test")); got != want {
+					t.Errorf("read %d went to %s, want %s", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckAllWithoutPrimary(t *testing.T) {
+	// без позиции WAL основной базы отставание реплик неизвестно
+	r := newReplicaRepository(t, true, true)
+	r.replicas.checkAll(context.Background())
+	for _, rep := range r.replicas.replicas {
+		if rep.healthy.Load() {
+			t.Errorf("%s stayed in rotation", rep.host)
+		}
+	}
+	if got := target(r, r.readConn(context.Background(), "test")); got != "primary" {
+		t.Errorf("read went to %s, want primary", got)
+	}
+}
+
+func TestCheckUnreachableReplica(t *testing.T) {
+	r := newReplicaRepository(t, true)
+	rep := r.replicas.replicas[0]
+	r.replicas.check(context.Background(), rep, "0/0")
+	if rep.healthy.Load() {
+		t.Error("unreachable replica stayed in rotation")
+	}
+}
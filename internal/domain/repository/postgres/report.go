@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const queryCreateReport = `
+INSERT INTO reports
+	(reporter_id, target_type, target_id, reason, comment)
+VALUES
+	($1, $2, $3, $4, $5)
+RETURNING id, status, created_at;
+`
+
+func (r *Repository) CreateReport(ctx context.Context, report *entities.Report) error {
+	if err := r.conn(ctx, "CreateReport").QueryRow(
+		ctx,
+		queryCreateReport,
+		report.ReporterID,
+		report.TargetType,
+		report.TargetID,
+		report.Reason,
+		entities.NewNullString(report.Comment),
+	).Scan(
+		&report.ID,
+		&report.Status,
+		&report.CreatedAt,
+	); err != nil {
+		r.logger(ctx).Error("CreateReport: error with INSERT", zap.Error(err))
+		return mapPgError(err)
+	}
+	return nil
+}
+
+const queryCountOpenReporters = `
+SELECT COUNT(DISTINCT reporter_id)
+FROM reports
+WHERE target_type = $1
+	AND target_id = $2
+	AND status = 'open';
+`
+
+const queryLockAdvertisment = `
+SELECT id
+FROM advertisements
+WHERE id = $1
+FOR UPDATE;
+`
+
+// LockAdvertisment блокирует строку объявления до конца транзакции из ctx:
+// конкурирующие транзакции над тем же объявлением выполняются по очереди
+func (r *Repository) LockAdvertisment(ctx context.Context, adID uint64) error {
+	var id uint64
+	if err := r.conn(ctx, "LockAdvertisment").QueryRow(ctx, queryLockAdvertisment, adID).Scan(&id); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		r.logger(ctx).Error("LockAdvertisment: error with QueryRow", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// CountOpenReporters: сколько разных пользователей пожаловались на объект
+func (r *Repository) CountOpenReporters(ctx context.Context, targetType string, targetID uint64) (uint64, error) {
+	var count uint64
+	if err := r.conn(ctx, "CountOpenReporters").QueryRow(ctx, queryCountOpenReporters, targetType, targetID).Scan(&count); err != nil {
+		r.logger(ctx).Error("CountOpenReporters: error with QueryRow", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+const queryGetReports = `
+SELECT
+	rp.id,
+	rp.reporter_id,
+	rp.target_type,
+	rp.target_id,
+	rp.reason,
+	COALESCE(rp.comment, ''),
+	rp.status,
+	rp.created_at,
+	COALESCE(rp.resolved_by, 0),
+	rp.resolved_at,
+	COUNT(*) FILTER (WHERE rp.status = 'open') OVER (PARTITION BY rp.target_type, rp.target_id)
+FROM reports rp
+WHERE rp.status = $1
+ORDER BY rp.created_at, rp.id
+LIMIT $2 OFFSET $3;
+`
+
+func (r *Repository) GetReports(ctx context.Context, status string, limit, offset uint64, reports *[]*entities.Report) error {
+	rows, err := r.conn(ctx, "GetReports").Query(ctx, queryGetReports, status, limit, offset)
+	if err != nil {
+		r.logger(ctx).Error("GetReports: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		report := entities.Report{}
+		if err := rows.Scan(
+			&report.ID,
+			&report.ReporterID,
+			&report.TargetType,
+			&report.TargetID,
+			&report.Reason,
+			&report.Comment,
+			&report.Status,
+			&report.CreatedAt,
+			&report.ResolvedBy,
+			&report.ResolvedAt,
+			&report.TargetReports,
+		); err != nil {
+			r.logger(ctx).Error("GetReports: error with scan row", zap.Error(err))
+			return err
+		}
+		*reports = append(*reports, &report)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetReports: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryResolveReport = `
+UPDATE reports
+SET status = $2,
+	resolved_by = $3,
+	resolved_at = CURRENT_TIMESTAMP
+WHERE id = $1
+	AND status = 'open'
+RETURNING target_type, target_id;
+`
+
+// ResolveReport закрывает открытую жалобу и возвращает ее объект
+func (r *Repository) ResolveReport(ctx context.Context, report *entities.Report) error {
+	if err := r.conn(ctx, "ResolveReport").QueryRow(
+		ctx,
+		queryResolveReport,
+		report.ID,
+		report.Status,
+		report.ResolvedBy,
+	).Scan(
+		&report.TargetType,
+		&report.TargetID,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		r.logger(ctx).Error("ResolveReport: error with UPDATE", zap.Error(err))
+		return err
+	}
+	return nil
+}
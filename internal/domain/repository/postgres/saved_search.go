@@ -0,0 +1,302 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const queryCountSavedSearches = `
+SELECT COUNT(*)
+FROM saved_searches
+WHERE user_id = $1;
+`
+
+func (r *Repository) CountSavedSearches(ctx context.Context, uID uint64) (uint64, error) {
+	var count uint64
+	if err := r.conn(ctx, "CountSavedSearches").QueryRow(ctx, queryCountSavedSearches, uID).Scan(&count); err != nil {
+		r.logger(ctx).Error("CountSavedSearches: error with QueryRow", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+const queryCreateSavedSearch = `
+INSERT INTO saved_searches
+	(user_id, name, filter, category_id, price_min, price_max, notify)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, created_at;
+`
+
+func (r *Repository) CreateSavedSearch(ctx context.Context, search *entities.SavedSearch) error {
+	filter, err := json.Marshal(search.Filter)
+	if err != nil {
+		return err
+	}
+	if err = r.conn(ctx, "CreateSavedSearch").QueryRow(
+		ctx,
+		queryCreateSavedSearch,
+		search.UserID,
+		search.Name,
+		filter,
+		nullableID(search.Filter.CategoryID),
+		search.Filter.PriceMin,
+		search.Filter.PriceMax,
+		search.Notify,
+	).Scan(
+		&search.ID,
+		&search.CreatedAt,
+	); err != nil {
+		r.logger(ctx).Error("CreateSavedSearch: error with INSERT", zap.Error(err))
+		return mapPgError(err)
+	}
+	return nil
+}
+
+const queryGetSavedSearches = `
+SELECT
+	id,
+	user_id,
+	name,
+	filter,
+	notify,
+	created_at,
+	last_digest_at
+FROM saved_searches
+WHERE user_id = $1
+ORDER BY id;
+`
+
+func (r *Repository) GetSavedSearches(ctx context.Context, uID uint64, searches *[]*entities.SavedSearch) error {
+	rows, err := r.conn(ctx, "GetSavedSearches").Query(ctx, queryGetSavedSearches, uID)
+	if err != nil {
+		r.logger(ctx).Error("GetSavedSearches: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			r.logger(ctx).Error("GetSavedSearches: error with scan row", zap.Error(err))
+			return err
+		}
+		*searches = append(*searches, search)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetSavedSearches: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func scanSavedSearch(rows pgx.Rows) (*entities.SavedSearch, error) {
+	search := entities.SavedSearch{}
+	var filter []byte
+	if err := rows.Scan(
+		&search.ID,
+		&search.UserID,
+		&search.Name,
+		&filter,
+		&search.Notify,
+		&search.CreatedAt,
+		&search.LastDigestAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filter, &search.Filter); err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+const queryDeleteSavedSearch = `
+DELETE FROM saved_searches
+WHERE id = $1
+	AND user_id = $2;
+`
+
+func (r *Repository) DeleteSavedSearch(ctx context.Context, uID, searchID uint64) error {
+	result, err := r.conn(ctx, "DeleteSavedSearch").Exec(ctx, queryDeleteSavedSearch, searchID, uID)
+	if err != nil {
+		r.logger(ctx).Error("DeleteSavedSearch: error with DELETE", zap.Error(err))
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Кандидаты отбираются по копиям полей фильтра: категория (с учетом
+// родительских), цена и не свое объявление. Остальное проверяет MatchSavedSearch.
+const queryGetSavedSearchCandidates = `
+WITH RECURSIVE ad AS (
+	SELECT user_id, category_id, price
+	FROM advertisements
+	WHERE id = $1
+), ancestors AS (
+	SELECT id, parent_id
+	FROM categories_product
+	WHERE id = (SELECT category_id FROM ad)
+	UNION ALL
+	SELECT c.id, c.parent_id
+	FROM categories_product c
+		JOIN ancestors an ON c.id = an.parent_id
+)
+SELECT
+	s.id,
+	s.user_id,
+	s.name,
+	s.filter,
+	s.notify,
+	s.created_at,
+	s.last_digest_at
+FROM saved_searches s, ad
+WHERE s.user_id <> ad.user_id
+	AND (s.category_id IS NULL OR s.category_id IN (SELECT id FROM ancestors))
+	AND (s.price_min IS NULL OR s.price_min <= ad.price)
+	AND (s.price_max IS NULL OR s.price_max >= ad.price)
+ORDER BY s.id;
+`
+
+func (r *Repository) GetSavedSearchCandidates(ctx context.Context, adID uint64, searches *[]*entities.SavedSearch) error {
+	rows, err := r.conn(ctx, "GetSavedSearchCandidates").Query(ctx, queryGetSavedSearchCandidates, adID)
+	if err != nil {
+		r.logger(ctx).Error("GetSavedSearchCandidates: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			r.logger(ctx).Error("GetSavedSearchCandidates: error with scan row", zap.Error(err))
+			return err
+		}
+		*searches = append(*searches, search)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetSavedSearchCandidates: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// MatchSavedSearch проверяет объявление тем же запросом, что и поиск,
+// поэтому сохраненный поиск находит ровно то, что нашел бы пользователь
+func (r *Repository) MatchSavedSearch(ctx context.Context, filter *entities.AdvertismentFilter, adID uint64) (bool, error) {
+	q, _ := newSearchQuery(filter, r.cfg.Geo.PostGIS)
+	q.where = append(q.where, "a.id = "+q.arg(adID))
+	var sb strings.Builder
+	q.writeWith(&sb)
+	sb.WriteString(`SELECT EXISTS (SELECT 1
+FROM advertisements a
+	LEFT JOIN cities ci ON a.city_id = ci.id
+WHERE `)
+	sb.WriteString(strings.Join(q.where, "\n\tAND "))
+	sb.WriteString(");")
+
+	var res bool
+	if err := r.conn(ctx, "MatchSavedSearch").QueryRow(ctx, sb.String(), q.args...).Scan(&res); err != nil {
+		r.logger(ctx).Error("MatchSavedSearch: error with QueryRow", zap.Error(err))
+		return false, err
+	}
+	return res, nil
+}
+
+const queryCreateSavedSearchMatch = `
+INSERT INTO saved_search_matches
+	(saved_search_id, advertisement_id, notified_at)
+VALUES
+	($1, $2, CASE WHEN $3::boolean THEN CURRENT_TIMESTAMP END)
+ON CONFLICT (saved_search_id, advertisement_id) DO NOTHING;
+`
+
+// CreateSavedSearchMatch возвращает false, если объявление уже находилось
+// этим поиском, например до повторной модерации после правки
+func (r *Repository) CreateSavedSearchMatch(ctx context.Context, searchID, adID uint64, notified bool) (bool, error) {
+	result, err := r.conn(ctx, "CreateSavedSearchMatch").Exec(ctx, queryCreateSavedSearchMatch, searchID, adID, notified)
+	if err != nil {
+		r.logger(ctx).Error("CreateSavedSearchMatch: error with INSERT", zap.Error(err))
+		return false, mapPgError(err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// Забирает поиски с дайджестом, у которых есть неотправленные объявления и
+// последний дайджест был не меньше period ($1) назад. Совпадения и время дайджеста
+// отмечаются тем же запросом, SKIP LOCKED разводит параллельные воркеры.
+const queryTakeSavedSearchDigests = `
+WITH due AS (
+	SELECT s.id, s.user_id, s.name
+	FROM saved_searches s
+	WHERE s.notify = 'daily'
+		AND (s.last_digest_at IS NULL OR s.last_digest_at <= CURRENT_TIMESTAMP - $1::interval)
+		AND EXISTS (SELECT 1
+			FROM saved_search_matches m
+			WHERE m.saved_search_id = s.id
+				AND m.notified_at IS NULL)
+	ORDER BY s.id
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED
+), marked AS (
+	UPDATE saved_search_matches m
+	SET notified_at = CURRENT_TIMESTAMP
+	FROM due
+	WHERE m.saved_search_id = due.id
+		AND m.notified_at IS NULL
+	RETURNING m.saved_search_id, m.advertisement_id
+), digested AS (
+	UPDATE saved_searches s
+	SET last_digest_at = CURRENT_TIMESTAMP
+	FROM due
+	WHERE s.id = due.id
+)
+SELECT
+	due.id,
+	due.user_id,
+	due.name,
+	array_agg(marked.advertisement_id::bigint ORDER BY marked.advertisement_id DESC)
+FROM due
+	JOIN marked ON marked.saved_search_id = due.id
+GROUP BY due.id, due.user_id, due.name
+ORDER BY due.id;
+`
+
+func (r *Repository) TakeSavedSearchDigests(ctx context.Context, period time.Duration, limit int, digests *[]*entities.SavedSearchDigest) error {
+	rows, err := r.conn(ctx, "TakeSavedSearchDigests").Query(ctx, queryTakeSavedSearchDigests, period, limit)
+	if err != nil {
+		r.logger(ctx).Error("TakeSavedSearchDigests: error with UPDATE", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		digest := entities.SavedSearchDigest{}
+		if err := rows.Scan(
+			&digest.SavedSearch.ID,
+			&digest.SavedSearch.UserID,
+			&digest.SavedSearch.Name,
+			&digest.AdIDs,
+		); err != nil {
+			r.logger(ctx).Error("TakeSavedSearchDigests: error with scan row", zap.Error(err))
+			return err
+		}
+		*digests = append(*digests, &digest)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("TakeSavedSearchDigests: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+const (
+	// txMaxAttempts - сколько раз транзакция выполняется при конфликтах
+	txMaxAttempts = 5
+	// txRetryDelay - базовая пауза перед повтором, растет с каждой попыткой
+	txRetryDelay = 20 * time.Millisecond
+)
+
+// txKey - ключ контекста, в котором лежит открытая транзакция
+type txKey struct{}
+
+// WithTx выполняет fn в транзакции READ COMMITTED. Методы репозитория,
+// вызванные с ctx из fn, работают в этой транзакции; ошибка fn откатывает
+// все изменения. Вложенный WithTx открывает точку сохранения во внешней
+// транзакции, поэтому методы с собственной транзакцией можно вызывать из fn.
+//
+// При конфликте сериализации или взаимной блокировке транзакция повторяется
+// целиком, поэтому fn может выполниться несколько раз и не должна иметь
+// побочных эффектов вне базы. Ошибки репозитория fn возвращает как есть.
+func (r *Repository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.withTx(ctx, pgx.TxOptions{}, fn)
+}
+
+// WithSerializableTx - WithTx с уровнем изоляции SERIALIZABLE: для операций,
+// где решение принимается по прочитанным в транзакции данным
+func (r *Repository) WithSerializableTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.withTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable}, fn)
+}
+
+func (r *Repository) withTx(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context) error) error {
+	// уровень изоляции задает внешняя транзакция, повторяет ее тоже она
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return pgx.BeginFunc(ctx, tx, func(savepoint pgx.Tx) error {
+			return fn(context.WithValue(ctx, txKey{}, savepoint))
+		})
+	}
+	return r.retryTx(ctx, r.DB, opts, fn)
+}
+
+// txBeginner открывает транзакцию верхнего уровня: в работе это пул основной базы
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// retryTx выполняет fn в новой транзакции db и повторяет ее целиком при конфликтах
+func (r *Repository) retryTx(ctx context.Context, db txBeginner, opts pgx.TxOptions, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := pgx.BeginTxFunc(ctx, db, opts, func(tx pgx.Tx) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+		if err == nil || !isTxConflict(err) || attempt == txMaxAttempts {
+			return err
+		}
+		r.logger(ctx).Warn("transaction conflict, retrying", zap.Int("attempt", attempt), zap.Error(err))
+		// случайная добавка разводит повторы конкурирующих транзакций
+		delay := txRetryDelay*time.Duration(attempt) + rand.N(txRetryDelay)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isTxConflict - ошибка, после которой транзакцию можно просто повторить:
+// 40001 serialization_failure и 40P01 deadlock_detected
+func isTxConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// txJournal - общий журнал транзакции и ее точек сохранения
+type txJournal struct {
+	events []string
+	execs  []execCall
+	// execErr возвращается из Exec, если задан
+	execErr func(sql string) error
+	// query отвечает на Query
+	query func(sql string, args []interface{}) (pgx.Rows, error)
+}
+
+type execCall struct {
+	depth int
+	sql   string
+	args  []interface{}
+}
+
+// fakeTx - транзакция без базы. Begin открывает точку сохранения уровнем
+// глубже. Методы pgx.Tx, которые тесты не вызывают, паникуют.
+type fakeTx struct {
+	pgx.Tx
+	journal   *txJournal
+	depth     int
+	commitErr error
+	closed    bool
+}
+
+func (tx *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	tx.journal.events = append(tx.journal.events, fmt.Sprintf("begin %d", tx.depth+1))
+	return &fakeTx{journal: tx.journal, depth: tx.depth + 1}, nil
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	tx.closed = true
+	if tx.commitErr != nil {
+		tx.journal.events = append(tx.journal.events, fmt.Sprintf("commit %d failed", tx.depth))
+		return tx.commitErr
+	}
+	tx.journal.events = append(tx.journal.events, fmt.Sprintf("commit %d", tx.depth))
+	return nil
+}
+
+// Rollback после Commit ничего не делает, как у pgx
+func (tx *fakeTx) Rollback(context.Context) error {
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	tx.closed = true
+	tx.journal.events = append(tx.journal.events, fmt.Sprintf("rollback %d", tx.depth))
+	return nil
+}
+
+func (tx *fakeTx) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	if tx.journal.execErr != nil {
+		if err := tx.journal.execErr(sql); err != nil {
+			return pgconn.CommandTag{}, err
+		}
+	}
+	tx.journal.execs = append(tx.journal.execs, execCall{depth: tx.depth, sql: sql, args: args})
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (tx *fakeTx) Query(_ context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return tx.journal.query(sql, args)
+}
+
+// fakePool открывает транзакции верхнего уровня; commitErrs - ошибки
+// Commit по попыткам, nil - успешная попытка
+type fakePool struct {
+	journal    *txJournal
+	commitErrs []error
+	opts       []pgx.TxOptions
+}
+
+func (p *fakePool) BeginTx(_ context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	attempt := len(p.opts)
+	p.opts = append(p.opts, opts)
+	p.journal.events = append(p.journal.events, "begin 1")
+	tx := &fakeTx{journal: p.journal, depth: 1}
+	if attempt < len(p.commitErrs) {
+		tx.commitErr = p.commitErrs[attempt]
+	}
+	return tx, nil
+}
+
+func newTestRepository() *Repository {
+	return &Repository{log: zap.NewNop()}
+}
+
+// inTx - ctx с открытой внешней транзакцией, как внутри WithTx
+func inTx(ctx context.Context, journal *txJournal) context.Context {
+	return context.WithValue(ctx, txKey{}, pgx.Tx(&fakeTx{journal: journal}))
+}
+
+func TestRetryTx(t *testing.T) {
+	serialization := &pgconn.PgError{Code: "40001"}
+	deadlock := &pgconn.PgError{Code: "40P01"}
+	unique := &pgconn.PgError{Code: "23505"}
+	always := make([]error, txMaxAttempts)
+	for i := range always {
+		always[i] = serialization
+	}
+
+	tests := []struct {
+		name         string
+		commitErrs   []error
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "first attempt", wantAttempts: 1},
+		{name: "serialization failure is retried", commitErrs: []error{serialization, serialization}, wantAttempts: 3},
+		{name: "deadlock is retried", commitErrs: []error{deadlock}, wantAttempts: 2},
+		{name: "other errors are not retried", commitErrs: []error{unique}, wantAttempts: 1, wantErr: unique},
+		{name: "attempts are limited", commitErrs: always, wantAttempts: txMaxAttempts, wantErr: serialization},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			journal := &txJournal{}
+			pool := &fakePool{journal: journal, commitErrs: tt.commitErrs}
+			calls := 0
+			err := newTestRepository().retryTx(context.Background(), pool, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(ctx context.Context) error {
+				calls++
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if len(pool.opts) != tt.wantAttempts || calls != tt.wantAttempts {
+				t.Errorf("attempts = %d, fn calls = %d, want %d", len(pool.opts), calls, tt.wantAttempts)
+			}
+			for _, opts := range pool.opts {
+				if opts.IsoLevel != pgx.Serializable {
+					t.Errorf("isolation = %q, want serializable on every attempt", opts.IsoLevel)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTxStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	conflict := &pgconn.PgError{Code: "40001"}
+	pool := &fakePool{journal: &txJournal{}, commitErrs: []error{conflict, conflict}}
+	err := newTestRepository().retryTx(ctx, pool, pgx.TxOptions{}, func(context.Context) error { return nil })
+	if !errors.Is(err, conflict) || len(pool.opts) != 1 {
+		t.Errorf("err = %v after %d attempts, want conflict after 1", err, len(pool.opts))
+	}
+}
+
+func TestWithTxNested(t *testing.T) {
+	failed := errors.New("fn failed")
+	conflict := &pgconn.PgError{Code: "40001"}
+	tests := []struct {
+		name       string
+		fn         func(r *Repository) func(ctx context.Context) error
+		wantErr    error
+		wantEvents []string
+		wantDepths []int
+	}{
+		{
+			name: "savepoint is committed",
+			fn: func(r *Repository) func(ctx context.Context) error {
+				return func(ctx context.Context) error {
+					_, err := r.conn(ctx, "test").Exec(ctx, "write")
+					return err
+				}
+			},
+			wantEvents: []string{"begin 1", "commit 1"},
+			wantDepths: []int{1},
+		},
+		{
+			name: "error rolls back only the savepoint",
+			fn: func(r *Repository) func(ctx context.Context) error {
+				return func(ctx context.Context) error { return failed }
+			},
+			wantErr:    failed,
+			wantEvents: []string{"begin 1", "rollback 1"},
+		},
+		{
+			name: "conflict is left to the outer transaction",
+			fn: func(r *Repository) func(ctx context.Context) error {
+				return func(ctx context.Context) error { return conflict }
+			},
+			wantErr:    conflict,
+			wantEvents: []string{"begin 1", "rollback 1"},
+		},
+		{
+			name: "savepoints nest",
+			fn: func(r *Repository) func(ctx context.Context) error {
+				return func(ctx context.Context) error {
+					if _, err := r.conn(ctx, "test").Exec(ctx, "outer write"); err != nil {
+						return err
+					}
+					// ошибка внутренней точки не отменяет запись внешней
+					_ = r.WithTx(ctx, func(ctx context.Context) error {
+						if _, err := r.conn(ctx, "test").Exec(ctx, "inner write"); err != nil {
+							return err
+						}
+						return failed
+					})
+					return nil
+				}
+			},
+			wantEvents: []string{"begin 1", "begin 2", "rollback 2", "commit 1"},
+			wantDepths: []int{1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRepository()
+			journal := &txJournal{}
+			err := r.WithSerializableTx(inTx(context.Background(), journal), tt.fn(r))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(journal.events, tt.wantEvents) {
+				t.Errorf("events = %v, want %v", journal.events, tt.wantEvents)
+			}
+			var depths []int
+			for _, e := range journal.execs {
+				depths = append(depths, e.depth)
+			}
+			if !reflect.DeepEqual(depths, tt.wantDepths) {
+				t.Errorf("exec depths = %v, want %v", depths, tt.wantDepths)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Заявка и статус пользователя меняются одним запросом
+const queryCreateVerificationRequest = `
+WITH req AS (
+	INSERT INTO verification_requests
+		(user_id, method, document_path)
+	VALUES
+		($1, $2, $3)
+	RETURNING id, status, created_at
+), upd AS (
+	UPDATE users
+	SET verification_status = 'pending'
+	WHERE id = $1
+)
+SELECT id, status, created_at
+FROM req;
+`
+
+func (r *Repository) CreateVerificationRequest(ctx context.Context, req *entities.VerificationRequest) error {
+	if err := r.conn(ctx, "CreateVerificationRequest").QueryRow(
+		ctx,
+		queryCreateVerificationRequest,
+		req.UserID,
+		req.Method,
+		entities.NewNullString(req.DocumentPath),
+	).Scan(
+		&req.ID,
+		&req.Status,
+		&req.CreatedAt,
+	); err != nil {
+		r.logger(ctx).Error("CreateVerificationRequest: error with INSERT", zap.Error(err))
+		return mapPgError(err)
+	}
+	return nil
+}
+
+const queryVerificationRequestColumns = `
+	vr.id,
+	vr.user_id,
+	vr.method,
+	COALESCE(vr.document_path, ''),
+	vr.status,
+	COALESCE(vr.reason, ''),
+	vr.created_at,
+	COALESCE(vr.reviewed_by, 0),
+	vr.reviewed_at`
+
+func scanVerificationRequest(row pgx.Row, req *entities.VerificationRequest) error {
+	return row.Scan(
+		&req.ID,
+		&req.UserID,
+		&req.Method,
+		&req.DocumentPath,
+		&req.Status,
+		&req.Reason,
+		&req.CreatedAt,
+		&req.ReviewedBy,
+		&req.ReviewedAt,
+	)
+}
+
+const queryGetLastVerificationRequest = `
+SELECT` + queryVerificationRequestColumns + `
+FROM verification_requests vr
+WHERE vr.user_id = $1
+ORDER BY vr.created_at DESC, vr.id DESC
+LIMIT 1;
+`
+
+func (r *Repository) GetLastVerificationRequest(ctx context.Context, req *entities.VerificationRequest) error {
+	if err := scanVerificationRequest(r.conn(ctx, "GetLastVerificationRequest").QueryRow(ctx, queryGetLastVerificationRequest, req.UserID), req); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		r.logger(ctx).Error("GetLastVerificationRequest: error with QueryRow", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryGetVerificationRequests = `
+SELECT` + queryVerificationRequestColumns + `
+FROM verification_requests vr
+WHERE vr.status = $1
+ORDER BY vr.created_at, vr.id
+LIMIT $2 OFFSET $3;
+`
+
+func (r *Repository) GetVerificationRequests(ctx context.Context, status string, limit, offset uint64, reqs *[]*entities.VerificationRequest) error {
+	rows, err := r.conn(ctx, "GetVerificationRequests").Query(ctx, queryGetVerificationRequests, status, limit, offset)
+	if err != nil {
+		r.logger(ctx).Error("GetVerificationRequests: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		req := entities.VerificationRequest{}
+		if err := scanVerificationRequest(rows, &req); err != nil {
+			r.logger(ctx).Error("GetVerificationRequests: error with scan row", zap.Error(err))
+			return err
+		}
+		*reqs = append(*reqs, &req)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetVerificationRequests: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Решение принимается только по заявке в статусе pending и сразу
+// переносится в users.verification_status
+const queryReviewVerificationRequest = `
+WITH req AS (
+	UPDATE verification_requests
+	SET status = $2,
+		reason = $3,
+		reviewed_by = $4,
+		reviewed_at = CURRENT_TIMESTAMP
+	WHERE id = $1
+		AND status = 'pending'
+	RETURNING user_id, status
+)
+UPDATE users u
+SET verification_status = req.status
+FROM req
+WHERE u.id = req.user_id
+RETURNING u.id;
+`
+
+func (r *Repository) ReviewVerificationRequest(ctx context.Context, req *entities.VerificationRequest) error {
+	if err := r.conn(ctx, "ReviewVerificationRequest").QueryRow(
+		ctx,
+		queryReviewVerificationRequest,
+		req.ID,
+		req.Status,
+		entities.NewNullString(req.Reason),
+		req.ReviewedBy,
+	).Scan(&req.UserID); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		r.logger(ctx).Error("ReviewVerificationRequest: error with UPDATE", zap.Error(err))
+		return err
+	}
+	return nil
+}
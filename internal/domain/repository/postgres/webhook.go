@@ -0,0 +1,320 @@
+package postgres
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const queryCountWebhooks = `
+SELECT COUNT(*)
+FROM webhooks
+WHERE user_id = $1;
+`
+
+func (r *Repository) CountWebhooks(ctx context.Context, uID uint64) (uint64, error) {
+	var count uint64
+	if err := r.conn(ctx, "CountWebhooks").QueryRow(ctx, queryCountWebhooks, uID).Scan(&count); err != nil {
+		r.logger(ctx).Error("CountWebhooks: error with QueryRow", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+const queryCreateWebhook = `
+INSERT INTO webhooks
+	(user_id, url, secret, event_types)
+VALUES
+	($1, $2, $3, $4)
+RETURNING id, created_at;
+`
+
+func (r *Repository) CreateWebhook(ctx context.Context, webhook *entities.Webhook) error {
+	if err := r.conn(ctx, "CreateWebhook").QueryRow(
+		ctx,
+		queryCreateWebhook,
+		webhook.UserID,
+		webhook.URL,
+		webhook.Secret,
+		webhook.EventTypes,
+	).Scan(
+		&webhook.ID,
+		&webhook.CreatedAt,
+	); err != nil {
+		r.logger(ctx).Error("CreateWebhook: error with INSERT", zap.Error(err))
+		return mapPgError(err)
+	}
+	return nil
+}
+
+const queryGetWebhooks = `
+SELECT
+	id,
+	user_id,
+	url,
+	event_types,
+	created_at
+FROM webhooks
+WHERE user_id = $1
+ORDER BY id;
+`
+
+func (r *Repository) GetWebhooks(ctx context.Context, uID uint64, webhooks *[]*entities.Webhook) error {
+	rows, err := r.conn(ctx, "GetWebhooks").Query(ctx, queryGetWebhooks, uID)
+	if err != nil {
+		r.logger(ctx).Error("GetWebhooks: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		webhook := entities.Webhook{}
+		if err := rows.Scan(
+			&webhook.ID,
+			&webhook.UserID,
+			&webhook.URL,
+			&webhook.EventTypes,
+			&webhook.CreatedAt,
+		); err != nil {
+			r.logger(ctx).Error("GetWebhooks: error with scan row", zap.Error(err))
+			return err
+		}
+		*webhooks = append(*webhooks, &webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetWebhooks: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryDeleteWebhook = `
+DELETE FROM webhooks
+WHERE id = $1
+	AND user_id = $2;
+`
+
+func (r *Repository) DeleteWebhook(ctx context.Context, uID, webhookID uint64) error {
+	result, err := r.conn(ctx, "DeleteWebhook").Exec(ctx, queryDeleteWebhook, webhookID, uID)
+	if err != nil {
+		r.logger(ctx).Error("DeleteWebhook: error with DELETE", zap.Error(err))
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+const queryEnqueueWebhookEvent = `
+INSERT INTO webhook_deliveries
+	(webhook_id, event_type, event_id, payload)
+SELECT id, $2, $3, $4
+FROM webhooks
+WHERE user_id = $1
+	AND $2 = ANY(event_types)
+ON CONFLICT (webhook_id, event_id) DO NOTHING;
+`
+
+// EnqueueWebhookEvent ставит событие outbox eventID в очередь всех подписок
+// пользователя на этот тип события и возвращает число созданных отправок.
+// Для уже поставленного события отправки не дублируются.
+func (r *Repository) EnqueueWebhookEvent(ctx context.Context, uID uint64, eventType string, eventID uint64, payload []byte) (int64, error) {
+	result, err := r.conn(ctx, "EnqueueWebhookEvent").Exec(ctx, queryEnqueueWebhookEvent, uID, eventType, eventID, payload)
+	if err != nil {
+		r.logger(ctx).Error("EnqueueWebhookEvent: error with INSERT", zap.Error(err))
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+// Берет готовые к отправке и сразу откладывает их на следующий шаг
+// экспоненциальной паузы: base * 2^попытка, но не больше max. Если воркер
+// упадет посреди отправки, она повторится в это время.
+const queryTakeWebhookDeliveries = `
+WITH due AS (
+	SELECT id
+	FROM webhook_deliveries
+	WHERE status = 'pending'
+		AND next_attempt_at <= CURRENT_TIMESTAMP
+	ORDER BY next_attempt_at
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED
+)
+UPDATE webhook_deliveries d
+SET attempts = d.attempts + 1,
+	next_attempt_at = CURRENT_TIMESTAMP + LEAST($2::interval * power(2, d.attempts), $3::interval)
+FROM due, webhooks w
+WHERE d.id = due.id
+	AND w.id = d.webhook_id
+RETURNING d.id, d.webhook_id, d.event_type, d.payload, d.attempts, w.url, w.secret;
+`
+
+func (r *Repository) TakeWebhookDeliveries(ctx context.Context, limit int, baseDelay, maxDelay time.Duration, deliveries *[]*entities.WebhookDelivery) error {
+	rows, err := r.conn(ctx, "TakeWebhookDeliveries").Query(ctx, queryTakeWebhookDeliveries, limit, baseDelay, maxDelay)
+	if err != nil {
+		r.logger(ctx).Error("TakeWebhookDeliveries: error with UPDATE", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery := entities.WebhookDelivery{}
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.Attempts,
+			&delivery.URL,
+			&delivery.Secret,
+		); err != nil {
+			r.logger(ctx).Error("TakeWebhookDeliveries: error with scan row", zap.Error(err))
+			return err
+		}
+		*deliveries = append(*deliveries, &delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("TakeWebhookDeliveries: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const querySetWebhookDeliveryResult = `
+UPDATE webhook_deliveries
+SET status = $2,
+	last_status_code = $3,
+	last_error = $4,
+	delivered_at = CASE WHEN $2 = 'delivered' THEN CURRENT_TIMESTAMP END
+WHERE id = $1;
+`
+
+// SetWebhookDeliveryResult: delivered, failed или pending для повтора
+func (r *Repository) SetWebhookDeliveryResult(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	var statusCode *int
+	if delivery.LastStatusCode != 0 {
+		statusCode = &delivery.LastStatusCode
+	}
+	if _, err := r.conn(ctx, "SetWebhookDeliveryResult").Exec(
+		ctx,
+		querySetWebhookDeliveryResult,
+		delivery.ID,
+		delivery.Status,
+		statusCode,
+		entities.NewNullString(delivery.LastError),
+	); err != nil {
+		r.logger(ctx).Error("SetWebhookDeliveryResult: error with UPDATE", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryGetWebhookDeliveries = `
+SELECT
+	d.id,
+	d.webhook_id,
+	d.event_type,
+	d.payload,
+	d.status,
+	d.attempts,
+	d.next_attempt_at,
+	COALESCE(d.last_status_code, 0),
+	COALESCE(d.last_error, ''),
+	d.created_at,
+	d.delivered_at
+FROM webhook_deliveries d
+	JOIN webhooks w ON d.webhook_id = w.id
+WHERE w.user_id = $1
+	AND ($2 = 0 OR d.webhook_id = $2)
+	AND ($3 = '' OR d.status = $3)
+ORDER BY d.created_at DESC, d.id DESC
+LIMIT $4 OFFSET $5;
+`
+
+// GetWebhookDeliveries - журнал отправок по подпискам пользователя;
+// webhookID и status необязательны
+func (r *Repository) GetWebhookDeliveries(ctx context.Context, uID, webhookID uint64, status string, limit, offset uint64, deliveries *[]*entities.WebhookDelivery) error {
+	rows, err := r.conn(ctx, "GetWebhookDeliveries").Query(ctx, queryGetWebhookDeliveries, uID, webhookID, status, limit, offset)
+	if err != nil {
+		r.logger(ctx).Error("GetWebhookDeliveries: error with SELECT FROM", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery := entities.WebhookDelivery{}
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventType,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&delivery.LastStatusCode,
+			&delivery.LastError,
+			&delivery.CreatedAt,
+			&delivery.DeliveredAt,
+		); err != nil {
+			r.logger(ctx).Error("GetWebhookDeliveries: error with scan row", zap.Error(err))
+			return err
+		}
+		*deliveries = append(*deliveries, &delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger(ctx).Error("GetWebhookDeliveries: error iterating through rows", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const queryGetWebhookDeliveryStatus = `
+SELECT d.status
+FROM webhook_deliveries d
+	JOIN webhooks w ON d.webhook_id = w.id
+WHERE d.id = $1
+	AND w.user_id = $2;
+`
+
+func (r *Repository) GetWebhookDeliveryStatus(ctx context.Context, uID, deliveryID uint64) (string, error) {
+	var status string
+	if err := r.conn(ctx, "GetWebhookDeliveryStatus").QueryRow(ctx, queryGetWebhookDeliveryStatus, deliveryID, uID).Scan(&status); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", ErrNotFound
+		}
+		r.logger(ctx).Error("GetWebhookDeliveryStatus: error with QueryRow", zap.Error(err))
+		return "", err
+	}
+	return status, nil
+}
+
+const queryReplayWebhookDelivery = `
+UPDATE webhook_deliveries
+SET status = 'pending',
+	attempts = 0,
+	next_attempt_at = CURRENT_TIMESTAMP,
+	last_status_code = NULL,
+	last_error = NULL
+WHERE id = $1
+	AND status = 'failed';
+`
+
+// ReplayWebhookDelivery возвращает проваленную отправку в очередь с нуля попыток
+func (r *Repository) ReplayWebhookDelivery(ctx context.Context, deliveryID uint64) error {
+	result, err := r.conn(ctx, "ReplayWebhookDelivery").Exec(ctx, queryReplayWebhookDelivery, deliveryID)
+	if err != nil {
+		r.logger(ctx).Error("ReplayWebhookDelivery: error with UPDATE", zap.Error(err))
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -1,14 +1,19 @@
 package repository
 
 import (
-	"go.uber.org/fx"
 	"backend/internal/domain/repository/postgres"
+	"backend/internal/health"
+	"go.uber.org/fx"
 )
 
 func New() fx.Option {
 	return fx.Module("repository",
 		fx.Provide(
 			postgres.NewRepository,
+			fx.Annotate(
+				func(r *postgres.Repository) health.Checker { return r },
+				fx.ResultTags(health.Group),
+			),
 		),
 		fx.Invoke(
 			func(lc fx.Lifecycle, a *postgres.Repository) {
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"backend/internal/domain/entities"
+	"backend/internal/rbac"
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+// Authorize проверяет право пользователя по его роли из user_roles
+func (uc *Usecase) Authorize(ctx context.Context, uID uint64, perm rbac.Permission) error {
+	user := &entities.User{ID: uID}
+	if exist, err := uc.Repo.IsUserExist(ctx, user); err != nil || !exist {
+		uc.logger(ctx).Error("user does not exist", zap.Error(err))
+		return ErrForbidden
+	}
+	if err := uc.Repo.GetUserInfo(ctx, user); err != nil {
+		uc.logger(ctx).Error("fail to get user role", zap.Error(err))
+		return err
+	}
+	if !rbac.Can(rbac.Role(user.Role.Name), perm) {
+		uc.logger(ctx).Warn("permission denied",
+			zap.Uint64("user_id", uID),
+			zap.String("role", user.Role.Name),
+			zap.String("permission", string(perm)),
+		)
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,429 @@
+package usecase
+
+import (
+	"backend/internal/domain/entities"
+	"backend/internal/domain/repository/postgres"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxAdNameLen        = 50
+	maxAdDescriptionLen = 255
+	maxAdLocationLen    = 50
+	// numeric(10, 2)
+	maxAdPrice = 99999999.99
+
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchRadiusKm  = 1000
+)
+
+func validateAdvertisment(advertisment *entities.Advertisment) error {
+	advertisment.Name = strings.TrimSpace(advertisment.Name)
+	advertisment.Description = strings.TrimSpace(advertisment.Description)
+	advertisment.Location = strings.TrimSpace(advertisment.Location)
+	switch {
+	case advertisment.Name == "" || utf8.RuneCountInString(advertisment.Name) > maxAdNameLen,
+		utf8.RuneCountInString(advertisment.Description) > maxAdDescriptionLen,
+		utf8.RuneCountInString(advertisment.Location) > maxAdLocationLen,
+		advertisment.Price < 0 || advertisment.Price > maxAdPrice,
+		advertisment.AdvertismentCategory.ID == 0:
+		return ErrInvalidData
+	}
+	for _, photo := range advertisment.Photos {
+		if strings.TrimSpace(photo.Path) == "" {
+			return ErrInvalidData
+		}
+	}
+	// точка задается целиком или не задается
+	if (advertisment.Latitude == nil) != (advertisment.Longitude == nil) {
+		return ErrInvalidData
+	}
+	if advertisment.Latitude != nil && !validCoordinates(*advertisment.Latitude, *advertisment.Longitude) {
+		return ErrInvalidData
+	}
+	return nil
+}
+
+// validatePublication: новое объявление публикуется сразу, сохраняется
+// черновиком или ставится на публикацию в будущем по PublishAt
+func validatePublication(advertisment *entities.Advertisment, now time.Time) error {
+	switch advertisment.Status {
+	case "":
+		advertisment.Status = entities.AdStatusActive
+		fallthrough
+	case entities.AdStatusActive, entities.AdStatusDraft:
+		advertisment.PublishAt = nil
+	case entities.AdStatusScheduled:
+		if advertisment.PublishAt == nil || !advertisment.PublishAt.After(now) {
+			return ErrInvalidData
+		}
+	default:
+		return ErrInvalidData
+	}
+	return nil
+}
+
+// prepareAdvertisment проверяет поля, категорию и значения ее атрибутов
+func (uc *Usecase) prepareAdvertisment(ctx context.Context, advertisment *entities.Advertisment, attributes map[string]string) error {
+	if err := validateAdvertisment(advertisment); err != nil {
+		return err
+	}
+	if active, err := uc.Repo.IsCategoryActive(ctx, advertisment.AdvertismentCategory.ID); err != nil || !active {
+		uc.logger(ctx).Error("category is not available", zap.Uint64("category_id", advertisment.AdvertismentCategory.ID), zap.Error(err))
+		return ErrInvalidData
+	}
+	if advertisment.City.ID != 0 {
+		if exist, err := uc.Repo.IsCityExist(ctx, advertisment.City.ID); err != nil || !exist {
+			uc.logger(ctx).Error("city does not exist", zap.Uint64("city_id", advertisment.City.ID), zap.Error(err))
+			return ErrInvalidData
+		}
+	}
+	var defs []*entities.CategoryAttribute
+	if err := uc.Repo.GetCategoryAttributes(ctx, advertisment.AdvertismentCategory.ID, &defs); err != nil {
+		uc.logger(ctx).Error("fail to get category attributes", zap.Error(err))
+		return err
+	}
+	values, err := validateAttributeValues(defs, attributes)
+	if err != nil {
+		uc.logger(ctx).Info("invalid advertisment attributes", zap.Error(err))
+		return err
+	}
+	advertisment.Attributes = values
+	return nil
+}
+
+func (uc *Usecase) CreateAdvertisment(ctx context.Context, advertisment *entities.Advertisment, attributes map[string]string) error {
+	ctx, span := tracer.Start(ctx, "Usecase.CreateAdvertisment")
+	defer span.End()
+	if exist, err := uc.Repo.IsUserExist(ctx, &advertisment.User); err != nil || !exist {
+		uc.logger(ctx).Error("user does not exist", zap.Error(err))
+		return ErrForbidden
+	}
+	if err := validatePublication(advertisment, time.Now()); err != nil {
+		return err
+	}
+	if err := uc.prepareAdvertisment(ctx, advertisment, attributes); err != nil {
+		return err
+	}
+	flags, err := uc.precheckAdvertisment(ctx, advertisment)
+	if err != nil {
+		return err
+	}
+	advertisment.Moderation.Flags = flags
+	// объявление без атрибутов или фотографий не должно остаться в базе
+	if err = uc.Repo.WithTx(ctx, func(ctx context.Context) error {
+		if err := uc.Repo.CreateAdvertisment(ctx, advertisment); err != nil {
+			uc.logger(ctx).Error("fail to create advertisment", zap.Error(err))
+			return err
+		}
+		if err := uc.Repo.SetAdvertismentAttributes(ctx, advertisment); err != nil {
+			uc.logger(ctx).Error("fail to set advertisment attributes", zap.Error(err))
+			return err
+		}
+		if err := uc.Repo.SetAdvertismentPhotos(ctx, advertisment); err != nil {
+			uc.logger(ctx).Error("fail to set advertisment photos", zap.Error(err))
+			return err
+		}
+		return nil
+	}); err != nil {
+		return mapRepoError(err)
+	}
+	uc.metrics.AdsCreated.Inc()
+	return nil
+}
+
+func (uc *Usecase) UpdateAdvertisment(ctx context.Context, uID uint64, advertisment *entities.Advertisment, attributes map[string]string) error {
+	ctx, span := tracer.Start(ctx, "Usecase.UpdateAdvertisment")
+	defer span.End()
+	// черновики и отложенные объявления тоже дописываются владельцем
+	ownerID, err := uc.advertismentOwner(ctx, advertisment.ID, entities.AdStatusActive, entities.AdStatusDraft, entities.AdStatusScheduled)
+	if err != nil {
+		return err
+	}
+	if ownerID != uID {
+		return ErrForbidden
+	}
+	advertisment.User.ID = ownerID
+	if err = uc.prepareAdvertisment(ctx, advertisment, attributes); err != nil {
+		return err
+	}
+	// после правки объявление снова проходит модерацию
+	if advertisment.Moderation.Flags, err = uc.precheckAdvertisment(ctx, advertisment); err != nil {
+		return err
+	}
+	if err = uc.Repo.WithTx(ctx, func(ctx context.Context) error {
+		if err := uc.Repo.UpdateAdvertisment(ctx, advertisment); err != nil {
+			uc.logger(ctx).Error("fail to update advertisment", zap.Error(err))
+			return err
+		}
+		if err := uc.Repo.SetAdvertismentAttributes(ctx, advertisment); err != nil {
+			uc.logger(ctx).Error("fail to set advertisment attributes", zap.Error(err))
+			return err
+		}
+		if err := uc.Repo.SetAdvertismentPhotos(ctx, advertisment); err != nil {
+			uc.logger(ctx).Error("fail to set advertisment photos", zap.Error(err))
+			return err
+		}
+		return nil
+	}); err != nil {
+		return mapRepoError(err)
+	}
+	uc.invalidate(ctx, adCacheKey(advertisment.ID))
+	return nil
+}
+
+// AdvertismentUpdatedAt - версия карточки объявления для условных запросов:
+// по ней отвечают 304, не собирая карточку. Невидимое зрителю объявление не
+// найдено, иначе 304 выдал бы существование черновика или скрытого объявления.
+func (uc *Usecase) AdvertismentUpdatedAt(ctx context.Context, viewerID, adID uint64) (time.Time, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.AdvertismentUpdatedAt")
+	defer span.End()
+	advertisment := &entities.Advertisment{ID: adID}
+	updatedAt, err := uc.Repo.GetAdvertismentUpdatedAt(ctx, advertisment)
+	if err != nil {
+		return updatedAt, mapRepoError(err)
+	}
+	if !uc.canViewAdvertisment(ctx, viewerID, advertisment) {
+		return time.Time{}, ErrNotFound
+	}
+	return updatedAt, nil
+}
+
+// advertismentOwner возвращает владельца, если объявление в одном из
+// статусов statuses. Архивное и удаленное сначала восстанавливаются.
+func (uc *Usecase) advertismentOwner(ctx context.Context, adID uint64, statuses ...string) (uint64, error) {
+	ownerID, status, err := uc.Repo.GetAdvertismentStatus(ctx, adID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to get advertisment status", zap.Error(err))
+		return 0, mapRepoError(err)
+	}
+	if !slices.Contains(statuses, status) {
+		return 0, ErrConflict
+	}
+	return ownerID, nil
+}
+
+// Переходы статуса объявления: в архив - из активного, удалить - из
+// любого, кроме удаленного, восстановить - из архива или удаленного.
+// Публикация черновика - PublishAdvertisment.
+var adStatusTransitions = map[string][]string{
+	entities.AdStatusArchived: {entities.AdStatusActive},
+	entities.AdStatusDeleted:  {entities.AdStatusDraft, entities.AdStatusScheduled, entities.AdStatusActive, entities.AdStatusArchived},
+	entities.AdStatusActive:   {entities.AdStatusArchived, entities.AdStatusDeleted},
+}
+
+// adStatusEvents - доменное событие для каждого перехода из adStatusTransitions
+var adStatusEvents = map[string]string{
+	entities.AdStatusArchived: entities.EventAdArchived,
+	entities.AdStatusDeleted:  entities.EventAdDeleted,
+	entities.AdStatusActive:   entities.EventAdRestored,
+}
+
+// ChangeAdvertismentStatus архивирует, мягко удаляет или восстанавливает
+// объявление владельца. Запись не удаляется: сделки, отзывы и статистика
+// покупателя продолжают на нее ссылаться.
+func (uc *Usecase) ChangeAdvertismentStatus(ctx context.Context, uID uint64, advertisment *entities.Advertisment) error {
+	ctx, span := tracer.Start(ctx, "Usecase.ChangeAdvertismentStatus")
+	defer span.End()
+	allowed, ok := adStatusTransitions[advertisment.Status]
+	if !ok {
+		return ErrInvalidData
+	}
+	ownerID, from, err := uc.Repo.GetAdvertismentStatus(ctx, advertisment.ID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to get advertisment status", zap.Error(err))
+		return mapRepoError(err)
+	}
+	if ownerID != uID {
+		return ErrForbidden
+	}
+	if !slices.Contains(allowed, from) {
+		return ErrConflict
+	}
+	event := newAdvertismentEvent(adStatusEvents[advertisment.Status], entities.AdvertismentEvent{
+		AdID:           advertisment.ID,
+		UserID:         ownerID,
+		Status:         advertisment.Status,
+		PreviousStatus: from,
+	})
+	if err = uc.Repo.SetAdvertismentStatus(ctx, advertisment, from, event); err != nil {
+		uc.logger(ctx).Error("fail to change advertisment status", zap.Error(err))
+		// статус успели поменять параллельным запросом
+		if errors.Is(err, postgres.ErrNotFound) {
+			return ErrConflict
+		}
+		return err
+	}
+	uc.invalidate(ctx, adCacheKey(advertisment.ID))
+	uc.logger(ctx).Info("advertisment status changed",
+		zap.Uint64("ad_id", advertisment.ID),
+		zap.String("from", from),
+		zap.String("to", advertisment.Status),
+	)
+	return nil
+}
+
+// PublishAdvertisment публикует черновик или отложенное объявление: без
+// PublishAt или с прошедшим временем - сразу, иначе ставит на PublishAt
+func (uc *Usecase) PublishAdvertisment(ctx context.Context, uID uint64, advertisment *entities.Advertisment) error {
+	ctx, span := tracer.Start(ctx, "Usecase.PublishAdvertisment")
+	defer span.End()
+	ownerID, from, err := uc.Repo.GetAdvertismentStatus(ctx, advertisment.ID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to get advertisment status", zap.Error(err))
+		return mapRepoError(err)
+	}
+	if ownerID != uID {
+		return ErrForbidden
+	}
+	if from != entities.AdStatusDraft && from != entities.AdStatusScheduled {
+		return ErrConflict
+	}
+	advertisment.Status = entities.AdStatusScheduled
+	if advertisment.PublishAt == nil || !advertisment.PublishAt.After(time.Now()) {
+		advertisment.Status = entities.AdStatusActive
+		advertisment.PublishAt = nil
+	}
+	eventType := entities.EventAdScheduled
+	if advertisment.Status == entities.AdStatusActive {
+		eventType = entities.EventAdPublished
+	}
+	event := newAdvertismentEvent(eventType, entities.AdvertismentEvent{
+		AdID:      advertisment.ID,
+		UserID:    ownerID,
+		Status:    advertisment.Status,
+		PublishAt: advertisment.PublishAt,
+	})
+	if err = uc.Repo.PublishAdvertisment(ctx, advertisment, from, event); err != nil {
+		uc.logger(ctx).Error("fail to publish advertisment", zap.Error(err))
+		if errors.Is(err, postgres.ErrNotFound) {
+			return ErrConflict
+		}
+		return err
+	}
+	uc.invalidate(ctx, adCacheKey(advertisment.ID))
+	uc.logger(ctx).Info("advertisment published",
+		zap.Uint64("ad_id", advertisment.ID),
+		zap.String("status", advertisment.Status),
+	)
+	return nil
+}
+
+// PublishScheduledAdvertisments публикует отложенные объявления, время которых
+// пришло, пачками по batchSize. Возвращает число опубликованных.
+func (uc *Usecase) PublishScheduledAdvertisments(ctx context.Context, batchSize int) (int, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.PublishScheduledAdvertisments")
+	defer span.End()
+	total := 0
+	for {
+		ads := []*entities.Advertisment{}
+		if err := uc.Repo.PublishScheduledAdvertisments(ctx, batchSize, &ads, scheduledPublishedEvent); err != nil {
+			uc.logger(ctx).Error("fail to publish scheduled advertisments", zap.Error(err))
+			return total, err
+		}
+		total += len(ads)
+		for _, ad := range ads {
+			uc.invalidate(ctx, adCacheKey(ad.ID))
+			uc.logger(ctx).Info("scheduled advertisment published", zap.Uint64("ad_id", ad.ID))
+		}
+		if len(ads) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+func scheduledPublishedEvent(ad *entities.Advertisment) entities.OutboxEvent {
+	return newAdvertismentEvent(entities.EventAdPublished, entities.AdvertismentEvent{
+		AdID:   ad.ID,
+		UserID: ad.User.ID,
+		Status: entities.AdStatusActive,
+	})
+}
+
+// PromoteAdvertisment подключает владельцу объявления тип продвижения.
+// Типы с VerifiedOnly доступны только верифицированным продавцам.
+func (uc *Usecase) PromoteAdvertisment(ctx context.Context, uID uint64, advertisment *entities.Advertisment) error {
+	ctx, span := tracer.Start(ctx, "Usecase.PromoteAdvertisment")
+	defer span.End()
+	ownerID, err := uc.advertismentOwner(ctx, advertisment.ID, entities.AdStatusActive)
+	if err != nil {
+		return err
+	}
+	if ownerID != uID {
+		return ErrForbidden
+	}
+	tp := &advertisment.TypePromotion
+	if err = uc.Repo.GetPromotionType(ctx, tp); err != nil {
+		uc.logger(ctx).Error("fail to get promotion type", zap.Error(err))
+		return mapRepoError(err)
+	}
+	if !tp.IsActive {
+		return ErrInvalidData
+	}
+	if tp.VerifiedOnly {
+		user := &entities.User{ID: uID}
+		if err = uc.Repo.GetUserInfo(ctx, user); err != nil {
+			uc.logger(ctx).Error("fail to get user info", zap.Error(err))
+			return err
+		}
+		if !user.Verified {
+			uc.logger(ctx).Info("promotion type requires verified seller",
+				zap.Uint64("user_id", uID),
+				zap.Uint64("type_id", tp.ID),
+			)
+			return ErrForbidden
+		}
+	}
+	if err = uc.Repo.SetAdvertismentPromotion(ctx, advertisment, tp.TimeLive); err != nil {
+		uc.logger(ctx).Error("fail to promote advertisment", zap.Error(err))
+		return mapRepoError(err)
+	}
+	uc.invalidate(ctx, adCacheKey(advertisment.ID))
+	uc.metrics.PromotionsBought.WithLabelValues(tp.Name).Inc()
+	return nil
+}
+
+func validateSearchFilter(filter *entities.AdvertismentFilter) error {
+	switch filter.Sort {
+	case "", entities.SortDateDesc, entities.SortPriceAsc, entities.SortPriceDesc, entities.SortDistance:
+	default:
+		return ErrInvalidData
+	}
+	if err := validateGeoFilter(filter); err != nil {
+		return err
+	}
+	for _, attr := range filter.Attributes {
+		if !attributeCodeRe.MatchString(attr.Code) {
+			return ErrInvalidData
+		}
+	}
+	return nil
+}
+
+func (uc *Usecase) SearchAdvertisments(ctx context.Context, filter *entities.AdvertismentFilter) (*[]*entities.AdvertisementPreview, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.SearchAdvertisments")
+	defer span.End()
+	if filter.Limit == 0 {
+		filter.Limit = defaultSearchLimit
+	}
+	if filter.Limit > maxSearchLimit {
+		filter.Limit = maxSearchLimit
+	}
+	if err := validateSearchFilter(filter); err != nil {
+		return nil, err
+	}
+	ads := []*entities.AdvertisementPreview{}
+	if err := uc.Repo.SearchAdvertisments(ctx, filter, &ads); err != nil {
+		uc.logger(ctx).Error("fail to search advertisments", zap.Error(err))
+		return nil, err
+	}
+	return &ads, nil
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"backend/config"
+	"backend/internal/domain/entities"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAdvertismentUpdatedAtVisibility(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		moderation string
+		hidden     bool
+		viewerID   uint64
+		wantErr    error
+	}{
+		{name: "published", status: entities.AdStatusActive, moderation: entities.ModerationApproved},
+		{name: "draft for anonymous", status: entities.AdStatusDraft, moderation: entities.ModerationApproved, wantErr: ErrNotFound},
+		{name: "draft for owner", status: entities.AdStatusDraft, moderation: entities.ModerationApproved, viewerID: testUserID},
+		{name: "hidden for anonymous", status: entities.AdStatusActive, moderation: entities.ModerationApproved, hidden: true, wantErr: ErrNotFound},
+		{name: "pending for anonymous", status: entities.AdStatusActive, moderation: entities.ModerationPending, wantErr: ErrNotFound},
+		{name: "rejected for owner", status: entities.AdStatusActive, moderation: entities.ModerationRejected, viewerID: testUserID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newDBUsecase(t, nil, &config.ConfigModel{})
+			ctx := context.Background()
+			var adID uint64
+			if err := uc.Repo.DB.QueryRow(ctx, `
+WITH category AS (
+	INSERT INTO categories_product (name) VALUES ('usecase_test')
+	ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+	RETURNING id
+)
+INSERT INTO advertisements (user_id, name, price, category_id, status, moderation_status, is_hidden)
+SELECT $1, 'test', 1, id, $2, $3, $4 FROM category
+RETURNING id`, testUserID, tt.status, tt.moderation, tt.hidden).Scan(&adID); err != nil {
+				t.Fatal(err)
+			}
+
+			updatedAt, err := uc.AdvertismentUpdatedAt(ctx, tt.viewerID, adID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AdvertismentUpdatedAt() error = %v, want %v", err, tt.wantErr)
+			}
+			// без доступа версия не отдается: по ней ответили бы 304
+			if (err == nil) == updatedAt.IsZero() {
+				t.Errorf("AdvertismentUpdatedAt() = %v with error %v", updatedAt, err)
+			}
+		})
+	}
+}
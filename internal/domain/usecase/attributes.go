@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"backend/internal/domain/entities"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxAttributeValueLen = 255
+
+var attributeCodeRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+func validateCategoryAttribute(attr *entities.CategoryAttribute) error {
+	var err error
+	if attr.Name, err = validateCatalogName(attr.Name); err != nil {
+		return err
+	}
+	if !attributeCodeRe.MatchString(attr.Code) {
+		return fmt.Errorf("%w: attribute code %q", ErrInvalidData, attr.Code)
+	}
+	switch attr.ValueType {
+	case entities.AttributeTypeString, entities.AttributeTypeInt, entities.AttributeTypeFloat, entities.AttributeTypeBool:
+		attr.Options = nil
+	case entities.AttributeTypeEnum:
+		if len(attr.Options) == 0 {
+			return fmt.Errorf("%w: enum attribute %q has no options", ErrInvalidData, attr.Code)
+		}
+	default:
+		return fmt.Errorf("%w: unknown attribute type %q", ErrInvalidData, attr.ValueType)
+	}
+	return nil
+}
+
+// validateAttributeValues проверяет значения объявления по атрибутам его
+// категории и возвращает их в нормализованном виде
+func validateAttributeValues(defs []*entities.CategoryAttribute, values map[string]string) ([]entities.AdvertismentAttribute, error) {
+	byCode := make(map[string]*entities.CategoryAttribute, len(defs))
+	for _, def := range defs {
+		byCode[def.Code] = def
+	}
+	for code := range values {
+		if _, ok := byCode[code]; !ok {
+			return nil, fmt.Errorf("%w: unknown attribute %q", ErrInvalidData, code)
+		}
+	}
+
+	res := make([]entities.AdvertismentAttribute, 0, len(values))
+	for _, def := range defs {
+		raw, ok := values[def.Code]
+		raw = strings.TrimSpace(raw)
+		if !ok || raw == "" {
+			if def.IsRequired {
+				return nil, fmt.Errorf("%w: attribute %q is required", ErrInvalidData, def.Code)
+			}
+			continue
+		}
+		attr := entities.AdvertismentAttribute{
+			AttributeID: def.ID,
+			Code:        def.Code,
+			Name:        def.Name,
+		}
+		value, num, err := normalizeAttributeValue(def, raw)
+		if err != nil {
+			return nil, err
+		}
+		attr.Value = value
+		attr.NumValue = num
+		res = append(res, attr)
+	}
+	return res, nil
+}
+
+func normalizeAttributeValue(def *entities.CategoryAttribute, raw string) (string, *float64, error) {
+	invalid := fmt.Errorf("%w: invalid value %q for %s attribute %q", ErrInvalidData, raw, def.ValueType, def.Code)
+	switch def.ValueType {
+	case entities.AttributeTypeInt:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return "", nil, invalid
+		}
+		num := float64(v)
+		return strconv.FormatInt(v, 10), &num, nil
+	case entities.AttributeTypeFloat:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return "", nil, invalid
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64), &v, nil
+	case entities.AttributeTypeBool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", nil, invalid
+		}
+		return strconv.FormatBool(v), nil, nil
+	case entities.AttributeTypeEnum:
+		for _, option := range def.Options {
+			if option == raw {
+				return raw, nil, nil
+			}
+		}
+		return "", nil, invalid
+	default:
+		if utf8.RuneCountInString(raw) > maxAttributeValueLen {
+			return "", nil, invalid
+		}
+		return raw, nil, nil
+	}
+}
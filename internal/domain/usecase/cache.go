@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// cacheLoadTimeout ограничивает загрузку в cached, чтобы она закончилась
+	// раньше, чем истечет метка invalidate
+	cacheLoadTimeout  = 5 * time.Second
+	cacheTombstoneTTL = 2 * cacheLoadTimeout
+)
+
+// Ключи кеша ответов: вид записи до двоеточия, дальше id
+func adCacheKey(adID uint64) string {
+	return "ad:" + strconv.FormatUint(adID, 10)
+}
+
+func profileCacheKey(uID uint64) string {
+	return "profile:" + strconv.FormatUint(uID, 10)
+}
+
+func categoriesCacheKey(onlyActive bool) string {
+	if onlyActive {
+		return "categories:active"
+	}
+	return "categories:all"
+}
+
+func cacheKind(key string) string {
+	kind, _, _ := strings.Cut(key, ":")
+	return kind
+}
+
+// cached возвращает значение key из кеша или загружает его load и кладет в
+// кеш на ttl. Одновременные промахи по одному ключу ждут одну загрузку.
+// load читает из основной базы: отстающая реплика не должна закрепить в кеше
+// старые данные на весь ttl. Ошибки load не кешируются. Результат кладется
+// только на пустое место: пока лежит метка invalidate, загрузка, начатая до
+// изменения, кеш не перезапишет.
+func cached[T any](ctx context.Context, uc *Usecase, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	var res T
+	if !uc.cfg.Cache.Enabled {
+		return load(ctx)
+	}
+	kind := cacheKind(key)
+	data, ok, err := uc.cache.Get(ctx, key)
+	switch {
+	case err != nil:
+		// недоступный кеш не должен ронять API
+		uc.logger(ctx).Warn("fail to get from cache", zap.String("key", key), zap.Error(err))
+		uc.metrics.CacheLookups.WithLabelValues(kind, "error").Inc()
+	case ok && len(data) > 0:
+		if err = json.Unmarshal(data, &res); err == nil {
+			uc.metrics.CacheLookups.WithLabelValues(kind, "hit").Inc()
+			return res, nil
+		}
+		// запись старого формата после обновления - как промах
+		uc.logger(ctx).Warn("fail to decode cached value", zap.String("key", key), zap.Error(err))
+		uc.metrics.CacheLookups.WithLabelValues(kind, "miss").Inc()
+	default:
+		uc.metrics.CacheLookups.WithLabelValues(kind, "miss").Inc()
+	}
+
+	// загрузку не должна отменить отмена запроса, который пришел первым:
+	// ее результат ждут и другие запросы
+	shared, err, _ := uc.flight.Do(key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(ReadPrimary(context.WithoutCancel(ctx)), cacheLoadTimeout)
+		defer cancel()
+		value, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = uc.cache.Add(loadCtx, key, data, ttl); err != nil {
+			uc.logger(ctx).Warn("fail to put into cache", zap.String("key", key), zap.Error(err))
+		}
+		return data, nil
+	})
+	if err != nil {
+		return res, err
+	}
+	// каждый получает свою копию, общий результат никто не изменит
+	err = json.Unmarshal(shared.([]byte), &res)
+	return res, err
+}
+
+// invalidate сбрасывает записи после изменения. Вместо удаления кладется
+// пустая метка на cacheTombstoneTTL: загрузка, успевшая прочитать старые
+// данные, не сможет положить их обратно. Ошибка только логируется: запись
+// доживет до своего ttl.
+func (uc *Usecase) invalidate(ctx context.Context, keys ...string) {
+	if !uc.cfg.Cache.Enabled {
+		return
+	}
+	// новый промах не должен присоединиться к загрузке, начатой до изменения
+	for _, key := range keys {
+		uc.flight.Forget(key)
+	}
+	for _, key := range keys {
+		if err := uc.cache.Set(ctx, key, []byte{}, cacheTombstoneTTL); err != nil {
+			uc.logger(ctx).Warn("fail to invalidate cache", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// invalidateUser сбрасывает профиль пользователя и карточки всех его
+// объявлений: в карточке показываются данные продавца
+func (uc *Usecase) invalidateUser(ctx context.Context, uID uint64) {
+	adIDs := uc.advertismentIDsToInvalidate(ctx, uc.Repo.GetUserAdvertismentIDs, uID)
+	uc.invalidateAdvertisments(ctx, adIDs, profileCacheKey(uID))
+}
+
+// advertismentIDsToInvalidate - объявления, в карточках которых показывается
+// запись id. Без кеша база не опрашивается. Ошибка только логируется:
+// карточки доживут до своего ttl.
+func (uc *Usecase) advertismentIDsToInvalidate(ctx context.Context, lookup func(ctx context.Context, id uint64) ([]uint64, error), id uint64) []uint64 {
+	if !uc.cfg.Cache.Enabled {
+		return nil
+	}
+	adIDs, err := lookup(ctx, id)
+	if err != nil {
+		uc.logger(ctx).Warn("fail to get advertisments for cache invalidation", zap.Uint64("id", id), zap.Error(err))
+	}
+	return adIDs
+}
+
+// invalidateAdvertisments сбрасывает карточки объявлений adIDs вместе с keys
+func (uc *Usecase) invalidateAdvertisments(ctx context.Context, adIDs []uint64, keys ...string) {
+	for _, adID := range adIDs {
+		keys = append(keys, adCacheKey(adID))
+	}
+	uc.invalidate(ctx, keys...)
+}
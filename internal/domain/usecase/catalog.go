@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"backend/internal/domain/entities"
+	"backend/internal/domain/repository/postgres"
+	"backend/internal/rbac"
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const maxCatalogNameLen = 50
+
+var (
+	ErrInvalidData = errors.New("invalid data")
+	// ErrConflict - дубликат имени или удаление записи, на которую ссылаются объявления
+	ErrConflict = errors.New("conflict with existing data")
+	ErrNotFound = errors.New("not found")
+)
+
+func validateCatalogName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxCatalogNameLen {
+		return "", ErrInvalidData
+	}
+	return name, nil
+}
+
+func mapRepoError(err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrDuplicate), errors.Is(err, postgres.ErrReferenced):
+		return ErrConflict
+	case errors.Is(err, postgres.ErrNotFound):
+		return ErrNotFound
+	}
+	return err
+}
+
+func (uc *Usecase) GetCategories(ctx context.Context, onlyActive bool) (*[]*entities.AdvertismentCategory, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetCategories")
+	defer span.End()
+	categories, err := cached(ctx, uc, categoriesCacheKey(onlyActive), uc.cfg.Cache.CategoriesTTL,
+		func(ctx context.Context) ([]*entities.AdvertismentCategory, error) {
+			categories := []*entities.AdvertismentCategory{}
+			if err := uc.Repo.GetCategories(ctx, onlyActive, &categories); err != nil {
+				uc.logger(ctx).Error("fail to get categories", zap.Error(err))
+				return nil, err
+			}
+			return categories, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &categories, nil
+}
+
+func (uc *Usecase) invalidateCategories(ctx context.Context) {
+	uc.invalidate(ctx, categoriesCacheKey(true), categoriesCacheKey(false))
+}
+
+// GetCategoryTree возвращает корневые категории с вложенными дочерними
+func (uc *Usecase) GetCategoryTree(ctx context.Context) (*[]*entities.AdvertismentCategory, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetCategoryTree")
+	defer span.End()
+	categories, err := uc.GetCategories(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	tree := buildCategoryTree(*categories)
+	return &tree, nil
+}
+
+func buildCategoryTree(categories []*entities.AdvertismentCategory) []*entities.AdvertismentCategory {
+	byID := make(map[uint64]*entities.AdvertismentCategory, len(categories))
+	for _, category := range categories {
+		category.Children = nil
+		byID[category.ID] = category
+	}
+	roots := []*entities.AdvertismentCategory{}
+	for _, category := range categories {
+		// Дочерние категории неактивного родителя в дерево не попадают
+		if category.ParentID == 0 {
+			roots = append(roots, category)
+		} else if parent, ok := byID[category.ParentID]; ok {
+			parent.Children = append(parent.Children, category)
+		}
+	}
+	return roots
+}
+
+// checkCategoryParent: родитель существует и не лежит в поддереве самой категории
+func (uc *Usecase) checkCategoryParent(ctx context.Context, category *entities.AdvertismentCategory) error {
+	if category.ParentID == 0 {
+		return nil
+	}
+	if category.ID != 0 {
+		cycle, err := uc.Repo.IsCategoryInSubtree(ctx, category.ID, category.ParentID)
+		if err != nil {
+			uc.logger(ctx).Error("fail to check category subtree", zap.Error(err))
+			return err
+		}
+		if cycle {
+			return ErrInvalidData
+		}
+	}
+	if exist, err := uc.Repo.IsCategoryExist(ctx, category.ParentID); err != nil || !exist {
+		uc.logger(ctx).Error("parent category does not exist", zap.Error(err))
+		return ErrInvalidData
+	}
+	return nil
+}
+
+func (uc *Usecase) CreateCategory(ctx context.Context, adminID uint64, category *entities.AdvertismentCategory) error {
+	ctx, span := tracer.Start(ctx, "Usecase.CreateCategory")
+	defer span.End()
+	if err := uc.Authorize(ctx, adminID, rbac.PermManageCategories); err != nil {
+		return err
+	}
+	var err error
+	if category.Name, err = validateCatalogName(category.Name); err != nil {
+		return err
+	}
+	if err = uc.checkCategoryParent(ctx, category); err != nil {
+		return err
+	}
+	if err = uc.Repo.CreateCategory(ctx, category); err != nil {
+		uc.logger(ctx).Error("fail to create category", zap.Error(err))
+		return mapRepoError(err)
+	}
+	uc.invalidateCategories(ctx)
+	return nil
+}
+
+func (uc *Usecase) UpdateCategory(ctx context.Context, adminID uint64, category *entities.AdvertismentCategory) error {
+	ctx, span := tracer.Start(ctx, "Usecase.UpdateCategory")
+	defer span.End()
+	if err := uc.Authorize(ctx, adminID, rbac.PermManageCategories); err != nil {
+		return err
+	}
+	var err error
+	if category.Name, err = validateCatalogName(category.Name); err != nil {
+		return err
+	}
+	if err = uc.checkCategoryParent(ctx, category); err != nil {
+		return err
+	}
+	if err = uc.Repo.UpdateCategory(ctx, category); err != nil {
+		uc.logger(ctx).Error("fail to update category", zap.Error(err))
+		return mapRepoError(err)
+	}
+	uc.invalidateCategories(ctx)
+	uc.invalidateAdvertisments(ctx, uc.advertismentIDsToInvalidate(ctx, uc.Repo.GetAdvertismentIDsByCategory, category.ID))
+	return nil
+}
+
+func (uc *Usecase) DeleteCategory(ctx context.Context, adminID, categoryID uint64) error {
+	ctx, span := tracer.Start(ctx, "Usecase.DeleteCategory")
+	defer span.End()
+	if err := uc.Authorize(ctx, adminID, rbac.PermManageCategories); err != nil {
+		return err
+	}
+	count, err := uc.Repo.CountAdvertismentsByCategory(ctx, categoryID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to count advertisments by category", zap.Error(err))
+		return err
+	}
+	if count > 0 {
+		uc.logger(ctx).Warn("category is still used", zap.Uint64("category_id", categoryID), zap.Uint64("ads", count))
+		return ErrConflict
+	}
+	// FK на categories_product защищает от объявления, созданного между проверкой и удалением
+	if err = uc.Repo.DeleteCategory(ctx, categoryID); err != nil {
+		uc.logger(ctx).Error("fail to delete category", zap.Error(err))
+		return mapRepoError(err)
+	}
+	uc.invalidateCategories(ctx)
+	return nil
+}
+
+func (uc *Usecase) GetCategoryAttributes(ctx context.Context, categoryID uint64) (*[]*entities.CategoryAttribute, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetCategoryAttributes")
+	defer span.End()
+	attributes := []*entities.CategoryAttribute{}
+	if err := uc.Repo.GetCategoryAttributes(ctx, categoryID, &attributes); err != nil {
+		uc.logger(ctx).Error("fail to get category attributes", zap.Error(err))
+		return nil, err
+	}
+	return &attributes, nil
+}
+
+func (uc *Usecase) CreateCategoryAttribute(ctx context.Context, adminID uint64, attr *entities.CategoryAttribute) error {
+	ctx, span := tracer.Start(ctx, "Usecase.CreateCategoryAttribute")
+	defer span.End()
+	if err := uc.Authorize(ctx, adminID, rbac.PermManageCategories); err != nil {
+		return err
+	}
+	if err := validateCategoryAttribute(attr); err != nil {
+		return err
+	}
+	used, err := uc.Repo.IsAttributeCodeUsedInBranch(ctx, attr.CategoryID, attr.Code)
+	if err != nil {
+		uc.logger(ctx).Error("fail to check attribute code", zap.Error(err))
+		return err
+	}
+	if used {
+		return ErrConflict
+	}
+	if err = uc.Repo.CreateCategoryAttribute(ctx, attr); err != nil {
+		uc.logger(ctx).Error("fail to create category attribute", zap.Error(err))
+		if errors.Is(err, postgres.ErrReferenced) {
+			return ErrNotFound
+		}
+		return mapRepoError(err)
+	}
+	return nil
+}
+
+func (uc *Usecase) DeleteCategoryAttribute(ctx context.Context, adminID, attributeID uint64) error {
+	ctx, span := tracer.Start(ctx, "Usecase.DeleteCategoryAttribute")
+	defer span.End()
+	if err := uc.Authorize(ctx, adminID, rbac.PermManageCategories); err != nil {
+		return err
+	}
+	// значения удаляются вместе с атрибутом, объявления ищутся до удаления
+	adIDs := uc.advertismentIDsToInvalidate(ctx, uc.Repo.GetAdvertismentIDsByAttribute, attributeID)
+	if err := uc.Repo.DeleteCategoryAttribute(ctx, attributeID); err != nil {
+		uc.logger(ctx).Error("fail to delete category attribute", zap.Error(err))
+		return mapRepoError(err)
+	}
+	uc.invalidateAdvertisments(ctx, adIDs)
+	return nil
+}
+
+func validatePromotionType(tp *entities.TypePromotion) error {
+	var err error
+	if tp.Name, err = validateCatalogName(tp.Name); err != nil {
+		return err
+	}
+	if tp.Price < 0 || tp.TimeLive <= 0 {
+		return ErrInvalidData
+	}
+	return nil
+}
+
+func (uc *Usecase) GetPromotionTypes(ctx context.Context, onlyActive bool) (*[]*entities.TypePromotion, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetPromotionTypes")
+	defer span.End()
+	types := []*entities.TypePromotion{}
+	if err := uc.Repo.GetPromotionTypes(ctx, onlyActive, &types); err != nil {
+		uc.logger(ctx).Error("fail to get promotion types", zap.Error(err))
+		return nil, err
+	}
+	return &types, nil
+}
+
+func (uc *Usecase) CreatePromotionType(ctx context.Context, adminID uint64, tp *entities.TypePromotion) error {
+	ctx, span := tracer.Start(ctx, "Usecase.CreatePromotionType")
+	defer span.End()
+	if err := uc.Authorize(ctx, adminID, rbac.PermManagePromotionTypes); err != nil {
+		return err
+	}
+	if err := validatePromotionType(tp); err != nil {
+		return err
+	}
+	if err := uc.Repo.CreatePromotionType(ctx, tp); err != nil {
+		uc.logger(ctx).Error("fail to create promotion type", zap.Error(err))
+		return mapRepoError(err)
+	}
+	return nil
+}
+
+func (uc *Usecase) UpdatePromotionType(ctx context.Context, adminID uint64, tp *entities.TypePromotion) error {
+	ctx, span := tracer.Start(ctx, "Usecase.UpdatePromotionType")
+	defer span.End()
+	if err := uc.Authorize(ctx, adminID, rbac.PermManagePromotionTypes); err != nil {
+		return err
+	}
+	if err := validatePromotionType(tp); err != nil {
+		return err
+	}
+	if err := uc.Repo.UpdatePromotionType(ctx, tp); err != nil {
+		uc.logger(ctx).Error("fail to update promotion type", zap.Error(err))
+		return mapRepoError(err)
+	}
+	uc.invalidateAdvertisments(ctx, uc.advertismentIDsToInvalidate(ctx, uc.Repo.GetAdvertismentIDsByPromotionType, tp.ID))
+	return nil
+}
+
+func (uc *Usecase) DeletePromotionType(ctx context.Context, adminID, typeID uint64) error {
+	ctx, span := tracer.Start(ctx, "Usecase.DeletePromotionType")
+	defer span.End()
+	if err := uc.Authorize(ctx, adminID, rbac.PermManagePromotionTypes); err != nil {
+		return err
+	}
+	count, err := uc.Repo.CountAdvertismentsByPromotionType(ctx, typeID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to count advertisments by promotion type", zap.Error(err))
+		return err
+	}
+	if count > 0 {
+		uc.logger(ctx).Warn("promotion type is still used", zap.Uint64("type_id", typeID), zap.Uint64("ads", count))
+		return ErrConflict
+	}
+	if err = uc.Repo.DeletePromotionType(ctx, typeID); err != nil {
+		uc.logger(ctx).Error("fail to delete promotion type", zap.Error(err))
+		return mapRepoError(err)
+	}
+	return nil
+}
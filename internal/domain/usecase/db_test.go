@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"backend/config"
+	"backend/internal/domain/repository/postgres"
+	"backend/internal/sms"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// envTestPostgresDSN - база после psql -f migration/init.sql. Без нее тесты
+// с базой пропускаются.
+const envTestPostgresDSN = "TEST_POSTGRES_DSN"
+
+// testUserID - пользователь тестов с базой, удаляется после каждого теста
+const testUserID = 2000000001
+
+// newDBUsecase - Usecase поверх тестовой базы с пользователем testUserID
+func newDBUsecase(t *testing.T, sender sms.Sender, cfg *config.ConfigModel) *Usecase {
+	t.Helper()
+	dsn := os.Getenv(envTestPostgresDSN)
+	if dsn == "" {
+		t.Skip(envTestPostgresDSN + " is not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	cleanup := func() {
+		for _, q := range []string{
+			`DELETE FROM phone_codes WHERE user_id = $1`,
+			`DELETE FROM advertisements WHERE user_id = $1`,
+			`DELETE FROM users WHERE id = $1`,
+		} {
+			if _, err := pool.Exec(ctx, q, testUserID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+	if _, err = pool.Exec(ctx, `
+INSERT INTO users (id, username, firstname, role_id)
+SELECT $1, 'usecase_test', 'Test', id FROM user_roles WHERE name = 'user'`, testUserID); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := postgres.NewRepository(zap.NewNop(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.DB = pool
+	uc, err := NewUsecase(zap.NewNop(), cfg, repo, nil, sender, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uc
+}
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"backend/config"
+	"backend/internal/health"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Digest - фоновый воркер суточных дайджестов сохраненных поисков
+type Digest struct {
+	log    *zap.Logger
+	uc     *Usecase
+	cfg    config.SavedSearchConfig
+	health *health.Worker
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewDigest(log *zap.Logger, cfg *config.ConfigModel, uc *Usecase) *Digest {
+	return &Digest{
+		log: log.Named("digest"),
+		uc:  uc,
+		cfg: cfg.SavedSearch,
+		// воркер считается зависшим после трех пропущенных итераций
+		health: health.NewWorker("digest", 3*cfg.SavedSearch.DigestInterval),
+	}
+}
+
+// Checker - проверка готовности для /readyz
+func (d *Digest) Checker() health.Checker {
+	return d.health
+}
+
+func (d *Digest) OnStart(_ context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+	go d.run(ctx)
+	return nil
+}
+
+func (d *Digest) OnStop(ctx context.Context) error {
+	d.cancel()
+	d.health.Stop()
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Digest) run(ctx context.Context) {
+	defer close(d.done)
+	ticker := time.NewTicker(d.cfg.DigestInterval)
+	defer ticker.Stop()
+	for {
+		n, err := d.uc.SendSavedSearchDigests(ctx, d.cfg.DigestBatchSize)
+		if err != nil && ctx.Err() == nil {
+			d.log.Error("fail to send saved search digests", zap.Error(err))
+		}
+		if n > 0 {
+			d.log.Info("saved search digests sent", zap.Int("count", n))
+		}
+		d.health.Beat(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"backend/internal/domain/entities"
+	"backend/internal/rbac"
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxCityNameLen   = 100
+	defaultCityLimit = 20
+	maxCityLimit     = 100
+)
+
+func validCoordinates(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+// validateGeoFilter: радиус и сортировка по расстоянию требуют точку поиска
+func validateGeoFilter(filter *entities.AdvertismentFilter) error {
+	hasPoint := filter.Lat != nil && filter.Lon != nil
+	if (filter.Lat == nil) != (filter.Lon == nil) {
+		return ErrInvalidData
+	}
+	if hasPoint && !validCoordinates(*filter.Lat, *filter.Lon) {
+		return ErrInvalidData
+	}
+	if filter.RadiusKm != nil && (!hasPoint || *filter.RadiusKm <= 0 || *filter.RadiusKm > maxSearchRadiusKm) {
+		return ErrInvalidData
+	}
+	if filter.Sort == entities.SortDistance && !hasPoint {
+		return ErrInvalidData
+	}
+	return nil
+}
+
+// GetCities: подсказка городов по началу названия
+func (uc *Usecase) GetCities(ctx context.Context, prefix string, limit uint64) (*[]*entities.City, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetCities")
+	defer span.End()
+	if limit == 0 {
+		limit = defaultCityLimit
+	}
+	if limit > maxCityLimit {
+		limit = maxCityLimit
+	}
+	cities := []*entities.City{}
+	if err := uc.Repo.GetCities(ctx, strings.TrimSpace(prefix), limit, &cities); err != nil {
+		uc.logger(ctx).Error("fail to get cities", zap.Error(err))
+		return nil, err
+	}
+	return &cities, nil
+}
+
+func (uc *Usecase) CreateCity(ctx context.Context, adminID uint64, city *entities.City) error {
+	ctx, span := tracer.Start(ctx, "Usecase.CreateCity")
+	defer span.End()
+	if err := uc.Authorize(ctx, adminID, rbac.PermManageCities); err != nil {
+		return err
+	}
+	city.Name = strings.TrimSpace(city.Name)
+	city.Region = strings.TrimSpace(city.Region)
+	if city.Name == "" || utf8.RuneCountInString(city.Name) > maxCityNameLen ||
+		utf8.RuneCountInString(city.Region) > maxCityNameLen ||
+		!validCoordinates(city.Latitude, city.Longitude) {
+		return ErrInvalidData
+	}
+	if err := uc.Repo.CreateCity(ctx, city); err != nil {
+		uc.logger(ctx).Error("fail to create city", zap.Error(err))
+		return mapRepoError(err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"backend/internal/domain/entities"
+	"backend/internal/rbac"
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxRejectReasonLen = 255
+
+	defaultQueueLimit = 20
+	maxQueueLimit     = 100
+)
+
+func (uc *Usecase) HideAdvertisment(ctx context.Context, moderatorID, adID uint64, hidden bool) error {
+	ctx, span := tracer.Start(ctx, "Usecase.HideAdvertisment")
+	defer span.End()
+	if err := uc.Authorize(ctx, moderatorID, rbac.PermHideAdvertisment); err != nil {
+		return err
+	}
+	exist, err := uc.Repo.IsAdExist(ctx, &entities.Advertisment{ID: adID})
+	if err != nil {
+		uc.logger(ctx).Error("fail to check advertisment", zap.Error(err))
+		return err
+	}
+	if !exist {
+		return ErrNotFound
+	}
+	if err := uc.Repo.HideAdvertisment(ctx, adID, hidden); err != nil {
+		uc.logger(ctx).Error("fail to hide advertisment", zap.Error(err))
+		return err
+	}
+	uc.invalidate(ctx, adCacheKey(adID))
+	uc.logger(ctx).Info("advertisment visibility changed by moderator",
+		zap.Uint64("moderator_id", moderatorID),
+		zap.Uint64("ad_id", adID),
+		zap.Bool("hidden", hidden),
+	)
+	return nil
+}
+
+func (uc *Usecase) HideReview(ctx context.Context, moderatorID, reviewID uint64, hidden bool) error {
+	ctx, span := tracer.Start(ctx, "Usecase.HideReview")
+	defer span.End()
+	if err := uc.Authorize(ctx, moderatorID, rbac.PermHideReview); err != nil {
+		return err
+	}
+	adID, sellerID, err := uc.Repo.GetReviewTarget(ctx, reviewID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to get review", zap.Error(err))
+		return mapRepoError(err)
+	}
+	if err := uc.Repo.HideReview(ctx, reviewID, hidden); err != nil {
+		uc.logger(ctx).Error("fail to hide review", zap.Error(err))
+		return err
+	}
+	uc.invalidate(ctx, adCacheKey(adID), profileCacheKey(sellerID))
+	uc.logger(ctx).Info("review visibility changed by moderator",
+		zap.Uint64("moderator_id", moderatorID),
+		zap.Uint64("review_id", reviewID),
+		zap.Bool("hidden", hidden),
+	)
+	return nil
+}
+
+// canViewAdvertisment: одобренные объявления видны всем, остальные - владельцу
+// и модераторам. Черновики и отложенные видит только владелец, скрытые - владелец
+// и модераторы с правом скрытия.
+func (uc *Usecase) canViewAdvertisment(ctx context.Context, viewerID uint64, advertisment *entities.Advertisment) bool {
+	switch advertisment.Status {
+	case entities.AdStatusDraft, entities.AdStatusScheduled:
+		return viewerID != 0 && viewerID == advertisment.User.ID
+	}
+	if advertisment.Moderation.Hidden {
+		if viewerID == 0 {
+			return false
+		}
+		return viewerID == advertisment.User.ID || uc.Authorize(ctx, viewerID, rbac.PermHideAdvertisment) == nil
+	}
+	if advertisment.Moderation.Status == entities.ModerationApproved {
+		return true
+	}
+	if viewerID == 0 {
+		return false
+	}
+	if viewerID == advertisment.User.ID {
+		return true
+	}
+	return uc.Authorize(ctx, viewerID, rbac.PermModerateAdvertisment) == nil
+}
+
+// precheckAdvertisment выполняет автоматические проверки перед постановкой
+// в очередь модерации. Флаги не блокируют публикацию, а поднимают
+// объявление в начало очереди.
+func (uc *Usecase) precheckAdvertisment(ctx context.Context, advertisment *entities.Advertisment) ([]string, error) {
+	flags := []string{}
+	if containsBannedWord(uc.cfg.Moderation.BannedWords, advertisment.Name, advertisment.Description) {
+		flags = append(flags, entities.FlagBannedWords)
+	}
+
+	median, samples, err := uc.Repo.GetCategoryPriceMedian(ctx, advertisment.AdvertismentCategory.ID, advertisment.ID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to get category price median", zap.Error(err))
+		return nil, err
+	}
+	if samples >= uint64(uc.cfg.Moderation.PriceMinSamples) && median > 0 {
+		ratio := advertisment.Price / median
+		if ratio < uc.cfg.Moderation.PriceLowRatio || ratio > uc.cfg.Moderation.PriceHighRatio {
+			flags = append(flags, entities.FlagSuspiciousPrice)
+		}
+	}
+
+	if len(advertisment.Photos) > 0 {
+		paths := make([]string, 0, len(advertisment.Photos))
+		for _, photo := range advertisment.Photos {
+			paths = append(paths, photo.Path)
+		}
+		duplicate, err := uc.Repo.HasDuplicatePhotos(ctx, advertisment.ID, paths)
+		if err != nil {
+			uc.logger(ctx).Error("fail to check duplicate photos", zap.Error(err))
+			return nil, err
+		}
+		if duplicate {
+			flags = append(flags, entities.FlagDuplicatePhoto)
+		}
+	}
+	return flags, nil
+}
+
+func containsBannedWord(banned []string, texts ...string) bool {
+	if len(banned) == 0 {
+		return false
+	}
+	words := map[string]struct{}{}
+	for _, text := range texts {
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			words[word] = struct{}{}
+		}
+	}
+	for _, word := range banned {
+		if _, ok := words[strings.ToLower(strings.TrimSpace(word))]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (uc *Usecase) GetModerationQueue(ctx context.Context, limit, offset uint64) (*[]*entities.ModerationQueueItem, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetModerationQueue")
+	defer span.End()
+	if limit == 0 {
+		limit = defaultQueueLimit
+	}
+	if limit > maxQueueLimit {
+		limit = maxQueueLimit
+	}
+	items := []*entities.ModerationQueueItem{}
+	if err := uc.Repo.GetModerationQueue(ctx, limit, offset, &items); err != nil {
+		uc.logger(ctx).Error("fail to get moderation queue", zap.Error(err))
+		return nil, err
+	}
+	for _, item := range items {
+		advertisment := &entities.Advertisment{ID: item.AdID}
+		if err := uc.Repo.GetAdvertismentPhotos(ctx, advertisment); err != nil {
+			uc.logger(ctx).Error("fail to get Photos by Advertisment ID", zap.Error(err))
+			return nil, err
+		}
+		item.Photos = advertisment.Photos
+	}
+	return &items, nil
+}
+
+func (uc *Usecase) ApproveAdvertisment(ctx context.Context, moderatorID, adID uint64) error {
+	ctx, span := tracer.Start(ctx, "Usecase.ApproveAdvertisment")
+	defer span.End()
+	if err := uc.Authorize(ctx, moderatorID, rbac.PermModerateAdvertisment); err != nil {
+		return err
+	}
+	ownerID, _, err := uc.Repo.GetAdvertismentStatus(ctx, adID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to get advertisment status", zap.Error(err))
+		return mapRepoError(err)
+	}
+	event := newAdvertismentEvent(entities.EventAdApproved, entities.AdvertismentEvent{
+		AdID:   adID,
+		UserID: ownerID,
+	})
+	if err = uc.Repo.SetAdvertismentModeration(ctx, adID, moderatorID, entities.ModerationApproved, "", event); err != nil {
+		uc.logger(ctx).Error("fail to approve advertisment", zap.Error(err))
+		return mapRepoError(err)
+	}
+	uc.invalidate(ctx, adCacheKey(adID))
+	uc.logger(ctx).Info("advertisment approved by moderator",
+		zap.Uint64("moderator_id", moderatorID),
+		zap.Uint64("ad_id", adID),
+	)
+	return nil
+}
+
+// RejectAdvertisment: причина обязательна, ее увидит владелец в своих объявлениях
+func (uc *Usecase) RejectAdvertisment(ctx context.Context, moderatorID, adID uint64, reason string) error {
+	ctx, span := tracer.Start(ctx, "Usecase.RejectAdvertisment")
+	defer span.End()
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > maxRejectReasonLen {
+		return ErrInvalidData
+	}
+	if err := uc.Authorize(ctx, moderatorID, rbac.PermModerateAdvertisment); err != nil {
+		return err
+	}
+	ownerID, _, err := uc.Repo.GetAdvertismentStatus(ctx, adID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to get advertisment status", zap.Error(err))
+		return mapRepoError(err)
+	}
+	event := newAdvertismentEvent(entities.EventAdRejected, entities.AdvertismentEvent{
+		AdID:   adID,
+		UserID: ownerID,
+		Reason: reason,
+	})
+	if err = uc.Repo.SetAdvertismentModeration(ctx, adID, moderatorID, entities.ModerationRejected, reason, event); err != nil {
+		uc.logger(ctx).Error("fail to reject advertisment", zap.Error(err))
+		return mapRepoError(err)
+	}
+	uc.invalidate(ctx, adCacheKey(adID))
+	uc.logger(ctx).Info("advertisment rejected by moderator",
+		zap.Uint64("moderator_id", moderatorID),
+		zap.Uint64("ad_id", adID),
+		zap.String("reason", reason),
+	)
+	return nil
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import "testing"
+
+func TestContainsBannedWord(t *testing.T) {
+	banned := []string{"Казино", " casino ", "xxx"}
+	tests := []struct {
+		name   string
+		banned []string
+		texts  []string
+		want   bool
+	}{
+		{"no banned list", nil, []string{"казино"}, false},
+		{"clean text", banned, []string{"Продам велосипед", "почти новый"}, false},
+		{"exact word", banned, []string{"лучшее казино города"}, true},
+		{"case insensitive", banned, []string{"КАЗИНО"}, true},
+		{"banned word is trimmed", banned, []string{"online casino"}, true},
+		{"second text", banned, []string{"Продам велосипед", "пишите, xxx"}, true},
+		{"punctuation separates words", banned, []string{"(казино)!"}, true},
+		{"part of a word", banned, []string{"казинорама", "casinos"}, false},
+		{"digits are part of a word", banned, []string{"xxx1"}, false},
+		{"no texts", banned, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsBannedWord(tt.banned, tt.texts...); got != tt.want {
+				t.Errorf("containsBannedWord(%q, %q) = %v, want %v", tt.banned, tt.texts, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"backend/internal/health"
+
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -10,6 +12,63 @@ func New() fx.Option {
 		"usecase",
 		fx.Provide(
 			NewUsecase,
+			NewPublisher,
+			NewDigest,
+			NewNotifier,
+			NewWebhookDispatcher,
+			NewRelay,
+			fx.Annotate(
+				func(p *Publisher) health.Checker { return p.Checker() },
+				fx.ResultTags(health.Group),
+			),
+			fx.Annotate(
+				func(d *Digest) health.Checker { return d.Checker() },
+				fx.ResultTags(health.Group),
+			),
+			fx.Annotate(
+				func(n *Notifier) health.Checker { return n.Checker() },
+				fx.ResultTags(health.Group),
+			),
+			fx.Annotate(
+				func(w *WebhookDispatcher) health.Checker { return w.Checker() },
+				fx.ResultTags(health.Group),
+			),
+			fx.Annotate(
+				func(r *Relay) health.Checker { return r.Checker() },
+				fx.ResultTags(health.Group),
+			),
+		),
+		fx.Invoke(
+			func(lc fx.Lifecycle, p *Publisher) {
+				lc.Append(fx.Hook{
+					OnStart: p.OnStart,
+					OnStop:  p.OnStop,
+				})
+			},
+			func(lc fx.Lifecycle, d *Digest) {
+				lc.Append(fx.Hook{
+					OnStart: d.OnStart,
+					OnStop:  d.OnStop,
+				})
+			},
+			func(lc fx.Lifecycle, n *Notifier) {
+				lc.Append(fx.Hook{
+					OnStart: n.OnStart,
+					OnStop:  n.OnStop,
+				})
+			},
+			func(lc fx.Lifecycle, w *WebhookDispatcher) {
+				lc.Append(fx.Hook{
+					OnStart: w.OnStart,
+					OnStop:  w.OnStop,
+				})
+			},
+			func(lc fx.Lifecycle, r *Relay) {
+				lc.Append(fx.Hook{
+					OnStart: r.OnStart,
+					OnStop:  r.OnStop,
+				})
+			},
 		),
 		fx.Decorate(func(log *zap.Logger) *zap.Logger {
 			return log.Named("usecase")
@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"backend/internal/domain/entities"
+	"backend/internal/notify"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// notify сохраняет уведомление во входящие пользователя и ставит его в
+// очередь на отправку в каналы, не выключенные в настройках. Повтор с тем
+// же DedupKey пропускается. Ошибку вызывающий откатывает вместе с тем, что
+// отметил уведомленным: иначе уведомление потеряется.
+func (uc *Usecase) notify(ctx context.Context, notification *entities.Notification) error {
+	created, err := uc.Repo.CreateNotification(ctx, notification, uc.notifier.Channels())
+	if err != nil {
+		uc.logger(ctx).Error("fail to create notification",
+			zap.Uint64("user_id", notification.UserID),
+			zap.String("kind", notification.Kind),
+			zap.Error(err),
+		)
+		return err
+	}
+	if !created {
+		uc.logger(ctx).Debug("duplicate notification skipped",
+			zap.Uint64("user_id", notification.UserID),
+			zap.String("dedup_key", notification.DedupKey),
+		)
+	}
+	return nil
+}
+
+func (uc *Usecase) GetNotifications(ctx context.Context, uID uint64, unreadOnly bool, limit, offset uint64) (*[]*entities.Notification, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetNotifications")
+	defer span.End()
+	if limit == 0 {
+		limit = defaultQueueLimit
+	}
+	if limit > maxQueueLimit {
+		limit = maxQueueLimit
+	}
+	notifications := []*entities.Notification{}
+	if err := uc.Repo.GetNotifications(ctx, uID, unreadOnly, limit, offset, &notifications); err != nil {
+		uc.logger(ctx).Error("fail to get notifications", zap.Error(err))
+		return nil, err
+	}
+	return &notifications, nil
+}
+
+func (uc *Usecase) CountUnreadNotifications(ctx context.Context, uID uint64) (uint64, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.CountUnreadNotifications")
+	defer span.End()
+	count, err := uc.Repo.CountUnreadNotifications(ctx, uID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to count unread notifications", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkNotificationsRead: пустой ids - прочитать все
+func (uc *Usecase) MarkNotificationsRead(ctx context.Context, uID uint64, ids []uint64) error {
+	ctx, span := tracer.Start(ctx, "Usecase.MarkNotificationsRead")
+	defer span.End()
+	if len(ids) == 0 {
+		ids = nil
+	}
+	if _, err := uc.Repo.MarkNotificationsRead(ctx, uID, ids); err != nil {
+		uc.logger(ctx).Error("fail to mark notifications read", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetNotificationPreferences возвращает настройки по всем типам событий и
+// каналам; не заданные явно включены
+func (uc *Usecase) GetNotificationPreferences(ctx context.Context, uID uint64) (*[]*entities.NotificationPreference, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetNotificationPreferences")
+	defer span.End()
+	saved := []*entities.NotificationPreference{}
+	if err := uc.Repo.GetNotificationPreferences(ctx, uID, &saved); err != nil {
+		uc.logger(ctx).Error("fail to get notification preferences", zap.Error(err))
+		return nil, err
+	}
+	disabled := map[[2]string]bool{}
+	for _, pref := range saved {
+		disabled[[2]string{pref.Kind, pref.Channel}] = !pref.Enabled
+	}
+	prefs := []*entities.NotificationPreference{}
+	for _, kind := range entities.NotificationKinds {
+		for _, channel := range uc.notifier.Channels() {
+			prefs = append(prefs, &entities.NotificationPreference{
+				Kind:    kind,
+				Channel: channel,
+				Enabled: !disabled[[2]string{kind, channel}],
+			})
+		}
+	}
+	return &prefs, nil
+}
+
+func (uc *Usecase) SetNotificationPreference(ctx context.Context, uID uint64, pref *entities.NotificationPreference) error {
+	ctx, span := tracer.Start(ctx, "Usecase.SetNotificationPreference")
+	defer span.End()
+	if !slices.Contains(entities.NotificationKinds, pref.Kind) || !slices.Contains(uc.notifier.Channels(), pref.Channel) {
+		return ErrInvalidData
+	}
+	if err := uc.Repo.SetNotificationPreference(ctx, uID, pref); err != nil {
+		uc.logger(ctx).Error("fail to set notification preference", zap.Error(err))
+		return mapRepoError(err)
+	}
+	return nil
+}
+
+// DeliverNotifications отправляет очередную пачку во внешние каналы.
+// Временная ошибка оставляет отправку в очереди до MaxAttempts попыток,
+// notify.ErrUndeliverable проваливает ее сразу. Возвращает число отправленных.
+func (uc *Usecase) DeliverNotifications(ctx context.Context, batchSize int) (int, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.DeliverNotifications")
+	defer span.End()
+	cfg := uc.cfg.Notification
+	deliveries := []*entities.NotificationDelivery{}
+	if err := uc.Repo.TakeNotificationDeliveries(ctx, batchSize, cfg.RetryDelay, &deliveries); err != nil {
+		uc.logger(ctx).Error("fail to take notification deliveries", zap.Error(err))
+		return 0, err
+	}
+	sent := 0
+	for _, d := range deliveries {
+		msg := notify.Message{Title: d.Notification.Title, Body: d.Notification.Body}
+		err := uc.notifier.Send(ctx, d.Channel, d.Notification.UserID, msg)
+		status, lastError, result := entities.DeliveryStatusSent, "", "sent"
+		switch {
+		case err == nil:
+			sent++
+		case errors.Is(err, notify.ErrUndeliverable) || d.Attempts >= cfg.MaxAttempts:
+			status, lastError, result = entities.DeliveryStatusFailed, err.Error(), "failed"
+		default:
+			// повтор по next_attempt_at, выставленному при взятии
+			status, lastError, result = entities.DeliveryStatusPending, err.Error(), "retry"
+		}
+		if err != nil {
+			uc.logger(ctx).Warn("fail to deliver notification",
+				zap.Uint64("delivery_id", d.ID),
+				zap.String("channel", d.Channel),
+				zap.Int("attempt", d.Attempts),
+				zap.Error(err),
+			)
+		}
+		uc.metrics.NotificationsSent.WithLabelValues(d.Channel, result).Inc()
+		if err = uc.Repo.SetNotificationDeliveryStatus(ctx, d.ID, status, lastError); err != nil {
+			uc.logger(ctx).Error("fail to save notification delivery status", zap.Uint64("delivery_id", d.ID), zap.Error(err))
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// NotifyExpiringPromotions предупреждает владельцев, у которых продвижение
+// закончится в ближайшие PromotionExpiryNotice. Пачка отмечается и
+// уведомляется в одной транзакции: при ошибке отметки откатываются и пачка
+// берется снова в следующий запуск. Возвращает число уведомлений.
+func (uc *Usecase) NotifyExpiringPromotions(ctx context.Context, batchSize int) (int, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.NotifyExpiringPromotions")
+	defer span.End()
+	total := 0
+	for {
+		ads := []*entities.Advertisment{}
+		err := uc.Repo.WithTx(ctx, func(ctx context.Context) error {
+			// повтор транзакции после конфликта берет пачку заново
+			ads = ads[:0]
+			if err := uc.Repo.TakeExpiringPromotions(ctx, uc.cfg.Notification.PromotionExpiryNotice, batchSize, &ads); err != nil {
+				uc.logger(ctx).Error("fail to take expiring promotions", zap.Error(err))
+				return err
+			}
+			for _, ad := range ads {
+				if err := uc.notify(ctx, &entities.Notification{
+					UserID:   ad.User.ID,
+					Kind:     entities.NotificationPromotionExpiry,
+					Title:    "Продвижение скоро закончится",
+					Body:     fmt.Sprintf("Продвижение объявления «%s» закончится %s", ad.Name, ad.DateExpirePromotion.Format("02.01.2006 15:04")),
+					Payload:  map[string]interface{}{"ad_id": ad.ID},
+					DedupKey: fmt.Sprintf("promotion_expiry:%d:%s", ad.ID, ad.DateExpirePromotion.Format(time.RFC3339)),
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+		total += len(ads)
+		if len(ads) < batchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"backend/config"
+	"backend/internal/health"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Notifier - фоновый воркер: предупреждает об окончании продвижения и
+// отправляет уведомления во внешние каналы
+type Notifier struct {
+	log    *zap.Logger
+	uc     *Usecase
+	cfg    config.NotificationConfig
+	health *health.Worker
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewNotifier(log *zap.Logger, cfg *config.ConfigModel, uc *Usecase) *Notifier {
+	return &Notifier{
+		log: log.Named("notifier"),
+		uc:  uc,
+		cfg: cfg.Notification,
+		// воркер считается зависшим после трех пропущенных итераций
+		health: health.NewWorker("notifier", 3*cfg.Notification.DeliveryInterval),
+	}
+}
+
+// Checker - проверка готовности для /readyz
+func (n *Notifier) Checker() health.Checker {
+	return n.health
+}
+
+func (n *Notifier) OnStart(_ context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	n.cancel = cancel
+	n.done = make(chan struct{})
+	go n.run(ctx)
+	return nil
+}
+
+func (n *Notifier) OnStop(ctx context.Context) error {
+	n.cancel()
+	n.health.Stop()
+	select {
+	case <-n.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (n *Notifier) run(ctx context.Context) {
+	defer close(n.done)
+	ticker := time.NewTicker(n.cfg.DeliveryInterval)
+	defer ticker.Stop()
+	for {
+		_, err := n.uc.NotifyExpiringPromotions(ctx, n.cfg.DeliveryBatchSize)
+		if err != nil && ctx.Err() == nil {
+			n.log.Error("fail to notify about expiring promotions", zap.Error(err))
+		}
+		if err == nil {
+			var sent int
+			sent, err = n.uc.DeliverNotifications(ctx, n.cfg.DeliveryBatchSize)
+			if err != nil && ctx.Err() == nil {
+				n.log.Error("fail to deliver notifications", zap.Error(err))
+			}
+			if sent > 0 {
+				n.log.Info("notifications delivered", zap.Int("count", sent))
+			}
+		}
+		n.health.Beat(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
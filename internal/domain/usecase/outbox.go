@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+)
+
+// OutboxHandler обрабатывает событие outbox. Событие доставляется хотя бы
+// один раз, поэтому обработчик должен быть идемпотентным; ошибка возвращает
+// событие в очередь на повтор. ctx несет транзакцию outbox: изменения
+// обработчика в базе фиксируются вместе с отметкой об обработке.
+type OutboxHandler func(ctx context.Context, event *entities.OutboxEvent) error
+
+// newAdvertismentEvent - событие объявления для записи в outbox
+func newAdvertismentEvent(eventType string, data entities.AdvertismentEvent) entities.OutboxEvent {
+	// структура из простых полей, ошибки маршалинга быть не может
+	payload, _ := json.Marshal(data)
+	return entities.OutboxEvent{Type: eventType, Payload: payload}
+}
+
+// ProcessOutbox разбирает outbox пачками по batchSize, пока очередь не
+// опустеет. Возвращает число обработанных событий.
+func (uc *Usecase) ProcessOutbox(ctx context.Context, batchSize int, handle OutboxHandler) (int, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.ProcessOutbox")
+	defer span.End()
+	cfg := uc.cfg.Outbox
+	total := 0
+	for {
+		n, err := uc.Repo.ProcessOutbox(ctx, batchSize, cfg.MaxAttempts, cfg.RetryDelay, handle)
+		if err != nil {
+			uc.logger(ctx).Error("fail to process outbox", zap.Error(err))
+			return total, err
+		}
+		total += n
+		if n < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// matchPublishedAdvertisment - обработчик outbox: объявление стало видимым,
+// проверяем сохраненные поиски
+func (uc *Usecase) matchPublishedAdvertisment(ctx context.Context, event *entities.OutboxEvent) error {
+	data := entities.AdvertismentEvent{}
+	if err := json.Unmarshal(event.Payload, &data); err != nil {
+		uc.logger(ctx).Error("fail to unmarshal outbox event", zap.Uint64("event_id", event.ID), zap.Error(err))
+		return err
+	}
+	return uc.matchSavedSearches(ctx, data.AdID)
+}
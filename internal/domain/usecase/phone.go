@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"backend/internal/domain/entities"
+	"backend/internal/phone"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"go.uber.org/zap"
+)
+
+// ErrTooManyRequests - код запрашивают слишком часто или попытки ввода кончились
+var ErrTooManyRequests = errors.New("too many requests")
+
+// newPhoneCodeKey: ключ из конфига или случайный на время жизни процесса
+func newPhoneCodeKey(secret string) ([]byte, error) {
+	if secret != "" {
+		return []byte(secret), nil
+	}
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func generatePhoneCode(length int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(length)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", length, n), nil
+}
+
+// hashPhoneCode привязывает код к номеру, чтобы его нельзя было применить к другому
+func (uc *Usecase) hashPhoneCode(phone, code string) []byte {
+	mac := hmac.New(sha256.New, uc.phoneCodeKey)
+	mac.Write([]byte(phone + ":" + code))
+	return mac.Sum(nil)
+}
+
+// RequestPhoneCode нормализует номер и отправляет на него код подтверждения
+func (uc *Usecase) RequestPhoneCode(ctx context.Context, uID uint64, rawPhone string) error {
+	ctx, span := tracer.Start(ctx, "Usecase.RequestPhoneCode")
+	defer span.End()
+	number, err := phone.Normalize(rawPhone, uc.cfg.Phone.DefaultCountryCode)
+	if err != nil {
+		return ErrInvalidData
+	}
+	if exist, err := uc.Repo.IsUserExist(ctx, &entities.User{ID: uID}); err != nil || !exist {
+		uc.logger(ctx).Error("user does not exist", zap.Error(err))
+		return ErrForbidden
+	}
+	used, err := uc.Repo.IsPhoneUsed(ctx, number, uID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to check phone", zap.Error(err))
+		return err
+	}
+	if used {
+		return ErrConflict
+	}
+	if uc.cfg.Phone.ResendInterval > 0 {
+		recent, err := uc.Repo.HasRecentPhoneCode(ctx, uID, uc.cfg.Phone.ResendInterval)
+		if err != nil {
+			uc.logger(ctx).Error("fail to check recent phone code", zap.Error(err))
+			return err
+		}
+		if recent {
+			return ErrTooManyRequests
+		}
+	}
+
+	code, err := generatePhoneCode(uc.cfg.Phone.CodeLength)
+	if err != nil {
+		uc.logger(ctx).Error("fail to generate phone code", zap.Error(err))
+		return err
+	}
+	pc := &entities.PhoneCode{
+		UserID:   uID,
+		Phone:    number,
+		CodeHash: uc.hashPhoneCode(number, code),
+	}
+	if err = uc.Repo.CreatePhoneCode(ctx, pc, uc.cfg.Phone.CodeTTL); err != nil {
+		uc.logger(ctx).Error("fail to save phone code", zap.Error(err))
+		return err
+	}
+	if err = uc.sms.Send(ctx, number, fmt.Sprintf("Код подтверждения: %s", code)); err != nil {
+		uc.logger(ctx).Error("fail to send phone code", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ConfirmPhone проверяет последний выданный код и сохраняет номер в профиль
+func (uc *Usecase) ConfirmPhone(ctx context.Context, uID uint64, code string) error {
+	ctx, span := tracer.Start(ctx, "Usecase.ConfirmPhone")
+	defer span.End()
+	if code == "" {
+		return ErrInvalidData
+	}
+	pc := &entities.PhoneCode{UserID: uID}
+	if err := uc.Repo.GetActivePhoneCode(ctx, pc); err != nil {
+		return mapRepoError(err)
+	}
+	if pc.Expired {
+		return ErrInvalidData
+	}
+	ok, err := uc.Repo.IncPhoneCodeAttempts(ctx, pc, uc.cfg.Phone.MaxAttempts)
+	if err != nil {
+		uc.logger(ctx).Error("fail to count phone code attempt", zap.Error(err))
+		return err
+	}
+	if !ok {
+		return ErrTooManyRequests
+	}
+	if !hmac.Equal(pc.CodeHash, uc.hashPhoneCode(pc.Phone, code)) {
+		uc.logger(ctx).Info("wrong phone code", zap.Uint64("user_id", uID), zap.Int("attempts", pc.Attempts))
+		return ErrInvalidData
+	}
+	if err = uc.Repo.ConfirmPhone(ctx, pc); err != nil {
+		uc.logger(ctx).Error("fail to confirm phone", zap.Error(err))
+		return mapRepoError(err)
+	}
+	uc.invalidateUser(ctx, uID)
+	return nil
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"backend/config"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// codeSender запоминает последнее SMS, чтобы тест прочитал из него код
+type codeSender struct {
+	phone, text string
+}
+
+func (s *codeSender) Send(_ context.Context, phone, text string) error {
+	s.phone, s.text = phone, text
+	return nil
+}
+
+func (s *codeSender) code() string {
+	return s.text[strings.LastIndex(s.text, " ")+1:]
+}
+
+func TestConfirmPhoneAttemptLimit(t *testing.T) {
+	const maxAttempts = 3
+	tests := []struct {
+		name string
+		// wrong - сколько неверных кодов ввести перед верным
+		wrong int
+		// resend - запросить новый код после неверных
+		resend  bool
+		wantErr error
+	}{
+		{name: "code on the last attempt", wrong: maxAttempts - 1},
+		{name: "attempts are exhausted", wrong: maxAttempts, wantErr: ErrTooManyRequests},
+		{name: "new code gets new attempts", wrong: maxAttempts, resend: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.ConfigModel{}
+			cfg.Phone = config.PhoneConfig{
+				DefaultCountryCode: "7",
+				CodeSecret:         "test",
+				CodeLength:         6,
+				CodeTTL:            time.Minute,
+				MaxAttempts:        maxAttempts,
+			}
+			sender := &codeSender{}
+			uc := newDBUsecase(t, sender, cfg)
+			ctx := context.Background()
+
+			if err := uc.RequestPhoneCode(ctx, testUserID, "8 (999) 000-00-01"); err != nil {
+				t.Fatalf("RequestPhoneCode() error = %v", err)
+			}
+			code := sender.code()
+			// неверный код той же длины
+			wrong := "0" + code[1:]
+			if code[0] == '0' {
+				wrong = "1" + code[1:]
+			}
+			for i := 0; i < tt.wrong; i++ {
+				if err := uc.ConfirmPhone(ctx, testUserID, wrong); !errors.Is(err, ErrInvalidData) {
+					t.Fatalf("wrong code %d: err = %v, want %v", i+1, err, ErrInvalidData)
+				}
+			}
+			if tt.resend {
+				if err := uc.RequestPhoneCode(ctx, testUserID, "+79990000001"); err != nil {
+					t.Fatalf("RequestPhoneCode() error = %v", err)
+				}
+				code = sender.code()
+			}
+			err := uc.ConfirmPhone(ctx, testUserID, code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ConfirmPhone() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var phone *string
+			if err = uc.Repo.DB.QueryRow(ctx, `SELECT number_phone FROM users WHERE id = $1`, testUserID).Scan(&phone); err != nil {
+				t.Fatal(err)
+			}
+			confirmed := phone != nil && *phone == sender.phone
+			if confirmed != (tt.wantErr == nil) {
+				t.Errorf("phone confirmed = %v, want %v", confirmed, tt.wantErr == nil)
+			}
+		})
+	}
+}
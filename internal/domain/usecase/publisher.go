@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"backend/config"
+	"backend/internal/health"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Publisher - фоновый воркер, который публикует отложенные объявления
+type Publisher struct {
+	log    *zap.Logger
+	uc     *Usecase
+	cfg    config.PublisherConfig
+	health *health.Worker
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewPublisher(log *zap.Logger, cfg *config.ConfigModel, uc *Usecase) *Publisher {
+	return &Publisher{
+		log: log.Named("publisher"),
+		uc:  uc,
+		cfg: cfg.Publisher,
+		// воркер считается зависшим после трех пропущенных итераций
+		health: health.NewWorker("publisher", 3*cfg.Publisher.Interval),
+	}
+}
+
+// Checker - проверка готовности для /readyz
+func (p *Publisher) Checker() health.Checker {
+	return p.health
+}
+
+func (p *Publisher) OnStart(_ context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	go p.run(ctx)
+	return nil
+}
+
+func (p *Publisher) OnStop(ctx context.Context) error {
+	p.cancel()
+	p.health.Stop()
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Publisher) run(ctx context.Context) {
+	defer close(p.done)
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		n, err := p.uc.PublishScheduledAdvertisments(ctx, p.cfg.BatchSize)
+		if err != nil && ctx.Err() == nil {
+			p.log.Error("fail to publish scheduled advertisments", zap.Error(err))
+		}
+		if n > 0 {
+			p.log.Info("scheduled advertisments published", zap.Int("count", n))
+		}
+		p.health.Beat(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
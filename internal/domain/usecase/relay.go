@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"backend/config"
+	"backend/internal/domain/entities"
+	"backend/internal/health"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Relay - фоновый воркер, который разбирает outbox и передает события
+// подписанным обработчикам
+type Relay struct {
+	log      *zap.Logger
+	uc       *Usecase
+	cfg      config.OutboxConfig
+	health   *health.Worker
+	handlers map[string][]OutboxHandler
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewRelay(log *zap.Logger, cfg *config.ConfigModel, uc *Usecase) *Relay {
+	r := &Relay{
+		log: log.Named("relay"),
+		uc:  uc,
+		cfg: cfg.Outbox,
+		// воркер считается зависшим после трех пропущенных итераций
+		health:   health.NewWorker("relay", 3*cfg.Outbox.Interval),
+		handlers: map[string][]OutboxHandler{},
+	}
+	r.Subscribe(uc.enqueueWebhooks, entities.WebhookEventTypes...)
+	r.Subscribe(uc.matchPublishedAdvertisment, entities.EventAdPublished, entities.EventAdApproved)
+	return r
+}
+
+// Subscribe подписывает обработчик на события eventTypes. Вызывается до OnStart.
+func (r *Relay) Subscribe(handler OutboxHandler, eventTypes ...string) {
+	for _, eventType := range eventTypes {
+		r.handlers[eventType] = append(r.handlers[eventType], handler)
+	}
+}
+
+// Checker - проверка готовности для /readyz
+func (r *Relay) Checker() health.Checker {
+	return r.health
+}
+
+func (r *Relay) OnStart(_ context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go r.run(ctx)
+	return nil
+}
+
+func (r *Relay) OnStop(ctx context.Context) error {
+	r.cancel()
+	r.health.Stop()
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dispatch передает событие всем обработчикам. При ошибке событие повторится
+// целиком, в том числе для уже отработавших обработчиков.
+func (r *Relay) dispatch(ctx context.Context, event *entities.OutboxEvent) error {
+	for _, handle := range r.handlers[event.Type] {
+		if err := handle(ctx, event); err != nil {
+			r.log.Warn("outbox handler failed",
+				zap.Uint64("event_id", event.ID),
+				zap.String("event_type", event.Type),
+				zap.Int("attempts", event.Attempts+1),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Relay) run(ctx context.Context) {
+	defer close(r.done)
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		n, err := r.uc.ProcessOutbox(ctx, r.cfg.BatchSize, r.dispatch)
+		if err != nil && ctx.Err() == nil {
+			r.log.Error("fail to process outbox", zap.Error(err))
+		}
+		if n > 0 {
+			r.log.Debug("outbox events processed", zap.Int("count", n))
+		}
+		r.health.Beat(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
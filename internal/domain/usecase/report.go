@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"backend/internal/domain/entities"
+	"backend/internal/rbac"
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const maxReportCommentLen = 500
+
+var reportReasons = map[string]struct{}{
+	entities.ReportReasonSpam:       {},
+	entities.ReportReasonFraud:      {},
+	entities.ReportReasonAbuse:      {},
+	entities.ReportReasonProhibited: {},
+	entities.ReportReasonOther:      {},
+}
+
+func validateReport(report *entities.Report) error {
+	report.Comment = strings.TrimSpace(report.Comment)
+	if _, ok := reportReasons[report.Reason]; !ok {
+		return ErrInvalidData
+	}
+	if report.TargetID == 0 || utf8.RuneCountInString(report.Comment) > maxReportCommentLen {
+		return ErrInvalidData
+	}
+	// без комментария причина "другое" ничего не говорит модератору
+	if report.Reason == entities.ReportReasonOther && report.Comment == "" {
+		return ErrInvalidData
+	}
+	return nil
+}
+
+// checkReportTarget: объект жалобы существует и не принадлежит автору жалобы
+func (uc *Usecase) checkReportTarget(ctx context.Context, report *entities.Report) error {
+	switch report.TargetType {
+	case entities.ReportTargetAdvertisment:
+		if exist, err := uc.Repo.IsAdExist(ctx, &entities.Advertisment{ID: report.TargetID}); err != nil || !exist {
+			uc.logger(ctx).Info("reported advertisment does not exist", zap.Error(err))
+			return ErrNotFound
+		}
+		ownerID, status, err := uc.Repo.GetAdvertismentStatus(ctx, report.TargetID)
+		if err != nil {
+			uc.logger(ctx).Error("fail to get advertisment status", zap.Error(err))
+			return mapRepoError(err)
+		}
+		// архивные и удаленные объявления уже не показываются в ленте
+		if status != entities.AdStatusActive {
+			return ErrNotFound
+		}
+		if ownerID == report.ReporterID {
+			return ErrInvalidData
+		}
+	case entities.ReportTargetReview:
+		if exist, err := uc.Repo.IsReviewExist(ctx, report.TargetID); err != nil || !exist {
+			uc.logger(ctx).Info("reported review does not exist", zap.Error(err))
+			return ErrNotFound
+		}
+	case entities.ReportTargetUser:
+		if report.TargetID == report.ReporterID {
+			return ErrInvalidData
+		}
+		if exist, err := uc.Repo.IsUserExist(ctx, &entities.User{ID: report.TargetID}); err != nil || !exist {
+			uc.logger(ctx).Info("reported user does not exist", zap.Error(err))
+			return ErrNotFound
+		}
+	default:
+		return ErrInvalidData
+	}
+	return nil
+}
+
+// CreateReport принимает жалобу. Повторная открытая жалоба того же
+// пользователя на тот же объект - ErrConflict.
+func (uc *Usecase) CreateReport(ctx context.Context, report *entities.Report) error {
+	ctx, span := tracer.Start(ctx, "Usecase.CreateReport")
+	defer span.End()
+	if err := validateReport(report); err != nil {
+		return err
+	}
+	if exist, err := uc.Repo.IsUserExist(ctx, &entities.User{ID: report.ReporterID}); err != nil || !exist {
+		uc.logger(ctx).Error("user does not exist", zap.Error(err))
+		return ErrForbidden
+	}
+	if err := uc.checkReportTarget(ctx, report); err != nil {
+		return err
+	}
+	// жалоба и автоматическое скрытие по ней фиксируются вместе
+	err := uc.Repo.WithTx(ctx, func(ctx context.Context) error {
+		if err := uc.Repo.CreateReport(ctx, report); err != nil {
+			uc.logger(ctx).Error("fail to create report", zap.Error(err))
+			return err
+		}
+		if report.TargetType == entities.ReportTargetAdvertisment {
+			return uc.autoHideReportedAdvertisment(ctx, report.TargetID)
+		}
+		return nil
+	})
+	if err != nil {
+		return mapRepoError(err)
+	}
+	// жалоба могла скрыть объявление
+	if report.TargetType == entities.ReportTargetAdvertisment {
+		uc.invalidate(ctx, adCacheKey(report.TargetID))
+	}
+	return nil
+}
+
+// autoHideReportedAdvertisment скрывает объявление, когда на него пожаловались
+// Moderation.ReportsToHide разных пользователей. Вернуть его может модератор
+// через /moderation/advertisment/hide.
+func (uc *Usecase) autoHideReportedAdvertisment(ctx context.Context, adID uint64) error {
+	threshold := uc.cfg.Moderation.ReportsToHide
+	if threshold <= 0 {
+		return nil
+	}
+	// без блокировки две одновременные жалобы, добивающие порог, видят по
+	// threshold-1 чужих жалоб и ни одна не скрывает объявление. После
+	// ожидания блокировки подсчет уже видит зафиксированную жалобу соседа.
+	if err := uc.Repo.LockAdvertisment(ctx, adID); err != nil {
+		uc.logger(ctx).Error("fail to lock advertisment", zap.Error(err))
+		return err
+	}
+	reporters, err := uc.Repo.CountOpenReporters(ctx, entities.ReportTargetAdvertisment, adID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to count reporters", zap.Error(err))
+		return err
+	}
+	if reporters < uint64(threshold) {
+		return nil
+	}
+	if err = uc.Repo.HideAdvertisment(ctx, adID, true); err != nil {
+		uc.logger(ctx).Error("fail to hide reported advertisment", zap.Error(err))
+		return err
+	}
+	uc.logger(ctx).Warn("advertisment hidden after reports",
+		zap.Uint64("ad_id", adID),
+		zap.Uint64("reporters", reporters),
+	)
+	return nil
+}
+
+func (uc *Usecase) GetReports(ctx context.Context, status string, limit, offset uint64) (*[]*entities.Report, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetReports")
+	defer span.End()
+	switch status {
+	case "":
+		status = entities.ReportStatusOpen
+	case entities.ReportStatusOpen, entities.ReportStatusResolved, entities.ReportStatusDismissed:
+	default:
+		return nil, ErrInvalidData
+	}
+	if limit == 0 {
+		limit = defaultQueueLimit
+	}
+	if limit > maxQueueLimit {
+		limit = maxQueueLimit
+	}
+	reports := []*entities.Report{}
+	if err := uc.Repo.GetReports(ctx, status, limit, offset, &reports); err != nil {
+		uc.logger(ctx).Error("fail to get reports", zap.Error(err))
+		return nil, err
+	}
+	return &reports, nil
+}
+
+// TriageReport закрывает жалобу как resolved или dismissed. С hideTarget
+// объявление или отзыв из жалобы скрывается.
+func (uc *Usecase) TriageReport(ctx context.Context, moderatorID, reportID uint64, status string, hideTarget bool) error {
+	ctx, span := tracer.Start(ctx, "Usecase.TriageReport")
+	defer span.End()
+	if status != entities.ReportStatusResolved && status != entities.ReportStatusDismissed {
+		return ErrInvalidData
+	}
+	if err := uc.Authorize(ctx, moderatorID, rbac.PermTriageReports); err != nil {
+		return err
+	}
+	report := &entities.Report{
+		ID:         reportID,
+		Status:     status,
+		ResolvedBy: moderatorID,
+	}
+	var reviewAdID, reviewSellerID uint64
+	// жалоба не закрывается, если объект из нее не удалось скрыть
+	if err := uc.Repo.WithTx(ctx, func(ctx context.Context) error {
+		if err := uc.Repo.ResolveReport(ctx, report); err != nil {
+			uc.logger(ctx).Error("fail to resolve report", zap.Error(err))
+			return err
+		}
+		if !hideTarget {
+			return nil
+		}
+		var err error
+		switch report.TargetType {
+		// объявление могло быть уже скрыто по жалобам, поэтому без проверки видимости
+		case entities.ReportTargetAdvertisment:
+			err = uc.Repo.HideAdvertisment(ctx, report.TargetID, true)
+		case entities.ReportTargetReview:
+			if reviewAdID, reviewSellerID, err = uc.Repo.GetReviewTarget(ctx, report.TargetID); err == nil {
+				err = uc.Repo.HideReview(ctx, report.TargetID, true)
+			}
+		default:
+			return ErrInvalidData
+		}
+		if err != nil {
+			uc.logger(ctx).Error("fail to hide reported object", zap.Error(err))
+		}
+		return err
+	}); err != nil {
+		return mapRepoError(err)
+	}
+	if hideTarget {
+		switch report.TargetType {
+		case entities.ReportTargetAdvertisment:
+			uc.invalidate(ctx, adCacheKey(report.TargetID))
+		case entities.ReportTargetReview:
+			uc.invalidate(ctx, adCacheKey(reviewAdID), profileCacheKey(reviewSellerID))
+		}
+	}
+	uc.logger(ctx).Info("report triaged by moderator",
+		zap.Uint64("moderator_id", moderatorID),
+		zap.Uint64("report_id", reportID),
+		zap.String("status", status),
+		zap.Bool("hide_target", hideTarget),
+	)
+	return nil
+}
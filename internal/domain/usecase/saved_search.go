@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"backend/internal/domain/entities"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxSavedSearchNameLen = 100
+	// savedSearchDigestPeriod - дайджест приходит не чаще раза в сутки
+	savedSearchDigestPeriod = 24 * time.Hour
+)
+
+func (uc *Usecase) CreateSavedSearch(ctx context.Context, uID uint64, search *entities.SavedSearch) error {
+	ctx, span := tracer.Start(ctx, "Usecase.CreateSavedSearch")
+	defer span.End()
+	search.UserID = uID
+	search.Name = strings.TrimSpace(search.Name)
+	if search.Name == "" || utf8.RuneCountInString(search.Name) > maxSavedSearchNameLen {
+		return ErrInvalidData
+	}
+	switch search.Notify {
+	case "":
+		search.Notify = entities.SavedSearchNotifyInstant
+	case entities.SavedSearchNotifyInstant, entities.SavedSearchNotifyDaily:
+	default:
+		return ErrInvalidData
+	}
+	// сортировка и страница не влияют на то, какие объявления подходят
+	search.Filter.Sort = ""
+	search.Filter.Limit = 0
+	search.Filter.Offset = 0
+	if err := validateSearchFilter(&search.Filter); err != nil {
+		return err
+	}
+	count, err := uc.Repo.CountSavedSearches(ctx, uID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to count saved searches", zap.Error(err))
+		return err
+	}
+	if count >= uint64(uc.cfg.SavedSearch.MaxPerUser) {
+		return ErrConflict
+	}
+	if err = uc.Repo.CreateSavedSearch(ctx, search); err != nil {
+		uc.logger(ctx).Error("fail to create saved search", zap.Error(err))
+		return mapRepoError(err)
+	}
+	return nil
+}
+
+func (uc *Usecase) GetSavedSearches(ctx context.Context, uID uint64) (*[]*entities.SavedSearch, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetSavedSearches")
+	defer span.End()
+	searches := []*entities.SavedSearch{}
+	if err := uc.Repo.GetSavedSearches(ctx, uID, &searches); err != nil {
+		uc.logger(ctx).Error("fail to get saved searches", zap.Error(err))
+		return nil, err
+	}
+	return &searches, nil
+}
+
+func (uc *Usecase) DeleteSavedSearch(ctx context.Context, uID, searchID uint64) error {
+	ctx, span := tracer.Start(ctx, "Usecase.DeleteSavedSearch")
+	defer span.End()
+	if err := uc.Repo.DeleteSavedSearch(ctx, uID, searchID); err != nil {
+		uc.logger(ctx).Error("fail to delete saved search", zap.Error(err))
+		return mapRepoError(err)
+	}
+	return nil
+}
+
+// matchSavedSearches ищет сохраненные поиски, которым подходит только что
+// ставшее видимым объявление. Каждое объявление попадает в поиск один раз;
+// для instant уведомление уходит сразу, для daily копится до дайджеста.
+// Вызывается из outbox и может повториться для того же объявления: совпадения
+// и уведомления дедуплицируются. Ошибка по одному поиску не мешает остальным,
+// но возвращается, чтобы событие обработалось повторно.
+func (uc *Usecase) matchSavedSearches(ctx context.Context, adID uint64) error {
+	ctx, span := tracer.Start(ctx, "Usecase.matchSavedSearches")
+	defer span.End()
+	searches := []*entities.SavedSearch{}
+	if err := uc.Repo.GetSavedSearchCandidates(ctx, adID, &searches); err != nil {
+		uc.logger(ctx).Error("fail to get saved search candidates", zap.Uint64("ad_id", adID), zap.Error(err))
+		return err
+	}
+	var advertisment *entities.Advertisment
+	var matchErr error
+	for _, search := range searches {
+		matched, err := uc.Repo.MatchSavedSearch(ctx, &search.Filter, adID)
+		if err != nil {
+			uc.logger(ctx).Error("fail to match saved search", zap.Uint64("saved_search_id", search.ID), zap.Error(err))
+			matchErr = err
+			continue
+		}
+		if !matched {
+			continue
+		}
+		instant := search.Notify == entities.SavedSearchNotifyInstant
+		created, err := uc.Repo.CreateSavedSearchMatch(ctx, search.ID, adID, instant)
+		if err != nil {
+			uc.logger(ctx).Error("fail to save saved search match", zap.Uint64("saved_search_id", search.ID), zap.Error(err))
+			matchErr = err
+			continue
+		}
+		if !created || !instant {
+			continue
+		}
+		if advertisment == nil {
+			advertisment = &entities.Advertisment{ID: adID}
+			if err = uc.Repo.GetAdvertismentAllInfo(ctx, advertisment); err != nil {
+				uc.logger(ctx).Error("fail to get advertisment for notification", zap.Uint64("ad_id", adID), zap.Error(err))
+				return err
+			}
+		}
+		// одно объявление из нескольких поисков пользователя - одно уведомление
+		if err = uc.notify(ctx, &entities.Notification{
+			UserID:   search.UserID,
+			Kind:     entities.NotificationSavedSearchMatch,
+			Title:    fmt.Sprintf("Новое объявление по поиску «%s»", search.Name),
+			Body:     fmt.Sprintf("%s, %.0f ₽", advertisment.Name, advertisment.Price),
+			Payload:  map[string]interface{}{"saved_search_id": search.ID, "ad_id": adID},
+			DedupKey: fmt.Sprintf("saved_search_ad:%d", adID),
+		}); err != nil {
+			matchErr = err
+		}
+	}
+	return matchErr
+}
+
+// SendSavedSearchDigests отправляет суточные дайджесты пачками по batchSize.
+// Совпадения пачки отмечаются и уведомляются в одной транзакции: при ошибке
+// отметки откатываются и дайджест уйдет в следующий запуск. Возвращает
+// число отправленных.
+func (uc *Usecase) SendSavedSearchDigests(ctx context.Context, batchSize int) (int, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.SendSavedSearchDigests")
+	defer span.End()
+	total := 0
+	for {
+		digests := []*entities.SavedSearchDigest{}
+		err := uc.Repo.WithTx(ctx, func(ctx context.Context) error {
+			// повтор транзакции после конфликта берет пачку заново
+			digests = digests[:0]
+			if err := uc.Repo.TakeSavedSearchDigests(ctx, savedSearchDigestPeriod, batchSize, &digests); err != nil {
+				uc.logger(ctx).Error("fail to take saved search digests", zap.Error(err))
+				return err
+			}
+			for _, digest := range digests {
+				search := &digest.SavedSearch
+				if err := uc.notify(ctx, &entities.Notification{
+					UserID:   search.UserID,
+					Kind:     entities.NotificationSavedSearchDigest,
+					Title:    fmt.Sprintf("Новые объявления по поиску «%s»: %d", search.Name, len(digest.AdIDs)),
+					Payload:  map[string]interface{}{"saved_search_id": search.ID, "ad_ids": digest.AdIDs},
+					DedupKey: fmt.Sprintf("saved_search_digest:%d:%s", search.ID, time.Now().Format(time.DateOnly)),
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+		total += len(digests)
+		if len(digests) < batchSize {
+			return total, nil
+		}
+	}
+}
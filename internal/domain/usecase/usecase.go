@@ -1,50 +1,125 @@
 package usecase
 
 import (
+	"backend/config"
+	"backend/internal/cache"
 	"backend/internal/domain/entities"
 	"backend/internal/domain/repository/postgres"
+	"backend/internal/logging"
+	"backend/internal/metrics"
+	"backend/internal/notify"
+	"backend/internal/sms"
+	"backend/internal/webhook"
 	"context"
 	"errors"
+	"time"
 
+	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
+var tracer = otel.Tracer("backend/internal/domain/usecase")
+
 type Usecase struct {
-	log  *zap.Logger
-	Repo *postgres.Repository
+	log     *zap.Logger
+	cfg     *config.ConfigModel
+	Repo    *postgres.Repository
+	metrics *metrics.Metrics
+	sms     sms.Sender
+	// notifier - внешние каналы доставки уведомлений
+	notifier *notify.Dispatcher
+	webhooks *webhook.Client
+	// cache - кеш ответов, flight склеивает одновременные промахи по ключу
+	cache  cache.Cache
+	flight singleflight.Group
+	// phoneCodeKey - ключ HMAC кодов подтверждения телефона
+	phoneCodeKey []byte
 }
 
-func NewUsecase(logger *zap.Logger, Repo *postgres.Repository) (*Usecase, error) {
+func NewUsecase(logger *zap.Logger, cfg *config.ConfigModel, Repo *postgres.Repository, m *metrics.Metrics, sender sms.Sender, notifier *notify.Dispatcher, webhooks *webhook.Client, responses cache.Cache) (*Usecase, error) {
+	key, err := newPhoneCodeKey(cfg.Phone.CodeSecret)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Phone.CodeSecret == "" {
+		logger.Warn("phone code secret is not set, codes will not survive restart")
+	}
 	return &Usecase{
-		log:  logger,
-		Repo: Repo,
+		log:          logger,
+		cfg:          cfg,
+		Repo:         Repo,
+		metrics:      m,
+		sms:          sender,
+		notifier:     notifier,
+		webhooks:     webhooks,
+		cache:        responses,
+		phoneCodeKey: key,
 	}, nil
 }
 
-func (uc *Usecase) GetAdvertismentAllInfo(ctx context.Context, advertisment *entities.Advertisment) error {
+func (uc *Usecase) logger(ctx context.Context) *zap.Logger {
+	return logging.FromContext(ctx, uc.log)
+}
+
+// ReadPrimary направляет чтения с ctx в основную базу, минуя реплики: для
+// запросов, которые должны увидеть недавние изменения пользователя
+func ReadPrimary(ctx context.Context) context.Context {
+	return postgres.WithPrimary(ctx)
+}
+
+// GetAdvertismentAllInfo: объявление не из статуса approved видят только
+// владелец и модераторы, для остальных его нет
+func (uc *Usecase) GetAdvertismentAllInfo(ctx context.Context, viewerID uint64, advertisment *entities.Advertisment) error {
+	ctx, span := tracer.Start(ctx, "Usecase.GetAdvertismentAllInfo")
+	defer span.End()
+	// в кеше карточка целиком, права зрителя проверяются на каждый запрос
+	loaded, err := cached(ctx, uc, adCacheKey(advertisment.ID), uc.cfg.Cache.AdTTL,
+		func(ctx context.Context) (*entities.Advertisment, error) {
+			ad := &entities.Advertisment{ID: advertisment.ID}
+			return ad, uc.loadAdvertismentAllInfo(ctx, ad)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if !uc.canViewAdvertisment(ctx, viewerID, loaded) {
+		return errors.New("advertisment does not exist")
+	}
+	*advertisment = *loaded
+	return nil
+}
+
+// loadAdvertismentAllInfo читает карточку объявления с продавцом, отзывами,
+// фотографиями и атрибутами
+func (uc *Usecase) loadAdvertismentAllInfo(ctx context.Context, advertisment *entities.Advertisment) error {
 	if exist, err := uc.Repo.IsAdExist(ctx, advertisment); err != nil || !exist {
-		uc.log.Error("advertisment does not exist", zap.Error(err))
+		uc.logger(ctx).Error("advertisment does not exist", zap.Error(err))
 		return errors.New("advertisment does not exist")
 	}
 
-	if err := uc.Repo.GetAdvertismentAllInfo(ctx, advertisment); err != nil{
-		uc.log.Error("fail to get Advertisment", zap.Error(err))
+	if err := uc.Repo.GetAdvertismentAllInfo(ctx, advertisment); err != nil {
+		uc.logger(ctx).Error("fail to get Advertisment", zap.Error(err))
 		return err
 	}
 	if exist, err := uc.Repo.IsUserExist(ctx, &advertisment.User); err != nil || !exist {
-		uc.log.Error("user does not exist", zap.Error(err))
+		uc.logger(ctx).Error("user does not exist", zap.Error(err))
 		return errors.New("user does not exist")
 	}
-	if err := uc.Repo.GetUserInfo(ctx, &advertisment.User); err != nil{
-		uc.log.Error("fail to get seller info by Advertisment ID", zap.Error(err))
+	if err := uc.Repo.GetUserInfo(ctx, &advertisment.User); err != nil {
+		uc.logger(ctx).Error("fail to get seller info by Advertisment ID", zap.Error(err))
 		return err
 	}
-	if err := uc.Repo.GetAdvertismentReviews(ctx, advertisment); err != nil{
-		uc.log.Error("fail to get Reviews by Advertisment ID", zap.Error(err))
+	if err := uc.Repo.GetAdvertismentReviews(ctx, advertisment); err != nil {
+		uc.logger(ctx).Error("fail to get Reviews by Advertisment ID", zap.Error(err))
 		return err
 	}
-	if err := uc.Repo.GetAdvertismentPhotos(ctx, advertisment); err != nil{
-		uc.log.Error("fail to get Photos by Advertisment ID", zap.Error(err))
+	if err := uc.Repo.GetAdvertismentPhotos(ctx, advertisment); err != nil {
+		uc.logger(ctx).Error("fail to get Photos by Advertisment ID", zap.Error(err))
+		return err
+	}
+	if err := uc.Repo.GetAdvertismentAttributes(ctx, advertisment); err != nil {
+		uc.logger(ctx).Error("fail to get Attributes by Advertisment ID", zap.Error(err))
 		return err
 	}
 
@@ -82,84 +157,109 @@ func (uc *Usecase) GetAdvertismentAllInfo(ctx context.Context, advertisment *ent
 // 	return nil
 // }
 
-
 func (uc *Usecase) GetProfileUserAllInfo(ctx context.Context, user *entities.User) error {
-	if exist, err := uc.Repo.IsUserExist(ctx, user); err != nil || !exist {
-		uc.log.Error("user does not exist", zap.Error(err))
-		return errors.New("user does not exist")
-	}
-	if err := uc.Repo.GetUserInfo(ctx, user); err != nil{
-		uc.log.Error("fail to get user profile info", zap.Error(err))
+	ctx, span := tracer.Start(ctx, "Usecase.GetProfileUserAllInfo")
+	defer span.End()
+	loaded, err := cached(ctx, uc, profileCacheKey(user.ID), uc.cfg.Cache.ProfileTTL,
+		func(ctx context.Context) (*entities.User, error) {
+			profile := &entities.User{ID: user.ID}
+			if exist, err := uc.Repo.IsUserExist(ctx, profile); err != nil || !exist {
+				uc.logger(ctx).Error("user does not exist", zap.Error(err))
+				return nil, errors.New("user does not exist")
+			}
+			if err := uc.Repo.GetUserInfo(ctx, profile); err != nil {
+				uc.logger(ctx).Error("fail to get user profile info", zap.Error(err))
+				return nil, err
+			}
+			return profile, nil
+		},
+	)
+	if err != nil {
 		return err
 	}
-
+	*user = *loaded
 	return nil
 }
 
+// ProfileUpdatedAt - версия профиля для условных запросов
+func (uc *Usecase) ProfileUpdatedAt(ctx context.Context, uID uint64) (time.Time, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.ProfileUpdatedAt")
+	defer span.End()
+	updatedAt, err := uc.Repo.GetUserUpdatedAt(ctx, uID)
+	return updatedAt, mapRepoError(err)
+}
+
 func (uc *Usecase) GetProfileUserStatistics(ctx context.Context, uID uint64) (*[]*entities.ProfileStatistic, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetProfileUserStatistics")
+	defer span.End()
 	var stats []*entities.ProfileStatistic
-	if exist, err := uc.Repo.IsUserExist(ctx, &entities.User{ID:uID}); err != nil || !exist {
-		uc.log.Error("user does not exist", zap.Error(err))
+	if exist, err := uc.Repo.IsUserExist(ctx, &entities.User{ID: uID}); err != nil || !exist {
+		uc.logger(ctx).Error("user does not exist", zap.Error(err))
 		return nil, errors.New("user does not exist")
 	}
 
 	if err := uc.Repo.GetProfileUserStatistics(ctx, uID, &stats); err != nil {
-		uc.log.Error("fail to ads by buyer id", zap.Error(err))
+		uc.logger(ctx).Error("fail to ads by buyer id", zap.Error(err))
 		return nil, err
 	}
-	for _, stat := range stats{
+	for _, stat := range stats {
 		var err error
 		stat.AdPhotoPath, err = uc.Repo.GetMainAdPhotoByAdID(ctx, stat.AdID)
-		if err != nil{
-			uc.log.Error("fail to get Photos by Advertisment ID", zap.Error(err))
+		if err != nil {
+			uc.logger(ctx).Error("fail to get Photos by Advertisment ID", zap.Error(err))
 		}
 		if exist, err := uc.Repo.IsReviewExistByDealID(ctx, stat.DealID); err != nil || !exist {
-			uc.log.Error("review does not exist", zap.Error(err))
+			uc.logger(ctx).Error("review does not exist", zap.Error(err))
 		}
-		if err := uc.Repo.GetStatisticAdReviewMark(ctx, stat); err != nil{
-			uc.log.Error("fail to get Review by Deal ID", zap.Error(err))
+		if err := uc.Repo.GetStatisticAdReviewMark(ctx, stat); err != nil {
+			uc.logger(ctx).Error("fail to get Review by Deal ID", zap.Error(err))
 		}
 
 	}
-	
+
 	return &stats, nil
 }
 
-func (uc *Usecase) GetProfileMyAdvertisments(ctx context.Context, uID uint64) (*[]*entities.MyAdvertisement, error) {
+// GetProfileMyAdvertisments: черновики и отложенные объявления видит только владелец
+func (uc *Usecase) GetProfileMyAdvertisments(ctx context.Context, viewerID, uID uint64) (*[]*entities.MyAdvertisement, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetProfileMyAdvertisments")
+	defer span.End()
 	var advertisements []*entities.MyAdvertisement
-	if exist, err := uc.Repo.IsUserExist(ctx, &entities.User{ID:uID}); err != nil || !exist {
-		uc.log.Error("user does not exist", zap.Error(err))
+	if exist, err := uc.Repo.IsUserExist(ctx, &entities.User{ID: uID}); err != nil || !exist {
+		uc.logger(ctx).Error("user does not exist", zap.Error(err))
 		return nil, errors.New("user does not exist")
 	}
 
-	if err := uc.Repo.GetProfileMyAdvertisments(ctx, uID, &advertisements); err != nil {
-		uc.log.Error("fail to get ads by user id", zap.Error(err))
+	if err := uc.Repo.GetProfileMyAdvertisments(ctx, uID, viewerID == uID, &advertisements); err != nil {
+		uc.logger(ctx).Error("fail to get ads by user id", zap.Error(err))
 		return nil, err
 	}
-	
-	for _, ad := range advertisements{
+
+	for _, ad := range advertisements {
 		var err error
 		ad.AdPhotoPath, err = uc.Repo.GetMainAdPhotoByAdID(ctx, ad.AdID)
 		if err != nil {
-			uc.log.Error("fail to get Photos by Advertisment ID", zap.Error(err))
+			uc.logger(ctx).Error("fail to get Photos by Advertisment ID", zap.Error(err))
 		}
 	}
-	
+
 	return &advertisements, nil
 }
 
 func (uc *Usecase) GetProfileReviews(ctx context.Context, uID uint64) (*[]*entities.ProfileReview, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetProfileReviews")
+	defer span.End()
 	var reviews []*entities.ProfileReview
-	if exist, err := uc.Repo.IsUserExist(ctx, &entities.User{ID:uID}); err != nil || !exist {
-		uc.log.Error("user does not exist", zap.Error(err))
+	if exist, err := uc.Repo.IsUserExist(ctx, &entities.User{ID: uID}); err != nil || !exist {
+		uc.logger(ctx).Error("user does not exist", zap.Error(err))
 		return nil, errors.New("user does not exist")
 	}
 
 	if err := uc.Repo.GetProfileReviews(ctx, uID, &reviews); err != nil {
-		uc.log.Error("fail to get reviews by user id", zap.Error(err))
+		uc.logger(ctx).Error("fail to get reviews by user id", zap.Error(err))
 		return nil, err
 	}
-	
+
 	// for _, ad := range reviews{
 	// 	var err error
 	// 	ad.AdPhotoPath, err = uc.Repo.GetMainAdPhotoByAdID(ctx, ad.AdID)
@@ -167,6 +267,6 @@ func (uc *Usecase) GetProfileReviews(ctx context.Context, uID uint64) (*[]*entit
 	// 		uc.log.Error("fail to get Photos by Advertisment ID", zap.Error(err))
 	// 	}
 	// }
-	
+
 	return &reviews, nil
-}
\ No newline at end of file
+}
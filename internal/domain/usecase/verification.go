@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"backend/internal/domain/entities"
+	"backend/internal/rbac"
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// RequestVerification подает заявку на верификацию продавца. Способ phone
+// доступен после подтверждения номера кодом из SMS.
+func (uc *Usecase) RequestVerification(ctx context.Context, req *entities.VerificationRequest) error {
+	ctx, span := tracer.Start(ctx, "Usecase.RequestVerification")
+	defer span.End()
+	user := &entities.User{ID: req.UserID}
+	if exist, err := uc.Repo.IsUserExist(ctx, user); err != nil || !exist {
+		uc.logger(ctx).Error("user does not exist", zap.Error(err))
+		return ErrForbidden
+	}
+	if err := uc.Repo.GetUserInfo(ctx, user); err != nil {
+		uc.logger(ctx).Error("fail to get user info", zap.Error(err))
+		return err
+	}
+	switch user.VerificationStatus {
+	case entities.VerificationPending, entities.VerificationVerified:
+		return ErrConflict
+	}
+
+	req.DocumentPath = strings.TrimSpace(req.DocumentPath)
+	switch req.Method {
+	case entities.VerificationMethodDocument:
+		if req.DocumentPath == "" {
+			return ErrInvalidData
+		}
+	case entities.VerificationMethodPhone:
+		if !user.PhoneConfirmed {
+			return ErrInvalidData
+		}
+		req.DocumentPath = ""
+	default:
+		return ErrInvalidData
+	}
+
+	if err := uc.Repo.CreateVerificationRequest(ctx, req); err != nil {
+		uc.logger(ctx).Error("fail to create verification request", zap.Error(err))
+		return mapRepoError(err)
+	}
+	uc.invalidateUser(ctx, req.UserID)
+	return nil
+}
+
+// GetMyVerification возвращает последнюю заявку пользователя
+func (uc *Usecase) GetMyVerification(ctx context.Context, uID uint64) (*entities.VerificationRequest, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetMyVerification")
+	defer span.End()
+	req := &entities.VerificationRequest{UserID: uID}
+	if err := uc.Repo.GetLastVerificationRequest(ctx, req); err != nil {
+		return nil, mapRepoError(err)
+	}
+	return req, nil
+}
+
+func (uc *Usecase) GetVerificationQueue(ctx context.Context, limit, offset uint64) (*[]*entities.VerificationRequest, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetVerificationQueue")
+	defer span.End()
+	if limit == 0 {
+		limit = defaultQueueLimit
+	}
+	if limit > maxQueueLimit {
+		limit = maxQueueLimit
+	}
+	reqs := []*entities.VerificationRequest{}
+	if err := uc.Repo.GetVerificationRequests(ctx, entities.VerificationPending, limit, offset, &reqs); err != nil {
+		uc.logger(ctx).Error("fail to get verification requests", zap.Error(err))
+		return nil, err
+	}
+	return &reqs, nil
+}
+
+// ReviewVerification: status verified или rejected, для отказа нужна причина
+func (uc *Usecase) ReviewVerification(ctx context.Context, moderatorID, requestID uint64, status, reason string) error {
+	ctx, span := tracer.Start(ctx, "Usecase.ReviewVerification")
+	defer span.End()
+	reason = strings.TrimSpace(reason)
+	switch status {
+	case entities.VerificationVerified:
+		reason = ""
+	case entities.VerificationRejected:
+		if reason == "" || utf8.RuneCountInString(reason) > maxRejectReasonLen {
+			return ErrInvalidData
+		}
+	default:
+		return ErrInvalidData
+	}
+	if err := uc.Authorize(ctx, moderatorID, rbac.PermReviewVerification); err != nil {
+		return err
+	}
+	req := &entities.VerificationRequest{
+		ID:         requestID,
+		Status:     status,
+		Reason:     reason,
+		ReviewedBy: moderatorID,
+	}
+	if err := uc.Repo.ReviewVerificationRequest(ctx, req); err != nil {
+		uc.logger(ctx).Error("fail to review verification request", zap.Error(err))
+		return mapRepoError(err)
+	}
+	uc.invalidateUser(ctx, req.UserID)
+	uc.logger(ctx).Info("verification request reviewed by moderator",
+		zap.Uint64("moderator_id", moderatorID),
+		zap.Uint64("request_id", requestID),
+		zap.Uint64("user_id", req.UserID),
+		zap.String("status", status),
+	)
+	return nil
+}
@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"backend/internal/domain/entities"
+	"backend/internal/domain/repository/postgres"
+	"backend/internal/webhook"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	minWebhookSecretLen = 16
+	maxWebhookSecretLen = 128
+	maxWebhookURLLen    = 500
+)
+
+// CreateWebhook: если секрет не передан, он генерируется и возвращается в
+// webhook.Secret единственный раз
+func (uc *Usecase) CreateWebhook(ctx context.Context, uID uint64, wh *entities.Webhook) error {
+	ctx, span := tracer.Start(ctx, "Usecase.CreateWebhook")
+	defer span.End()
+	wh.UserID = uID
+	wh.URL = strings.TrimSpace(wh.URL)
+	if len(wh.URL) > maxWebhookURLLen {
+		return ErrInvalidData
+	}
+	if err := uc.webhooks.ValidateURL(wh.URL); err != nil {
+		uc.logger(ctx).Info("invalid webhook url", zap.String("url", wh.URL), zap.Error(err))
+		return ErrInvalidData
+	}
+	if len(wh.EventTypes) == 0 {
+		return ErrInvalidData
+	}
+	for _, event := range wh.EventTypes {
+		if !slices.Contains(entities.WebhookEventTypes, event) {
+			return ErrInvalidData
+		}
+	}
+	slices.Sort(wh.EventTypes)
+	wh.EventTypes = slices.Compact(wh.EventTypes)
+	if wh.Secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return err
+		}
+		wh.Secret = hex.EncodeToString(buf)
+	}
+	if len(wh.Secret) < minWebhookSecretLen || len(wh.Secret) > maxWebhookSecretLen {
+		return ErrInvalidData
+	}
+	count, err := uc.Repo.CountWebhooks(ctx, uID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to count webhooks", zap.Error(err))
+		return err
+	}
+	if count >= uint64(uc.cfg.Webhook.MaxPerUser) {
+		return ErrConflict
+	}
+	if err = uc.Repo.CreateWebhook(ctx, wh); err != nil {
+		uc.logger(ctx).Error("fail to create webhook", zap.Error(err))
+		return mapRepoError(err)
+	}
+	return nil
+}
+
+func (uc *Usecase) GetWebhooks(ctx context.Context, uID uint64) (*[]*entities.Webhook, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetWebhooks")
+	defer span.End()
+	webhooks := []*entities.Webhook{}
+	if err := uc.Repo.GetWebhooks(ctx, uID, &webhooks); err != nil {
+		uc.logger(ctx).Error("fail to get webhooks", zap.Error(err))
+		return nil, err
+	}
+	return &webhooks, nil
+}
+
+func (uc *Usecase) DeleteWebhook(ctx context.Context, uID, webhookID uint64) error {
+	ctx, span := tracer.Start(ctx, "Usecase.DeleteWebhook")
+	defer span.End()
+	if err := uc.Repo.DeleteWebhook(ctx, uID, webhookID); err != nil {
+		uc.logger(ctx).Error("fail to delete webhook", zap.Error(err))
+		return mapRepoError(err)
+	}
+	return nil
+}
+
+func (uc *Usecase) GetWebhookDeliveries(ctx context.Context, uID, webhookID uint64, status string, limit, offset uint64) (*[]*entities.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.GetWebhookDeliveries")
+	defer span.End()
+	switch status {
+	case "", entities.WebhookDeliveryPending, entities.WebhookDeliveryDelivered, entities.WebhookDeliveryFailed:
+	default:
+		return nil, ErrInvalidData
+	}
+	if limit == 0 {
+		limit = defaultQueueLimit
+	}
+	if limit > maxQueueLimit {
+		limit = maxQueueLimit
+	}
+	deliveries := []*entities.WebhookDelivery{}
+	if err := uc.Repo.GetWebhookDeliveries(ctx, uID, webhookID, status, limit, offset, &deliveries); err != nil {
+		uc.logger(ctx).Error("fail to get webhook deliveries", zap.Error(err))
+		return nil, err
+	}
+	return &deliveries, nil
+}
+
+// ReplayWebhookDelivery заново ставит в очередь проваленную отправку
+func (uc *Usecase) ReplayWebhookDelivery(ctx context.Context, uID, deliveryID uint64) error {
+	ctx, span := tracer.Start(ctx, "Usecase.ReplayWebhookDelivery")
+	defer span.End()
+	status, err := uc.Repo.GetWebhookDeliveryStatus(ctx, uID, deliveryID)
+	if err != nil {
+		uc.logger(ctx).Error("fail to get webhook delivery status", zap.Error(err))
+		return mapRepoError(err)
+	}
+	if status != entities.WebhookDeliveryFailed {
+		return ErrConflict
+	}
+	if err = uc.Repo.ReplayWebhookDelivery(ctx, deliveryID); err != nil {
+		uc.logger(ctx).Error("fail to replay webhook delivery", zap.Error(err))
+		// отправку успели вернуть в очередь параллельным запросом
+		if errors.Is(err, postgres.ErrNotFound) {
+			return ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// webhookPayload - тело вебхука. ID - id события: по нему получатель
+// отбрасывает повторы.
+type webhookPayload struct {
+	ID        uint64          `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt *time.Time      `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// enqueueWebhooks - обработчик outbox: ставит событие объявления в очередь
+// подписок владельца. Повторная обработка не создает вторую отправку.
+func (uc *Usecase) enqueueWebhooks(ctx context.Context, event *entities.OutboxEvent) error {
+	data := entities.AdvertismentEvent{}
+	if err := json.Unmarshal(event.Payload, &data); err != nil {
+		uc.logger(ctx).Error("fail to unmarshal outbox event", zap.Uint64("event_id", event.ID), zap.Error(err))
+		return err
+	}
+	payload, err := json.Marshal(webhookPayload{
+		ID:        event.ID,
+		Event:     event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		uc.logger(ctx).Error("fail to marshal webhook payload", zap.String("event", event.Type), zap.Error(err))
+		return err
+	}
+	if _, err = uc.Repo.EnqueueWebhookEvent(ctx, data.UserID, event.Type, event.ID, payload); err != nil {
+		uc.logger(ctx).Error("fail to enqueue webhook event",
+			zap.Uint64("user_id", data.UserID),
+			zap.String("event", event.Type),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// DeliverWebhooks отправляет очередную пачку вебхуков. Неудачная отправка
+// остается в очереди до MaxAttempts попыток с растущей паузой, затем
+// помечается failed и ждет ручного повтора. Возвращает число доставленных.
+func (uc *Usecase) DeliverWebhooks(ctx context.Context, batchSize int) (int, error) {
+	ctx, span := tracer.Start(ctx, "Usecase.DeliverWebhooks")
+	defer span.End()
+	cfg := uc.cfg.Webhook
+	deliveries := []*entities.WebhookDelivery{}
+	if err := uc.Repo.TakeWebhookDeliveries(ctx, batchSize, cfg.RetryBaseDelay, cfg.RetryMaxDelay, &deliveries); err != nil {
+		uc.logger(ctx).Error("fail to take webhook deliveries", zap.Error(err))
+		return 0, err
+	}
+	delivered := 0
+	for _, d := range deliveries {
+		statusCode, err := uc.webhooks.Send(ctx, webhook.Request{
+			URL:        d.URL,
+			Secret:     d.Secret,
+			Event:      d.EventType,
+			DeliveryID: d.ID,
+			Payload:    d.Payload,
+		})
+		d.LastStatusCode = statusCode
+		switch {
+		case err == nil:
+			d.Status, d.LastError = entities.WebhookDeliveryDelivered, ""
+			delivered++
+		case d.Attempts >= cfg.MaxAttempts:
+			d.Status, d.LastError = entities.WebhookDeliveryFailed, err.Error()
+		default:
+			// повтор по next_attempt_at, выставленному при взятии
+			d.Status, d.LastError = entities.WebhookDeliveryPending, err.Error()
+		}
+		if err != nil {
+			uc.logger(ctx).Warn("fail to deliver webhook",
+				zap.Uint64("delivery_id", d.ID),
+				zap.Uint64("webhook_id", d.WebhookID),
+				zap.Int("attempt", d.Attempts),
+				zap.Int("status_code", statusCode),
+				zap.Error(err),
+			)
+		}
+		uc.metrics.WebhooksSent.WithLabelValues(d.Status).Inc()
+		if err = uc.Repo.SetWebhookDeliveryResult(ctx, d); err != nil {
+			uc.logger(ctx).Error("fail to save webhook delivery result", zap.Uint64("delivery_id", d.ID), zap.Error(err))
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"backend/config"
+	"backend/internal/health"
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// WebhookDispatcher - фоновый воркер очереди вебхуков
+type WebhookDispatcher struct {
+	log    *zap.Logger
+	uc     *Usecase
+	cfg    config.WebhookConfig
+	health *health.Worker
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewWebhookDispatcher(log *zap.Logger, cfg *config.ConfigModel, uc *Usecase) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		log: log.Named("webhooks"),
+		uc:  uc,
+		cfg: cfg.Webhook,
+		// воркер считается зависшим после трех пропущенных итераций; одна итерация
+		// может ждать таймаут каждого получателя пачки
+		health: health.NewWorker("webhooks", 3*cfg.Webhook.DeliveryInterval+time.Duration(cfg.Webhook.DeliveryBatchSize)*cfg.Webhook.RequestTimeout),
+	}
+}
+
+// Checker - проверка готовности для /readyz
+func (w *WebhookDispatcher) Checker() health.Checker {
+	return w.health
+}
+
+func (w *WebhookDispatcher) OnStart(_ context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	go w.run(ctx)
+	return nil
+}
+
+func (w *WebhookDispatcher) OnStop(ctx context.Context) error {
+	w.cancel()
+	w.health.Stop()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *WebhookDispatcher) run(ctx context.Context) {
+	defer close(w.done)
+	ticker := time.NewTicker(w.cfg.DeliveryInterval)
+	defer ticker.Stop()
+	for {
+		n, err := w.uc.DeliverWebhooks(ctx, w.cfg.DeliveryBatchSize)
+		if err != nil && ctx.Err() == nil {
+			w.log.Error("fail to deliver webhooks", zap.Error(err))
+		}
+		if n > 0 {
+			w.log.Info("webhooks delivered", zap.Int("count", n))
+		}
+		w.health.Beat(err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Group - тег fx-группы, в которую модули отдают свои проверки готовности
+const Group = `group:"health"`
+
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type CheckFunc struct {
+	CheckName string
+	Fn        func(ctx context.Context) error
+}
+
+func (c CheckFunc) Name() string {
+	return c.CheckName
+}
+
+func (c CheckFunc) Check(ctx context.Context) error {
+	return c.Fn(ctx)
+}
+
+// Detailer - проверка, которая сообщает подробности и в исправном состоянии
+type Detailer interface {
+	Detail() string
+}
+
+// MaxWorkerFailures - после стольких ошибок подряд воркер считается неготовым.
+// Одиночная ошибка общей зависимости не должна снимать с балансировки все
+// инстансы разом.
+const MaxWorkerFailures = 3
+
+// Worker - состояние фонового воркера: воркер отмечается через Beat после
+// каждой итерации, проверка падает, если отметок не было дольше MaxSilence
+// или MaxWorkerFailures итераций подряд завершились ошибкой.
+type Worker struct {
+	name       string
+	maxSilence time.Duration
+
+	mu       sync.Mutex
+	lastBeat time.Time
+	lastErr  error
+	failures int
+	stopped  bool
+}
+
+func NewWorker(name string, maxSilence time.Duration) *Worker {
+	return &Worker{
+		name:       name,
+		maxSilence: maxSilence,
+		lastBeat:   time.Now(),
+	}
+}
+
+func (w *Worker) Name() string {
+	return w.name
+}
+
+func (w *Worker) Beat(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastBeat = time.Now()
+	w.lastErr = err
+	if err == nil {
+		w.failures = 0
+		return
+	}
+	w.failures++
+}
+
+func (w *Worker) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+}
+
+func (w *Worker) Check(_ context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch {
+	case w.stopped:
+		return errors.New("worker stopped")
+	case w.failures >= MaxWorkerFailures:
+		return fmt.Errorf("%d iterations failed in a row: %w", w.failures, w.lastErr)
+	case time.Since(w.lastBeat) > w.maxSilence:
+		return errors.New("worker is not responding since " + w.lastBeat.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Detail - ошибка последней итерации, пока ошибок подряд меньше MaxWorkerFailures
+func (w *Worker) Detail() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.lastErr == nil {
+		return ""
+	}
+	return fmt.Sprintf("last iteration failed (%d in a row): %s", w.failures, w.lastErr)
+}
@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"context"
+
+	"backend/internal/tracing"
+
+	"go.uber.org/zap"
+)
+
+type loggerKey struct{}
+type requestIDKey struct{}
+
+// WithLogger кладет в контекст логгер запроса
+func WithLogger(ctx context.Context, log *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, log)
+}
+
+// FromContext возвращает логгер запроса, а вне запроса (воркеры, старт
+// приложения) - fallback с trace_id текущего спана, если он есть.
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if log, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return log
+	}
+	return fallback.With(tracing.LogFields(ctx)...)
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "hunt"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	HTTPRequests *prometheus.CounterVec
+	HTTPDuration *prometheus.HistogramVec
+
+	AdsCreated       prometheus.Counter
+	DealsCompleted   prometheus.Counter
+	ReviewsPosted    prometheus.Counter
+	PromotionsBought *prometheus.CounterVec
+
+	NotificationsSent *prometheus.CounterVec
+	WebhooksSent      *prometheus.CounterVec
+	CacheLookups      *prometheus.CounterVec
+}
+
+func NewMetrics() (*Metrics, error) {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		HTTPRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Количество HTTP-запросов по маршруту, методу и статусу.",
+		}, []string{"method", "route", "status"}),
+		HTTPDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Время обработки HTTP-запроса.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		AdsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ads_created_total",
+			Help:      "Количество созданных объявлений.",
+		}),
+		DealsCompleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "deals_completed_total",
+			Help:      "Количество завершенных сделок.",
+		}),
+		ReviewsPosted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reviews_posted_total",
+			Help:      "Количество оставленных отзывов.",
+		}),
+		PromotionsBought: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "promotions_bought_total",
+			Help:      "Количество купленных продвижений по типу.",
+		}, []string{"type"}),
+		NotificationsSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notifications_sent_total",
+			Help:      "Отправки уведомлений во внешние каналы по каналу и результату.",
+		}, []string{"channel", "result"}),
+		WebhooksSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhooks_sent_total",
+			Help:      "Попытки отправки вебхуков по результату: delivered, pending (будет повтор), failed.",
+		}, []string{"result"}),
+		CacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Обращения к кешу ответов по виду записи и результату: hit, miss, error.",
+		}, []string{"kind", "result"}),
+	}
+	if err := m.Register(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.HTTPRequests,
+		m.HTTPDuration,
+		m.AdsCreated,
+		m.DealsCompleted,
+		m.ReviewsPosted,
+		m.PromotionsBought,
+		m.NotificationsSent,
+		m.WebhooksSent,
+		m.CacheLookups,
+	); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Metrics) Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
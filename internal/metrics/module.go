@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"backend/internal/domain/repository/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/fx"
+)
+
+func New() fx.Option {
+	return fx.Module("metrics",
+		fx.Provide(
+			NewMetrics,
+		),
+		fx.Invoke(
+			func(m *Metrics, r *postgres.Repository) error {
+				return m.Register(NewPoolCollector(func() *pgxpool.Pool { return r.DB }))
+			},
+		),
+	)
+}
@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"backend/config"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// New отдает Dispatcher с каналами из ChannelGroup. Telegram подключается,
+// только если задан токен бота; другие каналы добавляются в группу своими модулями.
+func New() fx.Option {
+	return fx.Module("notify",
+		fx.Provide(
+			fx.Annotate(
+				NewDispatcher,
+				fx.ParamTags(ChannelGroup),
+			),
+			fx.Annotate(
+				func(log *zap.Logger, cfg *config.ConfigModel) []Channel {
+					if cfg.Telegram.BotToken == "" {
+						log.Warn("telegram bot token is not set, telegram notifications are disabled")
+						return nil
+					}
+					return []Channel{NewTelegramChannel(log, cfg.Telegram)}
+				},
+				fx.ResultTags(`group:"notify_channels,flatten"`),
+			),
+		),
+		fx.Decorate(func(log *zap.Logger) *zap.Logger {
+			return log.Named("notify")
+		}),
+	)
+}
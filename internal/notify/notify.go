@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+// ChannelGroup - тег fx-группы, в которую модули отдают каналы доставки
+const ChannelGroup = `group:"notify_channels"`
+
+// ErrUndeliverable - повтор не поможет: пользователь заблокировал бота,
+// канал не настроен и т.п.
+var ErrUndeliverable = errors.New("notification is undeliverable")
+
+type Message struct {
+	Title string
+	Body  string
+}
+
+// Channel - адаптер внешнего канала доставки. Входящие (inbox) пишутся в базу
+// всегда, каналы лишь дублируют туда уведомление.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, userID uint64, msg Message) error
+}
+
+// Dispatcher выбирает канал по имени
+type Dispatcher struct {
+	channels map[string]Channel
+	names    []string
+}
+
+func NewDispatcher(channels []Channel) *Dispatcher {
+	d := &Dispatcher{channels: make(map[string]Channel, len(channels))}
+	for _, ch := range channels {
+		d.channels[ch.Name()] = ch
+		d.names = append(d.names, ch.Name())
+	}
+	sort.Strings(d.names)
+	return d
+}
+
+// Channels - имена подключенных каналов
+func (d *Dispatcher) Channels() []string {
+	return d.names
+}
+
+func (d *Dispatcher) Send(ctx context.Context, channel string, userID uint64, msg Message) error {
+	ch, ok := d.channels[channel]
+	if !ok {
+		return ErrUndeliverable
+	}
+	return ch.Send(ctx, userID, msg)
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"backend/config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const ChannelTelegram = "telegram"
+
+// TelegramChannel отправляет уведомления личным сообщением от бота через
+// sendMessage. В Mini App id пользователя совпадает с chat_id личного чата.
+type TelegramChannel struct {
+	log     *zap.Logger
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+func NewTelegramChannel(log *zap.Logger, cfg config.TelegramConfig) *TelegramChannel {
+	return &TelegramChannel{
+		log:     log.Named("telegram"),
+		client:  &http.Client{Timeout: cfg.RequestTimeout},
+		baseURL: strings.TrimRight(cfg.APIBaseURL, "/"),
+		token:   cfg.BotToken,
+	}
+}
+
+func (c *TelegramChannel) Name() string {
+	return ChannelTelegram
+}
+
+type telegramMessage struct {
+	ChatID uint64 `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+func (c *TelegramChannel) Send(ctx context.Context, userID uint64, msg Message) error {
+	text := msg.Title
+	if msg.Body != "" {
+		text += "\n\n" + msg.Body
+	}
+	body, err := json.Marshal(telegramMessage{ChatID: userID, Text: text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/bot"+c.token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// в url.Error попадает адрес с токеном бота, в лог его не пускаем
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var res telegramResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("telegram: status %d: %w", resp.StatusCode, err)
+	}
+	if res.OK {
+		return nil
+	}
+	// 400 - чат не найден, 403 - пользователь не запускал или заблокировал бота
+	if res.ErrorCode == http.StatusBadRequest || res.ErrorCode == http.StatusForbidden {
+		return fmt.Errorf("telegram: %s: %w", res.Description, ErrUndeliverable)
+	}
+	return fmt.Errorf("telegram: %d %s", res.ErrorCode, res.Description)
+}
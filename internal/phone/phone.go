@@ -0,0 +1,59 @@
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalid = errors.New("invalid phone number")
+
+// По E.164 номер - не больше 15 цифр после "+"
+const (
+	minDigits = 8
+	maxDigits = 15
+	// национальный номер без кода страны обычно не длиннее 10 цифр
+	nationalMaxDigits = 10
+)
+
+// Normalize приводит номер к E.164: "+" и только цифры. Пробелы, дефисы,
+// точки и скобки отбрасываются. Номер без "+" (или "00") считается
+// национальным: префикс 8 или 0 заменяется на defaultCountryCode, а код
+// страны дописывается, если номер короче nationalMaxDigits+1 цифр.
+func Normalize(raw, defaultCountryCode string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	international := strings.HasPrefix(raw, "+")
+	if international {
+		raw = raw[1:]
+	} else if strings.HasPrefix(raw, "00") {
+		international = true
+		raw = raw[2:]
+	}
+
+	var sb strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		case r == ' ', r == '-', r == '.', r == '(', r == ')':
+		default:
+			return "", ErrInvalid
+		}
+	}
+	digits := sb.String()
+
+	if !international {
+		switch {
+		case defaultCountryCode == "" || digits == "":
+			return "", ErrInvalid
+		case digits[0] == '8' || digits[0] == '0':
+			digits = defaultCountryCode + digits[1:]
+		case strings.HasPrefix(digits, defaultCountryCode) && len(digits) > nationalMaxDigits:
+		default:
+			digits = defaultCountryCode + digits
+		}
+	}
+	if len(digits) < minDigits || len(digits) > maxDigits || digits[0] == '0' {
+		return "", ErrInvalid
+	}
+	return "+" + digits, nil
+}
@@ -0,0 +1,49 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		country string
+		want    string
+		wantErr bool
+	}{
+		{name: "international with formatting", raw: "+7 (912) 345-67-89", country: "7", want: "+79123456789"},
+		{name: "dots and spaces around", raw: "  +7.912.345.67.89  ", country: "7", want: "+79123456789"},
+		{name: "national with 8", raw: "8 912 345 67 89", country: "7", want: "+79123456789"},
+		{name: "national with 0", raw: "020 7946 0958", country: "44", want: "+442079460958"},
+		{name: "national without prefix", raw: "9123456789", country: "7", want: "+79123456789"},
+		{name: "country code without plus", raw: "79123456789", country: "7", want: "+79123456789"},
+		{name: "00 international prefix", raw: "0044 20 7946 0958", country: "7", want: "+442079460958"},
+		{name: "international ignores default country", raw: "+44 20 7946 0958", country: "7", want: "+442079460958"},
+		{name: "letters", raw: "+7 912 ABC 67 89", country: "7", wantErr: true},
+		{name: "empty", raw: "", country: "7", wantErr: true},
+		{name: "plus only", raw: "+", country: "7", wantErr: true},
+		{name: "national without default country", raw: "9123456789", country: "", wantErr: true},
+		{name: "too short", raw: "+1234567", country: "7", wantErr: true},
+		{name: "too long", raw: "+1234567890123456", country: "7", wantErr: true},
+		{name: "country code starts with 0", raw: "+0123456789", country: "7", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.raw, tt.country)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Fatalf("Normalize(%q) = %q, %v, want ErrInvalid", tt.raw, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize(%q): unexpected error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// clock - часы MemoryStore, которые идут только по advance
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// storeWithClock: последняя чистка - в момент старта часов, как у NewMemoryStore
+func storeWithClock(c *clock) *MemoryStore {
+	m := NewMemoryStore()
+	m.now = func() time.Time { return c.now }
+	m.lastSweep = c.now
+	return m
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	limit := Limit{Rate: 1, Burst: 2}
+	steps := []struct {
+		name    string
+		advance time.Duration
+		key     string
+		want    Result
+	}{
+		{"first request spends burst", 0, "a", Result{Allowed: true, Remaining: 1}},
+		{"second request empties bucket", 0, "a", Result{Allowed: true, Remaining: 0}},
+		{"third request is limited", 0, "a", Result{RetryAfter: time.Second}},
+		{"other key has own bucket", 0, "b", Result{Allowed: true, Remaining: 1}},
+		{"partial refill is not enough", 500 * time.Millisecond, "a", Result{RetryAfter: 500 * time.Millisecond}},
+		{"refilled token", 500 * time.Millisecond, "a", Result{Allowed: true, Remaining: 0}},
+		{"long pause refills up to burst", time.Hour, "a", Result{Allowed: true, Remaining: 1}},
+	}
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := storeWithClock(c)
+	for _, st := range steps {
+		c.advance(st.advance)
+		got, err := m.Take(context.Background(), st.key, limit)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", st.name, err)
+		}
+		if got != st.want {
+			t.Errorf("%s: result = %+v, want %+v", st.name, got, st.want)
+		}
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	limit := Limit{Rate: 1, Burst: 10} // ведро заполняется за 10 секунд
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := storeWithClock(c)
+	ctx := context.Background()
+	if _, err := m.Take(ctx, "old", limit); err != nil {
+		t.Fatal(err)
+	}
+	c.advance(sweepInterval - time.Second)
+	if _, err := m.Take(ctx, "fresh", limit); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.buckets) != 2 {
+		t.Fatalf("buckets before sweep = %d, want 2", len(m.buckets))
+	}
+	// следующий запрос после sweepInterval чистит ведра, которые уже заполнились
+	c.advance(2 * time.Second)
+	if _, err := m.Take(ctx, "fresh", limit); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.buckets["old"]; ok {
+		t.Error("expired bucket was not swept")
+	}
+	if _, ok := m.buckets["fresh"]; !ok {
+		t.Error("live bucket was swept")
+	}
+}
@@ -0,0 +1,18 @@
+package ratelimit
+
+import (
+	"go.uber.org/fx"
+)
+
+// New отдает MemoryStore. Для нескольких инстансов Store подменяется через
+// fx.Decorate на NewRedisStore с клиентом общего Redis.
+func New() fx.Option {
+	return fx.Module("ratelimit",
+		fx.Provide(
+			fx.Annotate(
+				NewMemoryStore,
+				fx.As(new(Store)),
+			),
+		),
+	)
+}
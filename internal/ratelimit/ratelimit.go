@@ -0,0 +1,41 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit - параметры token bucket: Rate токенов в секунду, не больше Burst
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// take - один шаг token bucket: пополняет tokens за elapsed и пытается списать токен
+func take(tokens float64, elapsed time.Duration, limit Limit) (float64, Result) {
+	burst := float64(limit.Burst)
+	tokens = math.Min(burst, tokens+elapsed.Seconds()*limit.Rate)
+	if tokens >= 1 {
+		tokens--
+		return tokens, Result{Allowed: true, Remaining: int(tokens)}
+	}
+	wait := time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+	return tokens, Result{Allowed: false, RetryAfter: wait}
+}
+
+// ttl - время, за которое пустое ведро заполнится целиком; после него
+// состояние ключа можно забыть
+func (l Limit) ttl() time.Duration {
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
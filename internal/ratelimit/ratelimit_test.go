@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTake(t *testing.T) {
+	limit := Limit{Rate: 2, Burst: 5}
+	tests := []struct {
+		name       string
+		tokens     float64
+		elapsed    time.Duration
+		wantTokens float64
+		want       Result
+	}{
+		{"full bucket", 5, 0, 4, Result{Allowed: true, Remaining: 4}},
+		{"refill is capped by burst", 5, time.Hour, 4, Result{Allowed: true, Remaining: 4}},
+		{"last token", 1, 0, 0, Result{Allowed: true, Remaining: 0}},
+		{"refilled one token", 0, 500 * time.Millisecond, 0, Result{Allowed: true, Remaining: 0}},
+		{"empty bucket", 0, 0, 0, Result{RetryAfter: 500 * time.Millisecond}},
+		{"half token", 0, 250 * time.Millisecond, 0.5, Result{RetryAfter: 250 * time.Millisecond}},
+		{"remaining rounds down", 3, 400 * time.Millisecond, 2.8, Result{Allowed: true, Remaining: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, got := take(tt.tokens, tt.elapsed, limit)
+			if diff := tokens - tt.wantTokens; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("tokens = %v, want %v", tokens, tt.wantTokens)
+			}
+			if got != tt.want {
+				t.Errorf("result = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitTTL(t *testing.T) {
+	tests := []struct {
+		limit Limit
+		want  time.Duration
+	}{
+		{Limit{Rate: 1, Burst: 10}, 10 * time.Second},
+		{Limit{Rate: 4, Burst: 2}, 500 * time.Millisecond},
+		{Limit{Rate: 0.5, Burst: 3}, 6 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := tt.limit.ttl(); got != tt.want {
+			t.Errorf("%+v.ttl() = %v, want %v", tt.limit, got, tt.want)
+		}
+	}
+}
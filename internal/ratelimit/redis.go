@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RedisClient - минимум, который нужен RedisStore. Подходит обертка над
+// любым клиентом Redis-протокола (Redis, KeyDB, Dragonfly), например
+// func(ctx, script, keys, args...) { return rdb.Eval(ctx, script, keys, args...).Result() }.
+type RedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// Состояние ведра хранится в hash {tokens, ts}; время берется из Redis,
+// чтобы инстансы с разными часами считали одинаково.
+const takeScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1]) or burst
+local ts = tonumber(data[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+`
+
+type RedisStore struct {
+	client RedisClient
+	prefix string
+}
+
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (r *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	ttl := limit.ttl().Milliseconds() + 1
+	raw, err := r.client.Eval(ctx, takeScript, []string{r.prefix + key}, limit.Rate, limit.Burst, ttl)
+	if err != nil {
+		return Result{}, err
+	}
+	reply, ok := raw.([]interface{})
+	if !ok || len(reply) != 2 {
+		return Result{}, fmt.Errorf("ratelimit: unexpected redis reply %v", raw)
+	}
+	allowed, _ := reply[0].(int64)
+	tokensStr, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit: unexpected tokens value %v", reply[1])
+	}
+	if allowed == 1 {
+		return Result{Allowed: true, Remaining: int(tokens)}, nil
+	}
+	return Result{
+		Allowed:    false,
+		RetryAfter: time.Duration((1 - tokens) / limit.Rate * float64(time.Second)),
+	}, nil
+}
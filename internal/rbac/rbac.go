@@ -0,0 +1,79 @@
+package rbac
+
+// Role совпадает с user_roles.name
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+type Permission string
+
+const (
+	PermHideAdvertisment     Permission = "advertisment:hide"
+	PermHideReview           Permission = "review:hide"
+	PermModerateAdvertisment Permission = "advertisment:moderate"
+	PermTriageReports        Permission = "report:triage"
+	PermReviewVerification   Permission = "verification:review"
+	PermManageCategories     Permission = "category:manage"
+	PermManagePromotionTypes Permission = "promotion_type:manage"
+	PermManageCities         Permission = "city:manage"
+)
+
+// Все права ролей объявляются здесь. Каждая следующая роль наследует права
+// предыдущей: user < moderator < admin.
+var (
+	roleOrder = []Role{RoleUser, RoleModerator, RoleAdmin}
+
+	ownPermissions = map[Role][]Permission{
+		RoleUser: {},
+		RoleModerator: {
+			PermHideAdvertisment,
+			PermHideReview,
+			PermModerateAdvertisment,
+			PermTriageReports,
+			PermReviewVerification,
+		},
+		RoleAdmin: {
+			PermManageCategories,
+			PermManagePromotionTypes,
+			PermManageCities,
+		},
+	}
+
+	permissions = build()
+)
+
+func build() map[Role]map[Permission]struct{} {
+	res := make(map[Role]map[Permission]struct{}, len(roleOrder))
+	inherited := map[Permission]struct{}{}
+	for _, role := range roleOrder {
+		set := make(map[Permission]struct{}, len(inherited)+len(ownPermissions[role]))
+		for p := range inherited {
+			set[p] = struct{}{}
+		}
+		for _, p := range ownPermissions[role] {
+			set[p] = struct{}{}
+		}
+		res[role] = set
+		inherited = set
+	}
+	return res
+}
+
+// Can: есть ли у роли право. Неизвестная роль прав не имеет.
+func Can(role Role, p Permission) bool {
+	_, ok := permissions[role][p]
+	return ok
+}
+
+// Permissions возвращает все права роли, включая унаследованные
+func Permissions(role Role) []Permission {
+	res := make([]Permission, 0, len(permissions[role]))
+	for p := range permissions[role] {
+		res = append(res, p)
+	}
+	return res
+}
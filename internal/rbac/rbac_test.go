@@ -0,0 +1,60 @@
+package rbac
+
+import "testing"
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		name string
+		role Role
+		perm Permission
+		want bool
+	}{
+		{"user has no moderation rights", RoleUser, PermHideAdvertisment, false},
+		{"moderator own permission", RoleModerator, PermTriageReports, true},
+		{"moderator has no admin rights", RoleModerator, PermManageCategories, false},
+		{"admin own permission", RoleAdmin, PermManageCities, true},
+		{"admin inherits moderator permission", RoleAdmin, PermHideReview, true},
+		{"admin inherits every moderator permission", RoleAdmin, PermReviewVerification, true},
+		{"unknown role", Role("guest"), PermHideAdvertisment, false},
+		{"empty role", Role(""), PermManageCities, false},
+		{"unknown permission", RoleAdmin, Permission("anything"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Can(tt.role, tt.perm); got != tt.want {
+				t.Errorf("Can(%q, %q) = %v, want %v", tt.role, tt.perm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissionsInherited(t *testing.T) {
+	// каждая следующая роль в roleOrder содержит все права предыдущей
+	for i := 1; i < len(roleOrder); i++ {
+		prev, role := roleOrder[i-1], roleOrder[i]
+		for _, p := range Permissions(prev) {
+			if !Can(role, p) {
+				t.Errorf("%s does not inherit %q from %s", role, p, prev)
+			}
+		}
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	tests := []struct {
+		role Role
+		want int
+	}{
+		{RoleUser, 0},
+		{RoleModerator, 5},
+		{RoleAdmin, 8},
+		{Role("guest"), 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			if got := len(Permissions(tt.role)); got != tt.want {
+				t.Errorf("len(Permissions(%q)) = %d, want %d", tt.role, got, tt.want)
+			}
+		})
+	}
+}
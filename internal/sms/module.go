@@ -0,0 +1,38 @@
+package sms
+
+import (
+	"backend/config"
+	"errors"
+	"fmt"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// New отдает Sender по SMS.Provider. Без провайдера сервис не стартует:
+// иначе коды подтверждения молча никуда не уходят. LogSender пишет коды в
+// лог, поэтому запускается только с Server.dev.
+func New() fx.Option {
+	return fx.Module("sms",
+		fx.Provide(newSender),
+		fx.Decorate(func(log *zap.Logger) *zap.Logger {
+			return log.Named("sms")
+		}),
+	)
+}
+
+func newSender(cfg *config.ConfigModel, log *zap.Logger) (Sender, error) {
+	switch cfg.SMS.Provider {
+	case ProviderSMSRu:
+		return NewSMSRuSender(cfg.SMS), nil
+	case ProviderLog:
+		if !cfg.Server.Dev {
+			return nil, errors.New("sms provider log writes codes to the log and needs Server.dev")
+		}
+		log.Warn("sms are not sent, codes are written to the log: use only for local development")
+		return NewLogSender(log), nil
+	case "":
+		return nil, errors.New("sms provider is not configured (SMS.provider)")
+	}
+	return nil, fmt.Errorf("unknown sms provider %q", cfg.SMS.Provider)
+}
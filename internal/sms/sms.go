@@ -0,0 +1,34 @@
+package sms
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+const (
+	ProviderSMSRu = "smsru"
+	// ProviderLog - LogSender, только для локальной разработки
+	ProviderLog = "log"
+)
+
+// Sender отправляет SMS на номер в формате E.164
+type Sender interface {
+	Send(ctx context.Context, phone, text string) error
+}
+
+// LogSender ничего не отправляет, а пишет SMS в лог вместе с кодом: при
+// локальном запуске номер подтверждается кодом из лога. Код из лога позволил
+// бы подтвердить чужой номер, поэтому newSender создает его только с Server.dev.
+type LogSender struct {
+	log *zap.Logger
+}
+
+func NewLogSender(log *zap.Logger) *LogSender {
+	return &LogSender{log: log}
+}
+
+func (s *LogSender) Send(ctx context.Context, phone, text string) error {
+	s.log.Info("sms message", zap.String("phone", phone), zap.String("text", text))
+	return nil
+}
@@ -0,0 +1,137 @@
+package sms
+
+import (
+	"backend/config"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSMSRuSend(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		response string
+		wantErr  string
+	}{
+		{
+			name:     "sent",
+			response: `{"status":"OK","status_code":100,"sms":{"79001234567":{"status":"OK","status_code":100}}}`,
+		},
+		{
+			name:     "sender name",
+			from:     "hunt",
+			response: `{"status":"OK","status_code":100,"sms":{"79001234567":{"status":"OK","status_code":100}}}`,
+		},
+		{
+			name:     "request rejected",
+			response: `{"status":"ERROR","status_code":200,"status_text":"Неправильный api_id"}`,
+			wantErr:  "smsru: 200",
+		},
+		{
+			name:     "message rejected",
+			response: `{"status":"OK","status_code":100,"sms":{"79001234567":{"status":"ERROR","status_code":207,"status_text":"На этот номер нельзя отправлять сообщения"}}}`,
+			wantErr:  "smsru: 207",
+		},
+		{
+			name:     "no message status",
+			response: `{"status":"OK","status_code":100}`,
+			wantErr:  "no message status",
+		},
+		{
+			name:     "not json",
+			response: `502 Bad Gateway`,
+			wantErr:  "smsru: status 200",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// ключ и текст только в теле: адрес запроса попадает в ошибки клиента
+				if r.Method != http.MethodPost || r.URL.Path != "/sms/send" || r.URL.RawQuery != "" {
+					t.Errorf("request %s %s", r.Method, r.URL)
+				}
+				if err := r.ParseForm(); err != nil {
+					t.Fatal(err)
+				}
+				want := map[string]string{"api_id": "key", "to": "79001234567", "msg": "Код: 123456", "json": "1", "from": tt.from}
+				for k, v := range want {
+					if got := r.PostForm.Get(k); got != v {
+						t.Errorf("%s = %q, want %q", k, got, v)
+					}
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			s := NewSMSRuSender(config.SMSConfig{APIID: "key", From: tt.from, APIBaseURL: srv.URL + "/", RequestTimeout: time.Second})
+			err := s.Send(context.Background(), "+79001234567", "Код: 123456")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Send() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Send() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLogSender(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	if err := NewLogSender(zap.New(core)).Send(context.Background(), "+79001234567", "Код: 123456"); err != nil {
+		t.Fatal(err)
+	}
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d log entries, want 1", len(entries))
+	}
+	// без кода в логе номер нельзя подтвердить локально
+	if got := entries[0].ContextMap()["text"]; got != "Код: 123456" {
+		t.Errorf("logged text = %v, want the code", got)
+	}
+}
+
+func TestNewSender(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		dev      bool
+		want     string
+	}{
+		{name: "smsru", provider: ProviderSMSRu, want: "*sms.SMSRuSender"},
+		{name: "log in dev", provider: ProviderLog, dev: true, want: "*sms.LogSender"},
+		{name: "log without dev", provider: ProviderLog},
+		{name: "not configured", dev: true},
+		{name: "unknown", provider: "twilio", dev: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.ConfigModel{}
+			cfg.Server.Dev = tt.dev
+			cfg.SMS = config.SMSConfig{Provider: tt.provider, APIID: "key", APIBaseURL: "https://sms.ru", RequestTimeout: time.Second}
+			s, err := newSender(cfg, zap.NewNop())
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("newSender() = %T, want error", s)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newSender() error = %v", err)
+			}
+			if got := fmt.Sprintf("%T", s); got != tt.want {
+				t.Errorf("newSender() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
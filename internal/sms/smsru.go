@@ -0,0 +1,82 @@
+package sms
+
+import (
+	"backend/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SMSRuSender отправляет SMS через sms.ru (https://sms.ru/api/send)
+type SMSRuSender struct {
+	client  *http.Client
+	baseURL string
+	apiID   string
+	from    string
+}
+
+func NewSMSRuSender(cfg config.SMSConfig) *SMSRuSender {
+	return &SMSRuSender{
+		client:  &http.Client{Timeout: cfg.RequestTimeout},
+		baseURL: strings.TrimRight(cfg.APIBaseURL, "/"),
+		apiID:   cfg.APIID,
+		from:    cfg.From,
+	}
+}
+
+// smsruStatus - статус запроса целиком и каждого сообщения в нем
+type smsruStatus struct {
+	Status     string `json:"status"`
+	StatusCode int    `json:"status_code"`
+	StatusText string `json:"status_text"`
+}
+
+type smsruResponse struct {
+	smsruStatus
+	SMS map[string]smsruStatus `json:"sms"`
+}
+
+// Send передает ключ и текст в теле запроса: в ошибки и логи с адресом они не попадут
+func (s *SMSRuSender) Send(ctx context.Context, phone, text string) error {
+	form := url.Values{
+		"api_id": {s.apiID},
+		"to":     {strings.TrimPrefix(phone, "+")},
+		"msg":    {text},
+		"json":   {"1"},
+	}
+	if s.from != "" {
+		form.Set("from", s.from)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/sms/send", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("smsru: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var res smsruResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("smsru: status %d: %w", resp.StatusCode, err)
+	}
+	if res.Status != "OK" {
+		return fmt.Errorf("smsru: %d %s", res.StatusCode, res.StatusText)
+	}
+	// запрос принят, но сообщение на номер могло быть отклонено
+	for _, msg := range res.SMS {
+		if msg.Status != "OK" {
+			return fmt.Errorf("smsru: %d %s", msg.StatusCode, msg.StatusText)
+		}
+	}
+	if len(res.SMS) == 0 {
+		return errors.New("smsru: no message status in response")
+	}
+	return nil
+}
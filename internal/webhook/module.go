@@ -0,0 +1,11 @@
+package webhook
+
+import (
+	"go.uber.org/fx"
+)
+
+func New() fx.Option {
+	return fx.Module("webhook",
+		fx.Provide(NewClient),
+	)
+}
@@ -0,0 +1,137 @@
+package webhook
+
+import (
+	"backend/config"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Заголовки запроса. Получатель проверяет подпись:
+// hex(HMAC-SHA256(secret, "<timestamp>.<body>")) == SignatureHeader без префикса "sha256="
+const (
+	SignatureHeader = "X-Hunt-Signature"
+	TimestampHeader = "X-Hunt-Timestamp"
+	EventHeader     = "X-Hunt-Event"
+	DeliveryHeader  = "X-Hunt-Delivery"
+)
+
+var (
+	ErrInvalidURL = errors.New("invalid webhook url")
+	// ErrForbiddenAddress - адрес получателя во внутренней сети
+	ErrForbiddenAddress = errors.New("webhook address is not allowed")
+)
+
+// Request - одна отправка события
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID uint64
+	Payload    []byte
+}
+
+// Sign считает подпись тела для момента timestamp (unix-секунды).
+// Время входит в подпись, чтобы перехваченный запрос нельзя было повторить позже.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type Client struct {
+	client        *http.Client
+	allowInsecure bool
+}
+
+func NewClient(cfg *config.ConfigModel) *Client {
+	c := &Client{allowInsecure: cfg.Webhook.AllowInsecure}
+	dialer := &net.Dialer{
+		Timeout: cfg.Webhook.RequestTimeout,
+		// проверяется уже разрешенный адрес, поэтому DNS не обходит запрет
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !c.allowedIP(ip) {
+				return ErrForbiddenAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	c.client = &http.Client{
+		Timeout:   cfg.Webhook.RequestTimeout,
+		Transport: transport,
+		// редирект считается ответом получателя, а не поводом идти по другому адресу
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return c
+}
+
+func (c *Client) allowedIP(ip net.IP) bool {
+	if c.allowInsecure {
+		return true
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast())
+}
+
+// ValidateURL: только https с хостом, без учетных данных в адресе
+func (c *Client) ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+	if u.Scheme != "https" && !(c.allowInsecure && u.Scheme == "http") {
+		return ErrInvalidURL
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !c.allowedIP(ip) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+// Send отправляет событие и возвращает HTTP-статус ответа. Успех - только 2xx.
+func (c *Client) Send(ctx context.Context, r Request) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "hunt-webhooks/1.0")
+	req.Header.Set(SignatureHeader, Sign(r.Secret, timestamp, r.Payload))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(EventHeader, r.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(r.DeliveryID, 10))
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// тело не нужно, но дочитываем, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook: unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -100,6 +100,341 @@ $$
             CONSTRAINT fk_advertisement_id FOREIGN KEY (advertisement_id) REFERENCES advertisements (id) ON DELETE CASCADE, -- Связь с таблицей объявлений
             CONSTRAINT fk_buyer_id FOREIGN KEY (buyer_id) REFERENCES users (id) ON DELETE CASCADE                           -- Связь с таблицей пользователей (покупателей)
         );
+
+-- Роли пользователей (права ролей объявлены в internal/rbac)
+        INSERT INTO user_roles (name)
+        VALUES ('user'), ('moderator'), ('admin')
+        ON CONFLICT (name) DO NOTHING;
+
+-- Скрытие объявлений и отзывов модератором
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS is_hidden boolean NOT NULL DEFAULT false; -- Скрыто модератором
+        ALTER TABLE reviews ADD COLUMN IF NOT EXISTS is_hidden boolean NOT NULL DEFAULT false;        -- Скрыто модератором
+
+-- Активность категорий и типов продвижения (неактивные не показываются клиенту)
+        ALTER TABLE categories_product ADD COLUMN IF NOT EXISTS is_active boolean NOT NULL DEFAULT true; -- Показывать в списке категорий
+        ALTER TABLE types_promotion ADD COLUMN IF NOT EXISTS is_active boolean NOT NULL DEFAULT true;    -- Доступно для покупки
+
+-- Дерево категорий
+        ALTER TABLE categories_product ADD COLUMN IF NOT EXISTS parent_id int REFERENCES categories_product (id); -- Родительская категория, NULL для корневой
+
+-- Создаем таблицу атрибутов категорий (наследуются дочерними категориями)
+        CREATE TABLE IF NOT EXISTS category_attributes
+        (
+            id          serial PRIMARY KEY,
+            category_id int         NOT NULL,                                                             -- Категория, в которой объявлен атрибут
+            code        varchar(50) NOT NULL,                                                             -- Код атрибута для API и фильтров (size, brand, mileage)
+            name        varchar(50) NOT NULL,                                                             -- Название для клиента
+            value_type  varchar(10) NOT NULL CHECK (value_type IN ('string', 'int', 'float', 'bool', 'enum')), -- Тип значения
+            is_required boolean     NOT NULL DEFAULT false,                                               -- Обязателен при создании объявления
+            options     text[]      NOT NULL DEFAULT '{}',                                                -- Допустимые значения для enum
+            CONSTRAINT uq_category_attribute_code UNIQUE (category_id, code),
+            CONSTRAINT fk_category_id FOREIGN KEY (category_id) REFERENCES categories_product (id) ON DELETE CASCADE
+        );
+
+-- Создаем таблицу значений атрибутов объявлений
+        CREATE TABLE IF NOT EXISTS advertisement_attributes
+        (
+            advertisement_id int  NOT NULL, -- Объявление
+            attribute_id     int  NOT NULL, -- Атрибут категории
+            value            text NOT NULL, -- Значение в текстовом виде
+            value_num        numeric,       -- Значение для int/float, используется в фильтрах по диапазону
+            PRIMARY KEY (advertisement_id, attribute_id),
+            CONSTRAINT fk_advertisement_id FOREIGN KEY (advertisement_id) REFERENCES advertisements (id) ON DELETE CASCADE,
+            CONSTRAINT fk_attribute_id FOREIGN KEY (attribute_id) REFERENCES category_attributes (id) ON DELETE CASCADE
+        );
+        CREATE INDEX IF NOT EXISTS idx_advertisement_attributes_value ON advertisement_attributes (attribute_id, value);
+        CREATE INDEX IF NOT EXISTS idx_advertisement_attributes_value_num ON advertisement_attributes (attribute_id, value_num);
+
+-- Модерация объявлений: новые и измененные объявления попадают в очередь
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS moderation_status varchar(20) NOT NULL DEFAULT 'approved'  -- pending, approved, rejected
+            CHECK (moderation_status IN ('pending', 'approved', 'rejected'));
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS moderation_reason varchar(255);                             -- Причина отклонения
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS moderation_flags text[] NOT NULL DEFAULT '{}';              -- Флаги автоматической проверки
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS moderated_by int REFERENCES users (id);                     -- Модератор, принявший решение
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS moderated_at timestamp;                                     -- Время решения
+        CREATE INDEX IF NOT EXISTS idx_advertisements_moderation_queue ON advertisements (date_placement) WHERE moderation_status = 'pending';
+
+-- Жалобы пользователей на объявления, отзывы и пользователей
+        CREATE TABLE IF NOT EXISTS reports
+        (
+            id          serial PRIMARY KEY,
+            reporter_id int          NOT NULL REFERENCES users (id),                                   -- Кто пожаловался
+            target_type varchar(20)  NOT NULL CHECK (target_type IN ('advertisment', 'review', 'user')), -- На что жалоба
+            target_id   int          NOT NULL,                                                         -- id объявления, отзыва или пользователя
+            reason      varchar(20)  NOT NULL,                                                         -- Код причины
+            comment     varchar(500),                                                                  -- Комментарий пользователя
+            status      varchar(20)  NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+            created_at  timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            resolved_by int REFERENCES users (id),                                                     -- Модератор, разобравший жалобу
+            resolved_at timestamp
+        );
+        -- Один пользователь - одна открытая жалоба на объект
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_open_unique ON reports (reporter_id, target_type, target_id) WHERE status = 'open';
+        CREATE INDEX IF NOT EXISTS idx_reports_target ON reports (target_type, target_id) WHERE status = 'open';
+
+-- Верификация продавцов: unverified -> pending -> verified / rejected
+        IF NOT EXISTS (SELECT FROM pg_constraint WHERE conname = 'chk_users_verification_status') THEN
+            ALTER TABLE users ADD CONSTRAINT chk_users_verification_status
+                CHECK (verification_status IN ('unverified', 'pending', 'verified', 'rejected'));
+        END IF;
+        CREATE TABLE IF NOT EXISTS verification_requests
+        (
+            id            serial PRIMARY KEY,
+            user_id       int         NOT NULL REFERENCES users (id),
+            method        varchar(20) NOT NULL CHECK (method IN ('document', 'phone')),                 -- Чем подтверждается продавец
+            document_path text,                                                                          -- Путь к скану документа
+            status        varchar(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'verified', 'rejected')),
+            reason        varchar(255),                                                                  -- Причина отказа
+            created_at    timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            reviewed_by   int REFERENCES users (id),                                                     -- Модератор
+            reviewed_at   timestamp
+        );
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_verification_requests_pending ON verification_requests (user_id) WHERE status = 'pending';
+        ALTER TABLE types_promotion ADD COLUMN IF NOT EXISTS verified_only boolean NOT NULL DEFAULT false; -- Только для верифицированных продавцов
+
+-- Подтверждение телефона одноразовыми кодами. Номер хранится в E.164 (до 16 символов)
+        ALTER TABLE users ALTER COLUMN number_phone TYPE varchar(16);
+        ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_confirmed_at timestamp;                       -- Когда номер подтвержден кодом
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_users_number_phone ON users (number_phone) WHERE number_phone IS NOT NULL;
+        CREATE TABLE IF NOT EXISTS phone_codes
+        (
+            id          serial PRIMARY KEY,
+            user_id     int         NOT NULL REFERENCES users (id),
+            phone       varchar(16) NOT NULL,                          -- Номер в E.164
+            code_hash   bytea       NOT NULL,                          -- HMAC-SHA256 кода
+            attempts    int         NOT NULL DEFAULT 0,                -- Сколько раз вводили код
+            created_at  timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            expires_at  timestamp   NOT NULL,
+            consumed_at timestamp                                      -- Код использован или заменен новым
+        );
+        CREATE INDEX IF NOT EXISTS idx_phone_codes_user ON phone_codes (user_id, created_at DESC);
+
+-- Архив и мягкое удаление объявлений: запись остается для сделок, отзывов и статистики
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'active' -- active, archived, deleted
+            CHECK (status IN ('active', 'archived', 'deleted'));
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS status_changed_at timestamp;                   -- Когда объявление архивировали, удалили или восстановили
+        -- Физическое удаление объявления со сделками запрещено, чтобы не потерять историю покупок
+        ALTER TABLE deals DROP CONSTRAINT IF EXISTS fk_advertisement_id;
+        ALTER TABLE deals ADD CONSTRAINT fk_advertisement_id FOREIGN KEY (advertisement_id) REFERENCES advertisements (id) ON DELETE RESTRICT;
+
+-- Черновики и отложенная публикация
+        ALTER TABLE advertisements DROP CONSTRAINT IF EXISTS advertisements_status_check;
+        ALTER TABLE advertisements ADD CONSTRAINT advertisements_status_check
+            CHECK (status IN ('draft', 'scheduled', 'active', 'archived', 'deleted'));
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS publish_at timestamp;                         -- Когда опубликовать объявление в статусе scheduled
+        CREATE INDEX IF NOT EXISTS idx_advertisements_publish_at ON advertisements (publish_at) WHERE status = 'scheduled';
+
+-- Справочник городов и координаты объявлений для поиска по расстоянию
+        CREATE TABLE IF NOT EXISTS cities
+        (
+            id        serial PRIMARY KEY,
+            name      varchar(100)     NOT NULL,                                    -- Название города
+            region    varchar(100)     NOT NULL DEFAULT '',                         -- Регион, различает одноименные города
+            latitude  double precision NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+            longitude double precision NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+            CONSTRAINT uq_city_name_region UNIQUE (name, region)
+        );
+        CREATE INDEX IF NOT EXISTS idx_cities_name ON cities (lower(name) varchar_pattern_ops);
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS city_id int REFERENCES cities (id);                         -- Город объявления
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS latitude double precision CHECK (latitude BETWEEN -90 AND 90); -- Точка на карте, если продавец ее указал
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS longitude double precision CHECK (longitude BETWEEN -180 AND 180);
+        CREATE INDEX IF NOT EXISTS idx_advertisements_latitude ON advertisements (latitude) WHERE latitude IS NOT NULL;
+        CREATE INDEX IF NOT EXISTS idx_advertisements_city ON advertisements (city_id);
+
+-- Уведомления пользователей. dedup_key не дает отправить одно и то же событие дважды
+        CREATE TABLE IF NOT EXISTS notifications
+        (
+            id         serial PRIMARY KEY,
+            user_id    int          NOT NULL REFERENCES users (id),
+            kind       varchar(50)  NOT NULL,                          -- Тип события
+            title      varchar(255) NOT NULL,
+            body       text         NOT NULL DEFAULT '',
+            payload    jsonb        NOT NULL DEFAULT '{}',             -- Данные для клиента: id объявлений и т.п.
+            dedup_key  varchar(255) NOT NULL,
+            created_at timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            CONSTRAINT uq_notifications_dedup UNIQUE (user_id, dedup_key)
+        );
+        CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications (user_id, created_at DESC);
+
+-- Сохраненные поиски и найденные по ним объявления
+        CREATE TABLE IF NOT EXISTS saved_searches
+        (
+            id             serial PRIMARY KEY,
+            user_id        int          NOT NULL REFERENCES users (id),
+            name           varchar(100) NOT NULL,
+            filter         jsonb        NOT NULL,                                                       -- Фильтр поиска
+            category_id    int REFERENCES categories_product (id) ON DELETE CASCADE,                    -- Копии полей фильтра для быстрого отбора кандидатов
+            price_min      numeric(10, 2),
+            price_max      numeric(10, 2),
+            notify         varchar(20)  NOT NULL DEFAULT 'instant' CHECK (notify IN ('instant', 'daily')), -- Сразу или дайджестом раз в сутки
+            created_at     timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            last_digest_at timestamp                                                                     -- Когда отправлен последний дайджест
+        );
+        CREATE INDEX IF NOT EXISTS idx_saved_searches_user ON saved_searches (user_id);
+        CREATE INDEX IF NOT EXISTS idx_saved_searches_category ON saved_searches (category_id);
+        CREATE TABLE IF NOT EXISTS saved_search_matches
+        (
+            saved_search_id  int       NOT NULL REFERENCES saved_searches (id) ON DELETE CASCADE,
+            advertisement_id int       NOT NULL REFERENCES advertisements (id),
+            created_at       timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            notified_at      timestamp,                                 -- Уведомление отправлено сразу или в дайджесте
+            PRIMARY KEY (saved_search_id, advertisement_id)
+        );
+        CREATE INDEX IF NOT EXISTS idx_saved_search_matches_pending ON saved_search_matches (saved_search_id) WHERE notified_at IS NULL;
+
+-- Входящие уведомления и доставка во внешние каналы
+        ALTER TABLE notifications ADD COLUMN IF NOT EXISTS read_at timestamp;                          -- Когда пользователь прочитал уведомление
+        CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+        -- Нет строки - уведомления типа kind в канал channel включены
+        CREATE TABLE IF NOT EXISTS notification_preferences
+        (
+            user_id int         NOT NULL REFERENCES users (id),
+            kind    varchar(50) NOT NULL,                      -- Тип события
+            channel varchar(20) NOT NULL,                      -- Канал доставки: telegram, ...
+            enabled boolean     NOT NULL,
+            PRIMARY KEY (user_id, kind, channel)
+        );
+        CREATE TABLE IF NOT EXISTS notification_deliveries
+        (
+            id              serial PRIMARY KEY,
+            notification_id int         NOT NULL REFERENCES notifications (id) ON DELETE CASCADE,
+            channel         varchar(20) NOT NULL,
+            status          varchar(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+            attempts        int         NOT NULL DEFAULT 0,
+            next_attempt_at timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Не раньше этого времени отправка берется в работу
+            last_error      text,
+            sent_at         timestamp,
+            CONSTRAINT uq_notification_deliveries UNIQUE (notification_id, channel)
+        );
+        CREATE INDEX IF NOT EXISTS idx_notification_deliveries_pending ON notification_deliveries (next_attempt_at) WHERE status = 'pending';
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS promotion_expiry_notified_for timestamp; -- Срок продвижения, о котором владельца уже предупредили
+        CREATE INDEX IF NOT EXISTS idx_advertisements_promotion_expire ON advertisements (date_expire_promotion) WHERE date_expire_promotion IS NOT NULL;
+
+-- Вебхуки партнеров: подписки и очередь отправки, она же журнал доставки
+        CREATE TABLE IF NOT EXISTS webhooks
+        (
+            id          serial PRIMARY KEY,
+            user_id     int          NOT NULL REFERENCES users (id),
+            url         varchar(500) NOT NULL,                     -- Куда отправлять POST
+            secret      varchar(128) NOT NULL,                     -- Ключ HMAC-подписи
+            event_types text[]       NOT NULL,                     -- На какие события подписка
+            created_at  timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE INDEX IF NOT EXISTS idx_webhooks_user ON webhooks (user_id);
+        CREATE TABLE IF NOT EXISTS webhook_deliveries
+        (
+            id               serial PRIMARY KEY,
+            webhook_id       int         NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+            event_type       varchar(50) NOT NULL,
+            event_id         bigint,                                         -- Событие outbox, из которого создана отправка
+            payload          jsonb       NOT NULL,
+            status           varchar(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+            attempts         int         NOT NULL DEFAULT 0,
+            next_attempt_at  timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Не раньше этого времени отправка берется в работу
+            last_status_code int,                                            -- HTTP-статус последнего ответа
+            last_error       text,
+            created_at       timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            delivered_at     timestamp
+        );
+        CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+        CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, created_at DESC);
+        -- Повторная обработка события outbox не создает вторую отправку
+        CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_event ON webhook_deliveries (webhook_id, event_id);
+
+        -- Outbox: события пишутся в одной транзакции с изменением данных
+        -- и разбираются воркером-ретранслятором
+        CREATE TABLE IF NOT EXISTS outbox
+        (
+            id           bigserial PRIMARY KEY,
+            event_type   varchar(50) NOT NULL,
+            payload      jsonb       NOT NULL,
+            status       varchar(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processed', 'failed')),
+            attempts     int         NOT NULL DEFAULT 0,
+            available_at timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP, -- Не раньше этого времени событие берется в работу
+            last_error   text,
+            created_at   timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            processed_at timestamp
+        );
+        CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (available_at, id) WHERE status = 'pending';
+
+-- Время последнего изменения для ETag и Last-Modified. Ставится триггером,
+-- поэтому учитывает и изменения в обход API
+        ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP; -- Когда объявление менялось последний раз
+        ALTER TABLE users ADD COLUMN IF NOT EXISTS updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP;          -- Когда профиль менялся последний раз
+        -- Категория, тип продвижения и город показываются в карточке объявления
+        ALTER TABLE categories_product ADD COLUMN IF NOT EXISTS updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP;
+        ALTER TABLE types_promotion ADD COLUMN IF NOT EXISTS updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP;
+        ALTER TABLE cities ADD COLUMN IF NOT EXISTS updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP;
+        CREATE OR REPLACE FUNCTION set_updated_at() RETURNS trigger AS
+        $fn$
+        BEGIN
+            NEW.updated_at = CURRENT_TIMESTAMP;
+            RETURN NEW;
+        END
+        $fn$ LANGUAGE plpgsql;
+        -- Отзывы, фотографии и атрибуты входят в карточку объявления: их изменение
+        -- сдвигает updated_at объявления
+        CREATE OR REPLACE FUNCTION touch_advertisement() RETURNS trigger AS
+        $fn$
+        BEGIN
+            IF TG_OP = 'DELETE' THEN
+                UPDATE advertisements SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.advertisement_id;
+                RETURN OLD;
+            END IF;
+            UPDATE advertisements SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.advertisement_id;
+            RETURN NEW;
+        END
+        $fn$ LANGUAGE plpgsql;
+        -- UPDATE без фактических изменений не сбрасывает ETag у клиентов
+        IF NOT EXISTS (SELECT FROM pg_trigger WHERE tgname = 'trg_advertisements_updated_at') THEN
+            CREATE TRIGGER trg_advertisements_updated_at
+                BEFORE UPDATE ON advertisements
+                FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*)
+            EXECUTE FUNCTION set_updated_at();
+        END IF;
+        IF NOT EXISTS (SELECT FROM pg_trigger WHERE tgname = 'trg_users_updated_at') THEN
+            CREATE TRIGGER trg_users_updated_at
+                BEFORE UPDATE ON users
+                FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*)
+            EXECUTE FUNCTION set_updated_at();
+        END IF;
+        IF NOT EXISTS (SELECT FROM pg_trigger WHERE tgname = 'trg_categories_product_updated_at') THEN
+            CREATE TRIGGER trg_categories_product_updated_at
+                BEFORE UPDATE ON categories_product
+                FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*)
+            EXECUTE FUNCTION set_updated_at();
+        END IF;
+        IF NOT EXISTS (SELECT FROM pg_trigger WHERE tgname = 'trg_types_promotion_updated_at') THEN
+            CREATE TRIGGER trg_types_promotion_updated_at
+                BEFORE UPDATE ON types_promotion
+                FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*)
+            EXECUTE FUNCTION set_updated_at();
+        END IF;
+        IF NOT EXISTS (SELECT FROM pg_trigger WHERE tgname = 'trg_cities_updated_at') THEN
+            CREATE TRIGGER trg_cities_updated_at
+                BEFORE UPDATE ON cities
+                FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*)
+            EXECUTE FUNCTION set_updated_at();
+        END IF;
+        IF NOT EXISTS (SELECT FROM pg_trigger WHERE tgname = 'trg_reviews_touch_advertisement') THEN
+            CREATE TRIGGER trg_reviews_touch_advertisement
+                AFTER INSERT OR UPDATE OR DELETE ON reviews
+                FOR EACH ROW
+            EXECUTE FUNCTION touch_advertisement();
+        END IF;
+        IF NOT EXISTS (SELECT FROM pg_trigger WHERE tgname = 'trg_ad_photos_touch_advertisement') THEN
+            CREATE TRIGGER trg_ad_photos_touch_advertisement
+                AFTER INSERT OR UPDATE OR DELETE ON ad_photos
+                FOR EACH ROW
+            EXECUTE FUNCTION touch_advertisement();
+        END IF;
+        IF NOT EXISTS (SELECT FROM pg_trigger WHERE tgname = 'trg_advertisement_attributes_touch_advertisement') THEN
+            CREATE TRIGGER trg_advertisement_attributes_touch_advertisement
+                AFTER INSERT OR UPDATE OR DELETE ON advertisement_attributes
+                FOR EACH ROW
+            EXECUTE FUNCTION touch_advertisement();
+        END IF;
+
         RAISE NOTICE 'Таблицы успешно созданы.';
     EXCEPTION
         WHEN OTHERS THEN